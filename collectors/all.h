@@ -62,6 +62,7 @@
 #define NETDATA_CHART_PRIO_CPU_PER_CORE               1000 // +1 per core
 #define NETDATA_CHART_PRIO_CPU_TEMPERATURE            1050 // freebsd only
 #define NETDATA_CHART_PRIO_CPUFREQ_SCALING_CUR_FREQ   5003 // freebsd only
+#define NETDATA_CHART_PRIO_CPUFREQ_PSTATE             5100 // +1 per core
 #define NETDATA_CHART_PRIO_CPUIDLE                    6000
 
 #define NETDATA_CHART_PRIO_CORE_THROTTLING            5001
@@ -85,6 +86,9 @@
 #define NETDATA_CHART_PRIO_MEM_KSM_RATIOS             1302
 #define NETDATA_CHART_PRIO_MEM_NUMA                   1400
 #define NETDATA_CHART_PRIO_MEM_NUMA_NODES             1410
+#define NETDATA_CHART_PRIO_MEM_NUMA_NODES_MEMINFO     1411
+#define NETDATA_CHART_PRIO_MEM_NUMA_NODES_LOCALITY    1412
+#define NETDATA_CHART_PRIO_MEM_NUMA_NODES_HUGEPAGES   1413
 #define NETDATA_CHART_PRIO_MEM_PAGEFRAG               1450
 #define NETDATA_CHART_PRIO_MEM_HW                     1500
 #define NETDATA_CHART_PRIO_MEM_HW_ECC_CE              1550
@@ -337,10 +341,23 @@
 
 // Linux Power Supply
 
-#define NETDATA_CHART_PRIO_POWER_SUPPLY_CAPACITY      9500 // 4 charts per power supply
+#define NETDATA_CHART_PRIO_POWER_SUPPLY_CAPACITY      9500 // 8 charts per power supply
 #define NETDATA_CHART_PRIO_POWER_SUPPLY_CHARGE        9501
 #define NETDATA_CHART_PRIO_POWER_SUPPLY_ENERGY        9502
 #define NETDATA_CHART_PRIO_POWER_SUPPLY_VOLTAGE       9503
+#define NETDATA_CHART_PRIO_POWER_SUPPLY_STATUS        9504
+#define NETDATA_CHART_PRIO_POWER_SUPPLY_HEALTH        9505
+#define NETDATA_CHART_PRIO_POWER_SUPPLY_CYCLE_COUNT   9506
+#define NETDATA_CHART_PRIO_POWER_SUPPLY_ONLINE        9507
+
+// Linux Powercap (RAPL)
+
+#define NETDATA_CHART_PRIO_POWERCAP_RAPL              9550
+
+// Linux Thermal
+
+#define NETDATA_CHART_PRIO_THERMAL_ZONE_TEMPERATURE   9600
+#define NETDATA_CHART_PRIO_THERMAL_COOLING_DEVICE     9610
 
 
 // Wireless
@@ -895,6 +895,12 @@ struct cgroup {
     RRDDIM *rd_io_queued_write;
     RRDDIM *rd_io_merged_write;
 
+    RRDDIM *rd_cpu_some_pressure;
+    RRDDIM *rd_mem_some_pressure;
+    RRDDIM *rd_mem_full_pressure;
+    RRDDIM *rd_io_some_pressure;
+    RRDDIM *rd_io_full_pressure;
+
     struct cgroup *next;
     struct cgroup *discovered_next;
 
@@ -2811,6 +2817,11 @@ void update_systemd_services_charts(
         , int do_throttle_ops
         , int do_queued_ops
         , int do_merged_ops
+        , int do_cpu_some_pressure
+        , int do_mem_some_pressure
+        , int do_mem_full_pressure
+        , int do_io_some_pressure
+        , int do_io_full_pressure
 ) {
     static RRDSET
         *st_cpu = NULL,
@@ -2839,7 +2850,13 @@ void update_systemd_services_charts(
         *st_throttle_io_write = NULL,
         *st_throttle_ops_write = NULL,
         *st_queued_ops_write = NULL,
-        *st_merged_ops_write = NULL;
+        *st_merged_ops_write = NULL,
+
+        *st_cpu_some_pressure = NULL,
+        *st_mem_some_pressure = NULL,
+        *st_mem_full_pressure = NULL,
+        *st_io_some_pressure = NULL,
+        *st_io_full_pressure = NULL;
 
     // create the charts
 
@@ -3370,6 +3387,121 @@ void update_systemd_services_charts(
             rrdset_next(st_merged_ops_write);
     }
 
+    if(likely(do_cpu_some_pressure)) {
+        if(unlikely(!st_cpu_some_pressure)) {
+
+            st_cpu_some_pressure = rrdset_create_localhost(
+                    "services"
+                    , "cpu_some_pressure"
+                    , NULL
+                    , "cpu"
+                    , "services.cpu_some_pressure"
+                    , "Systemd Services CPU some pressure"
+                    , "percentage"
+                    , PLUGIN_CGROUPS_NAME
+                    , PLUGIN_CGROUPS_MODULE_SYSTEMD_NAME
+                    , NETDATA_CHART_PRIO_CGROUPS_SYSTEMD + 240
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+        }
+        else
+            rrdset_next(st_cpu_some_pressure);
+    }
+
+    if(likely(do_mem_some_pressure)) {
+        if(unlikely(!st_mem_some_pressure)) {
+
+            st_mem_some_pressure = rrdset_create_localhost(
+                    "services"
+                    , "memory_some_pressure"
+                    , NULL
+                    , "mem"
+                    , "services.memory_some_pressure"
+                    , "Systemd Services Memory some pressure"
+                    , "percentage"
+                    , PLUGIN_CGROUPS_NAME
+                    , PLUGIN_CGROUPS_MODULE_SYSTEMD_NAME
+                    , NETDATA_CHART_PRIO_CGROUPS_SYSTEMD + 250
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+        }
+        else
+            rrdset_next(st_mem_some_pressure);
+    }
+
+    if(likely(do_mem_full_pressure)) {
+        if(unlikely(!st_mem_full_pressure)) {
+
+            st_mem_full_pressure = rrdset_create_localhost(
+                    "services"
+                    , "memory_full_pressure"
+                    , NULL
+                    , "mem"
+                    , "services.memory_full_pressure"
+                    , "Systemd Services Memory full pressure"
+                    , "percentage"
+                    , PLUGIN_CGROUPS_NAME
+                    , PLUGIN_CGROUPS_MODULE_SYSTEMD_NAME
+                    , NETDATA_CHART_PRIO_CGROUPS_SYSTEMD + 260
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+        }
+        else
+            rrdset_next(st_mem_full_pressure);
+    }
+
+    if(likely(do_io_some_pressure)) {
+        if(unlikely(!st_io_some_pressure)) {
+
+            st_io_some_pressure = rrdset_create_localhost(
+                    "services"
+                    , "io_some_pressure"
+                    , NULL
+                    , "disk"
+                    , "services.io_some_pressure"
+                    , "Systemd Services I/O some pressure"
+                    , "percentage"
+                    , PLUGIN_CGROUPS_NAME
+                    , PLUGIN_CGROUPS_MODULE_SYSTEMD_NAME
+                    , NETDATA_CHART_PRIO_CGROUPS_SYSTEMD + 270
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+        }
+        else
+            rrdset_next(st_io_some_pressure);
+    }
+
+    if(likely(do_io_full_pressure)) {
+        if(unlikely(!st_io_full_pressure)) {
+
+            st_io_full_pressure = rrdset_create_localhost(
+                    "services"
+                    , "io_full_pressure"
+                    , NULL
+                    , "disk"
+                    , "services.io_full_pressure"
+                    , "Systemd Services I/O full pressure"
+                    , "percentage"
+                    , PLUGIN_CGROUPS_NAME
+                    , PLUGIN_CGROUPS_MODULE_SYSTEMD_NAME
+                    , NETDATA_CHART_PRIO_CGROUPS_SYSTEMD + 280
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+        }
+        else
+            rrdset_next(st_io_full_pressure);
+    }
+
     // update the values
     struct cgroup *cg;
     for(cg = cgroup_root; cg ; cg = cg->next) {
@@ -3532,6 +3664,42 @@ void update_systemd_services_charts(
 
             rrddim_set_by_pointer(st_merged_ops_write, cg->rd_io_merged_write, cg->io_merged.Write);
         }
+
+        // the 10 seconds average is the most responsive, the longer ones are available on the cgroup charts of containers
+        if(likely(do_cpu_some_pressure && cg->cpu_pressure.updated && cg->cpu_pressure.some.enabled)) {
+            if(unlikely(!cg->rd_cpu_some_pressure))
+                cg->rd_cpu_some_pressure = rrddim_add(st_cpu_some_pressure, cg->chart_id, cg->chart_title, 1, 100, RRD_ALGORITHM_ABSOLUTE);
+
+            rrddim_set_by_pointer(st_cpu_some_pressure, cg->rd_cpu_some_pressure, (collected_number)(cg->cpu_pressure.some.share_time.value10 * 100));
+        }
+
+        if(likely(do_mem_some_pressure && cg->memory_pressure.updated && cg->memory_pressure.some.enabled)) {
+            if(unlikely(!cg->rd_mem_some_pressure))
+                cg->rd_mem_some_pressure = rrddim_add(st_mem_some_pressure, cg->chart_id, cg->chart_title, 1, 100, RRD_ALGORITHM_ABSOLUTE);
+
+            rrddim_set_by_pointer(st_mem_some_pressure, cg->rd_mem_some_pressure, (collected_number)(cg->memory_pressure.some.share_time.value10 * 100));
+        }
+
+        if(likely(do_mem_full_pressure && cg->memory_pressure.updated && cg->memory_pressure.full.enabled)) {
+            if(unlikely(!cg->rd_mem_full_pressure))
+                cg->rd_mem_full_pressure = rrddim_add(st_mem_full_pressure, cg->chart_id, cg->chart_title, 1, 100, RRD_ALGORITHM_ABSOLUTE);
+
+            rrddim_set_by_pointer(st_mem_full_pressure, cg->rd_mem_full_pressure, (collected_number)(cg->memory_pressure.full.share_time.value10 * 100));
+        }
+
+        if(likely(do_io_some_pressure && cg->io_pressure.updated && cg->io_pressure.some.enabled)) {
+            if(unlikely(!cg->rd_io_some_pressure))
+                cg->rd_io_some_pressure = rrddim_add(st_io_some_pressure, cg->chart_id, cg->chart_title, 1, 100, RRD_ALGORITHM_ABSOLUTE);
+
+            rrddim_set_by_pointer(st_io_some_pressure, cg->rd_io_some_pressure, (collected_number)(cg->io_pressure.some.share_time.value10 * 100));
+        }
+
+        if(likely(do_io_full_pressure && cg->io_pressure.updated && cg->io_pressure.full.enabled)) {
+            if(unlikely(!cg->rd_io_full_pressure))
+                cg->rd_io_full_pressure = rrddim_add(st_io_full_pressure, cg->chart_id, cg->chart_title, 1, 100, RRD_ALGORITHM_ABSOLUTE);
+
+            rrddim_set_by_pointer(st_io_full_pressure, cg->rd_io_full_pressure, (collected_number)(cg->io_pressure.full.share_time.value10 * 100));
+        }
     }
 
     // complete the iteration
@@ -3587,6 +3755,21 @@ void update_systemd_services_charts(
         rrdset_done(st_merged_ops_read);
         rrdset_done(st_merged_ops_write);
     }
+
+    if(likely(do_cpu_some_pressure))
+        rrdset_done(st_cpu_some_pressure);
+
+    if(likely(do_mem_some_pressure))
+        rrdset_done(st_mem_some_pressure);
+
+    if(likely(do_mem_full_pressure))
+        rrdset_done(st_mem_full_pressure);
+
+    if(likely(do_io_some_pressure))
+        rrdset_done(st_io_some_pressure);
+
+    if(likely(do_io_full_pressure))
+        rrdset_done(st_io_full_pressure);
 }
 
 static inline char *cgroup_chart_type(char *buffer, const char *id, size_t len) {
@@ -3768,7 +3951,12 @@ void update_cgroup_charts(int update_every) {
             services_do_throttle_io = 0,
             services_do_throttle_ops = 0,
             services_do_queued_ops = 0,
-            services_do_merged_ops = 0;
+            services_do_merged_ops = 0,
+            services_do_cpu_some_pressure = 0,
+            services_do_mem_some_pressure = 0,
+            services_do_mem_full_pressure = 0,
+            services_do_io_some_pressure = 0,
+            services_do_io_full_pressure = 0;
 
     struct cgroup *cg;
     for(cg = cgroup_root; cg ; cg = cg->next) {
@@ -3789,6 +3977,12 @@ void update_cgroup_charts(int update_every) {
             if(cg->throttle_io_serviced.updated && cg->throttle_io_serviced.enabled == CONFIG_BOOLEAN_YES) services_do_throttle_ops++;
             if(cg->io_queued.updated && cg->io_queued.enabled == CONFIG_BOOLEAN_YES) services_do_queued_ops++;
             if(cg->io_merged.updated && cg->io_merged.enabled == CONFIG_BOOLEAN_YES) services_do_merged_ops++;
+
+            if(cg->cpu_pressure.updated && cg->cpu_pressure.some.enabled) services_do_cpu_some_pressure++;
+            if(cg->memory_pressure.updated && cg->memory_pressure.some.enabled) services_do_mem_some_pressure++;
+            if(cg->memory_pressure.updated && cg->memory_pressure.full.enabled) services_do_mem_full_pressure++;
+            if(cg->io_pressure.updated && cg->io_pressure.some.enabled) services_do_io_some_pressure++;
+            if(cg->io_pressure.updated && cg->io_pressure.full.enabled) services_do_io_full_pressure++;
             continue;
         }
 
@@ -4902,6 +5096,8 @@ void update_cgroup_charts(int update_every) {
                                        , services_do_mem_failcnt, services_do_swap_usage, services_do_io
                                        , services_do_io_ops, services_do_throttle_io, services_do_throttle_ops
                                        , services_do_queued_ops, services_do_merged_ops
+                                       , services_do_cpu_some_pressure, services_do_mem_some_pressure, services_do_mem_full_pressure
+                                       , services_do_io_some_pressure, services_do_io_full_pressure
         );
 
     debug(D_CGROUP, "done updating cgroups charts");
@@ -75,6 +75,10 @@ static struct proc_module {
     {.name = "/sys/class/power_supply",      .dim = "power_supply", .func = do_sys_class_power_supply},
     // linux power supply metrics
 
+    // thermal zones, cooling devices and RAPL energy
+    {.name = "/sys/class/thermal",           .dim = "thermal",      .func = do_sys_class_thermal},
+    {.name = "/sys/class/powercap",          .dim = "powercap",     .func = do_sys_class_powercap},
+
     // the terminator of this array
     {.name = NULL, .dim = NULL, .func = NULL}
 };
@@ -44,6 +44,8 @@ extern int do_proc_net_sockstat6(int update_every, usec_t dt);
 extern int do_proc_net_sctp_snmp(int update_every, usec_t dt);
 extern int do_ipc(int update_every, usec_t dt);
 extern int do_sys_class_power_supply(int update_every, usec_t dt);
+extern int do_sys_class_powercap(int update_every, usec_t dt);
+extern int do_sys_class_thermal(int update_every, usec_t dt);
 extern int do_proc_pagetypeinfo(int update_every, usec_t dt);
 extern int do_sys_class_infiniband(int update_every, usec_t dt);
 extern int get_numa_node_count(void);
@@ -24,6 +24,11 @@ struct per_core_time_in_state_file {
     procfile *ff;
     size_t last_ticks_len;
     struct last_ticks *last_ticks;
+    size_t frequencies;
+
+    RRDSET *st_pstate;
+    size_t rd_pstate_len;
+    RRDDIM **rd_pstate;
 };
 
 #define CORE_THROTTLE_COUNT_INDEX    0
@@ -195,6 +200,8 @@ static int read_per_core_time_in_state_files(struct cpu_chart *all_cpu_charts, s
 
             }
 
+            tsf->frequencies = lines - 1;
+
             if (likely(total_ticks_since_last)) {
                 avg_freq /= total_ticks_since_last;
                 f->value = avg_freq;
@@ -223,6 +230,65 @@ static int read_per_core_time_in_state_files(struct cpu_chart *all_cpu_charts, s
     return (int)files_nonzero;
 }
 
+static void chart_per_core_time_in_state(struct cpu_chart *all_cpu_charts, size_t len, int update_every) {
+    size_t x, l;
+    for(x = 0; x < len ; x++) {
+        struct per_core_time_in_state_file *tsf = &all_cpu_charts[x].time_in_state_files;
+
+        if(unlikely(!all_cpu_charts[x].files[CPU_FREQ_INDEX].found || !tsf->frequencies))
+            continue;
+
+        if(unlikely(!tsf->st_pstate)) {
+            char pstate_chart_id[RRD_ID_LENGTH_MAX + 1];
+            snprintfz(pstate_chart_id, RRD_ID_LENGTH_MAX, "%s_pstate", all_cpu_charts[x].id);
+
+            tsf->st_pstate = rrdset_create_localhost(
+                    "cpu"
+                    , pstate_chart_id
+                    , NULL
+                    , "cpufreq"
+                    , "cpufreq.cpu_pstate_residency_time"
+                    , "P-state residency time"
+                    , "percentage"
+                    , PLUGIN_PROC_NAME
+                    , PLUGIN_PROC_MODULE_STAT_NAME
+                    , NETDATA_CHART_PRIO_CPUFREQ_PSTATE + x
+                    , update_every
+                    , RRDSET_TYPE_STACKED
+            );
+
+            rrdlabels_add(tsf->st_pstate->state->chart_labels, "cpu", all_cpu_charts[x].id, RRDLABEL_SRC_AUTO);
+        }
+        else
+            rrdset_next(tsf->st_pstate);
+
+        if(unlikely(tsf->rd_pstate_len < tsf->frequencies)) {
+            tsf->rd_pstate = reallocz(tsf->rd_pstate, sizeof(RRDDIM *) * tsf->frequencies);
+            memset(tsf->rd_pstate + tsf->rd_pstate_len, 0, sizeof(RRDDIM *) * (tsf->frequencies - tsf->rd_pstate_len));
+            tsf->rd_pstate_len = tsf->frequencies;
+        }
+
+        for(l = 0; l < tsf->frequencies ; l++) {
+            struct last_ticks *lt = &tsf->last_ticks[l];
+
+            if(unlikely(!lt->frequency))
+                continue;
+
+            if(unlikely(!tsf->rd_pstate[l])) {
+                // time_in_state frequencies are in KHz
+                char pstate_dim_id[50 + 1], pstate_dim_name[50 + 1];
+                snprintfz(pstate_dim_id, 50, "%lld", (long long)lt->frequency);
+                snprintfz(pstate_dim_name, 50, "%lld MHz", (long long)lt->frequency / 1000);
+                tsf->rd_pstate[l] = rrddim_add(tsf->st_pstate, pstate_dim_id, pstate_dim_name, 1, 1, RRD_ALGORITHM_PCENT_OVER_DIFF_TOTAL);
+            }
+
+            rrddim_set_by_pointer(tsf->st_pstate, tsf->rd_pstate[l], lt->ticks);
+        }
+
+        rrdset_done(tsf->st_pstate);
+    }
+}
+
 static void chart_per_core_files(struct cpu_chart *all_cpu_charts, size_t len, size_t index, RRDSET *st, collected_number multiplier, collected_number divisor, RRD_ALGORITHM algorithm) {
     size_t x;
     for(x = 0; x < len ; x++) {
@@ -477,7 +543,7 @@ int do_proc_stat(int update_every, usec_t dt) {
     static size_t all_cpu_charts_size = 0;
     static procfile *ff = NULL;
     static int do_cpu = -1, do_cpu_cores = -1, do_interrupts = -1, do_context = -1, do_forks = -1, do_processes = -1,
-           do_core_throttle_count = -1, do_package_throttle_count = -1, do_cpu_freq = -1, do_cpuidle = -1;
+           do_core_throttle_count = -1, do_package_throttle_count = -1, do_cpu_freq = -1, do_cpu_pstate = -1, do_cpuidle = -1;
     static uint32_t hash_intr, hash_ctxt, hash_processes, hash_procs_running, hash_procs_blocked;
     static char *core_throttle_count_filename = NULL, *package_throttle_count_filename = NULL, *scaling_cur_freq_filename = NULL,
            *time_in_state_filename = NULL, *schedstat_filename = NULL, *cpuidle_name_filename = NULL, *cpuidle_time_filename = NULL;
@@ -500,6 +566,7 @@ int do_proc_stat(int update_every, usec_t dt) {
             do_core_throttle_count = CONFIG_BOOLEAN_NO;
             do_package_throttle_count = CONFIG_BOOLEAN_NO;
             do_cpu_freq = CONFIG_BOOLEAN_NO;
+            do_cpu_pstate = CONFIG_BOOLEAN_NO;
             do_cpuidle = CONFIG_BOOLEAN_NO;
         }
         else {
@@ -508,6 +575,7 @@ int do_proc_stat(int update_every, usec_t dt) {
             do_core_throttle_count = CONFIG_BOOLEAN_AUTO;
             do_package_throttle_count = CONFIG_BOOLEAN_NO;
             do_cpu_freq = CONFIG_BOOLEAN_YES;
+            do_cpu_pstate = CONFIG_BOOLEAN_AUTO;
             do_cpuidle = CONFIG_BOOLEAN_YES;
         }
         if(unlikely(processors > 24)) {
@@ -524,6 +592,7 @@ int do_proc_stat(int update_every, usec_t dt) {
         do_core_throttle_count    = config_get_boolean_ondemand("plugin:proc:/proc/stat", "core_throttle_count", do_core_throttle_count);
         do_package_throttle_count = config_get_boolean_ondemand("plugin:proc:/proc/stat", "package_throttle_count", do_package_throttle_count);
         do_cpu_freq               = config_get_boolean_ondemand("plugin:proc:/proc/stat", "cpu frequency", do_cpu_freq);
+        do_cpu_pstate             = config_get_boolean_ondemand("plugin:proc:/proc/stat", "cpu p-state residency", do_cpu_pstate);
         do_cpuidle                = config_get_boolean_ondemand("plugin:proc:/proc/stat", "cpu idle states", do_cpuidle);
 
         hash_intr = simple_hash("intr");
@@ -974,6 +1043,12 @@ int do_proc_stat(int update_every, usec_t dt) {
 
                 chart_per_core_files(&all_cpu_charts[1], all_cpu_charts_size - 1, CPU_FREQ_INDEX, st_scaling_cur_freq, 1, 1000, RRD_ALGORITHM_ABSOLUTE);
                 rrdset_done(st_scaling_cur_freq);
+
+                // the residency is available only from time_in_state
+                if(likely(do_cpu_pstate != CONFIG_BOOLEAN_NO && accurate_freq_is_used)) {
+                    do_cpu_pstate = CONFIG_BOOLEAN_YES;
+                    chart_per_core_time_in_state(&all_cpu_charts[1], all_cpu_charts_size - 1, update_every);
+                }
             }
         }
     }
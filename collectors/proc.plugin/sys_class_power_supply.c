@@ -45,12 +45,73 @@ struct capacity {
     unsigned long long value;
 };
 
+// a single numeric attribute, like cycle_count or online
+struct ps_value {
+    char *filename;
+    int fd;
+
+    RRDSET *st;
+    RRDDIM *rd;
+    unsigned long long value;
+};
+
+#define PS_STATE_DIMS_MAX 8
+
+struct ps_state_value {
+    const char *value; // the string reported by the driver, NULL terminates the list and matches everything else
+    size_t dim;        // the index of the dimension this string is reported as
+};
+
+const char *ps_status_dims[] = {"charging", "discharging", "not_charging", "full", "unknown", NULL};
+struct ps_state_value ps_status_values[] = {
+          {"Charging", 0}
+        , {"Discharging", 1}
+        , {"Not charging", 2}
+        , {"Full", 3}
+        , {NULL, 4}
+};
+
+const char *ps_health_dims[] = {"good", "overheat", "cold", "dead", "over_voltage", "over_current", "failure", "unknown", NULL};
+struct ps_state_value ps_health_values[] = {
+          {"Good", 0}
+        , {"Overheat", 1}
+        , {"Hot", 1}
+        , {"Warm", 1}
+        , {"Cold", 2}
+        , {"Cool", 2}
+        , {"Dead", 3}
+        , {"Over voltage", 4}
+        , {"Over current", 5}
+        , {"Unspecified failure", 6}
+        , {"Watchdog timer expire", 6}
+        , {"Safety timer expire", 6}
+        , {"Calibration required", 6}
+        , {NULL, 7}
+};
+
+// an enumerated attribute, like status or health
+struct ps_state {
+    char *filename;
+    int fd;
+
+    const char **dims;
+    struct ps_state_value *values;
+    size_t current;
+
+    RRDSET *st;
+    RRDDIM *rd[PS_STATE_DIMS_MAX];
+};
+
 struct power_supply {
     char *name;
     uint32_t hash;
     int found;
 
     struct capacity *capacity;
+    struct ps_value *cycle_count;
+    struct ps_value *online;
+    struct ps_state *status;
+    struct ps_state *health;
 
     struct ps_property *property_root;
 
@@ -60,6 +121,26 @@ struct power_supply {
 static struct power_supply *power_supply_root = NULL;
 static int files_num = 0;
 
+static void ps_value_free(struct ps_value *pv) {
+    if(likely(pv)) {
+        if(likely(pv->st)) rrdset_is_obsolete(pv->st);
+        freez(pv->filename);
+        if(likely(pv->fd != -1)) close(pv->fd);
+        files_num--;
+        freez(pv);
+    }
+}
+
+static void ps_state_free(struct ps_state *pst) {
+    if(likely(pst)) {
+        if(likely(pst->st)) rrdset_is_obsolete(pst->st);
+        freez(pst->filename);
+        if(likely(pst->fd != -1)) close(pst->fd);
+        files_num--;
+        freez(pst);
+    }
+}
+
 void power_supply_free(struct power_supply *ps) {
     if(likely(ps)) {
 
@@ -71,6 +152,12 @@ void power_supply_free(struct power_supply *ps) {
             files_num--;
             freez(ps->capacity);
         }
+
+        ps_value_free(ps->cycle_count);
+        ps_value_free(ps->online);
+        ps_state_free(ps->status);
+        ps_state_free(ps->health);
+
         freez(ps->name);
 
         struct ps_property *pr = ps->property_root;
@@ -116,9 +203,166 @@ static void add_labels_to_power_supply(struct power_supply *ps, RRDSET *st) {
     rrdlabels_add(st->state->chart_labels, "device", ps->name, RRDLABEL_SRC_AUTO);
 }
 
+static struct ps_value *ps_value_init(const char *dirname, const char *name, const char *attribute) {
+    struct stat stbuf;
+    char filename[FILENAME_MAX + 1];
+    snprintfz(filename, FILENAME_MAX, "%s/%s/%s", dirname, name, attribute);
+    if(stat(filename, &stbuf) != 0)
+        return NULL;
+
+    struct ps_value *pv = callocz(sizeof(struct ps_value), 1);
+    pv->filename = strdupz(filename);
+    pv->fd = -1;
+    files_num++;
+    return pv;
+}
+
+static struct ps_state *ps_state_init(const char *dirname, const char *name, const char *attribute, const char **dims, struct ps_state_value *values) {
+    struct stat stbuf;
+    char filename[FILENAME_MAX + 1];
+    snprintfz(filename, FILENAME_MAX, "%s/%s/%s", dirname, name, attribute);
+    if(stat(filename, &stbuf) != 0)
+        return NULL;
+
+    struct ps_state *pst = callocz(sizeof(struct ps_state), 1);
+    pst->filename = strdupz(filename);
+    pst->fd = -1;
+    pst->dims = dims;
+    pst->values = values;
+    files_num++;
+    return pst;
+}
+
+// returns 0 on success, the file descriptor is kept open or closed according to keep_fds_open
+static int read_power_supply_file(const char *filename, int *fd, char *buffer, size_t size, int keep_fds_open) {
+    if(unlikely(*fd == -1)) {
+        *fd = open(filename, O_RDONLY, 0666);
+        if(unlikely(*fd == -1)) {
+            error("Cannot open file '%s'", filename);
+            return 1;
+        }
+    }
+
+    ssize_t r = read(*fd, buffer, size);
+    if(unlikely(r < 1)) {
+        error("Cannot read file '%s'", filename);
+        close(*fd);
+        *fd = -1;
+        return 1;
+    }
+    buffer[r] = '\0';
+
+    if(unlikely(!keep_fds_open)) {
+        close(*fd);
+        *fd = -1;
+    }
+    else if(unlikely(lseek(*fd, 0, SEEK_SET) == -1)) {
+        error("Cannot seek in file '%s'", filename);
+        close(*fd);
+        *fd = -1;
+    }
+
+    return 0;
+}
+
+// drivers that do not support an attribute may still expose the file, but fail to read it or report a negative value
+static int read_ps_value(struct ps_value *pv, int keep_fds_open) {
+    char buffer[30 + 1];
+
+    if(unlikely(read_power_supply_file(pv->filename, &pv->fd, buffer, 30, keep_fds_open)))
+        return 1;
+
+    long long value = str2ll(buffer, NULL);
+    if(unlikely(value < 0))
+        return 1;
+
+    pv->value = (unsigned long long)value;
+    return 0;
+}
+
+static int read_ps_state(struct ps_state *pst, int keep_fds_open) {
+    char buffer[50 + 1];
+
+    if(unlikely(read_power_supply_file(pst->filename, &pst->fd, buffer, 50, keep_fds_open)))
+        return 1;
+
+    char *value = trim(buffer);
+
+    struct ps_state_value *sv;
+    for(sv = pst->values; sv->value; sv++) {
+        if(value && !strcmp(sv->value, value))
+            break;
+    }
+    pst->current = sv->dim;
+
+    return 0;
+}
+
+static void update_ps_value_chart(struct power_supply *ps, struct ps_value *pv, const char *type, const char *context, const char *title, const char *units, const char *dim, long priority, int update_every) {
+    if(unlikely(!pv->st)) {
+        pv->st = rrdset_create_localhost(
+                type
+                , ps->name
+                , NULL
+                , ps->name
+                , context
+                , title
+                , units
+                , PLUGIN_PROC_NAME
+                , PLUGIN_PROC_MODULE_POWER_SUPPLY_NAME
+                , priority
+                , update_every
+                , RRDSET_TYPE_LINE
+        );
+
+        add_labels_to_power_supply(ps, pv->st);
+    }
+    else
+        rrdset_next(pv->st);
+
+    if(unlikely(!pv->rd)) pv->rd = rrddim_add(pv->st, dim, NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+    rrddim_set_by_pointer(pv->st, pv->rd, pv->value);
+
+    rrdset_done(pv->st);
+}
+
+static void update_ps_state_chart(struct power_supply *ps, struct ps_state *pst, const char *type, const char *context, const char *title, long priority, int update_every) {
+    if(unlikely(!pst->st)) {
+        pst->st = rrdset_create_localhost(
+                type
+                , ps->name
+                , NULL
+                , ps->name
+                , context
+                , title
+                , "status"
+                , PLUGIN_PROC_NAME
+                , PLUGIN_PROC_MODULE_POWER_SUPPLY_NAME
+                , priority
+                , update_every
+                , RRDSET_TYPE_LINE
+        );
+
+        add_labels_to_power_supply(ps, pst->st);
+
+        size_t i;
+        for(i = 0; pst->dims[i] && i < PS_STATE_DIMS_MAX; i++)
+            pst->rd[i] = rrddim_add(pst->st, pst->dims[i], NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+    }
+    else
+        rrdset_next(pst->st);
+
+    size_t i;
+    for(i = 0; pst->dims[i] && i < PS_STATE_DIMS_MAX; i++)
+        rrddim_set_by_pointer(pst->st, pst->rd[i], pst->current == i);
+
+    rrdset_done(pst->st);
+}
+
 int do_sys_class_power_supply(int update_every, usec_t dt) {
     (void)dt;
     static int do_capacity = -1, do_property[3] = {-1};
+    static int do_status = -1, do_health = -1, do_cycle_count = -1, do_online = -1;
     static int keep_fds_open = CONFIG_BOOLEAN_NO, keep_fds_open_config = -1;
     static char *dirname = NULL;
 
@@ -127,6 +371,10 @@ int do_sys_class_power_supply(int update_every, usec_t dt) {
         do_property[0] = config_get_boolean("plugin:proc:/sys/class/power_supply", "battery charge", CONFIG_BOOLEAN_NO);
         do_property[1] = config_get_boolean("plugin:proc:/sys/class/power_supply", "battery energy", CONFIG_BOOLEAN_NO);
         do_property[2] = config_get_boolean("plugin:proc:/sys/class/power_supply", "power supply voltage", CONFIG_BOOLEAN_NO);
+        do_status      = config_get_boolean("plugin:proc:/sys/class/power_supply", "battery status", CONFIG_BOOLEAN_YES);
+        do_health      = config_get_boolean("plugin:proc:/sys/class/power_supply", "battery health", CONFIG_BOOLEAN_YES);
+        do_cycle_count = config_get_boolean("plugin:proc:/sys/class/power_supply", "battery cycle count", CONFIG_BOOLEAN_YES);
+        do_online      = config_get_boolean("plugin:proc:/sys/class/power_supply", "power supply online", CONFIG_BOOLEAN_YES);
 
         keep_fds_open_config = config_get_boolean_ondemand("plugin:proc:/sys/class/power_supply", "keep files open", CONFIG_BOOLEAN_AUTO);
 
@@ -182,6 +430,19 @@ int do_sys_class_power_supply(int update_every, usec_t dt) {
                     }
                 }
 
+                if(likely(do_status != CONFIG_BOOLEAN_NO))
+                    ps->status = ps_state_init(dirname, de->d_name, "status", ps_status_dims, ps_status_values);
+
+                if(likely(do_health != CONFIG_BOOLEAN_NO))
+                    ps->health = ps_state_init(dirname, de->d_name, "health", ps_health_dims, ps_health_values);
+
+                if(likely(do_cycle_count != CONFIG_BOOLEAN_NO))
+                    ps->cycle_count = ps_value_init(dirname, de->d_name, "cycle_count");
+
+                // mains and usb adapters report whether they are plugged in
+                if(likely(do_online != CONFIG_BOOLEAN_NO))
+                    ps->online = ps_value_init(dirname, de->d_name, "online");
+
                 // allocate memory and initialize structures for every property and file found
                 size_t pr_idx, pd_idx;
                 size_t prev_idx = 3; // there is no property with this index
@@ -278,6 +539,30 @@ int do_sys_class_power_supply(int update_every, usec_t dt) {
                 }
             }
 
+            // read status, health, cycle count and online files
+            // an attribute that cannot be read is dropped, the rest of the power supply is still collected
+            if(ps) {
+                if(ps->status && unlikely(read_ps_state(ps->status, keep_fds_open))) {
+                    ps_state_free(ps->status);
+                    ps->status = NULL;
+                }
+
+                if(ps->health && unlikely(read_ps_state(ps->health, keep_fds_open))) {
+                    ps_state_free(ps->health);
+                    ps->health = NULL;
+                }
+
+                if(ps->cycle_count && unlikely(read_ps_value(ps->cycle_count, keep_fds_open))) {
+                    ps_value_free(ps->cycle_count);
+                    ps->cycle_count = NULL;
+                }
+
+                if(ps->online && unlikely(read_ps_value(ps->online, keep_fds_open))) {
+                    ps_value_free(ps->online);
+                    ps->online = NULL;
+                }
+            }
+
             // read property files
             int read_error = 0;
             struct ps_property *pr;
@@ -374,6 +659,18 @@ int do_sys_class_power_supply(int update_every, usec_t dt) {
             rrdset_done(ps->capacity->st);
         }
 
+        if(ps->status)
+            update_ps_state_chart(ps, ps->status, "powersupply_status", "powersupply.status", "Battery status", NETDATA_CHART_PRIO_POWER_SUPPLY_STATUS, update_every);
+
+        if(ps->health)
+            update_ps_state_chart(ps, ps->health, "powersupply_health", "powersupply.health", "Battery health", NETDATA_CHART_PRIO_POWER_SUPPLY_HEALTH, update_every);
+
+        if(ps->cycle_count)
+            update_ps_value_chart(ps, ps->cycle_count, "powersupply_cycle_count", "powersupply.cycle_count", "Battery charge cycles", "cycles", "cycles", NETDATA_CHART_PRIO_POWER_SUPPLY_CYCLE_COUNT, update_every);
+
+        if(ps->online)
+            update_ps_value_chart(ps, ps->online, "powersupply_online", "powersupply.online", "Power supply online", "status", "online", NETDATA_CHART_PRIO_POWER_SUPPLY_ONLINE, update_every);
+
         struct ps_property *pr;
         for(pr = ps->property_root; pr; pr = pr->next) {
             if(unlikely(!pr->st)) {
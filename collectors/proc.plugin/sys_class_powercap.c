@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+#include "plugin_proc.h"
+
+#define PLUGIN_PROC_MODULE_POWERCAP_NAME "/sys/class/powercap"
+
+// RAPL zones are named <control type>:<package>, their subzones <control type>:<package>:<subzone>,
+// e.g. intel-rapl:0 (package-0) and intel-rapl:0:0 (core).
+// Every top level zone gets a chart, with one dimension for the zone itself and one for each of its subzones.
+struct rapl_zone {
+    char *id;
+    uint32_t hash;
+    char *name;
+    int found;
+    int updated;
+
+    char *energy_filename;
+    unsigned long long max_energy_range;
+    unsigned long long last_energy;
+    collected_number energy;
+
+    struct rapl_zone *parent;
+
+    RRDSET *st;
+    RRDDIM *rd;
+
+    struct rapl_zone *next;
+};
+
+static struct rapl_zone *rapl_zone_root = NULL;
+
+static void rapl_zone_free(struct rapl_zone *z) {
+    if(likely(z->st)) rrdset_is_obsolete(z->st);
+
+    // a subzone is a dimension of the chart of its zone
+    if(z->parent && z->parent->st && z->rd)
+        rrddim_is_obsolete(z->parent->st, z->rd);
+
+    // subzones of a removed zone are removed together with it
+    struct rapl_zone *s;
+    for(s = rapl_zone_root; s; s = s->next) {
+        if(s->parent == z) {
+            s->parent = NULL;
+            s->found = 0;
+        }
+    }
+
+    freez(z->id);
+    freez(z->name);
+    freez(z->energy_filename);
+
+    // remove the zone from the linked list
+    if(likely(z == rapl_zone_root)) {
+        rapl_zone_root = z->next;
+    }
+    else {
+        struct rapl_zone *last;
+        for(last = rapl_zone_root; last && last->next != z; last = last->next);
+        if(likely(last)) last->next = z->next;
+    }
+
+    freez(z);
+}
+
+static struct rapl_zone *rapl_zone_find(const char *id, uint32_t hash) {
+    struct rapl_zone *z;
+    for(z = rapl_zone_root; z; z = z->next) {
+        if(unlikely(z->hash == hash && !strcmp(z->id, id)))
+            return z;
+    }
+    return NULL;
+}
+
+static struct rapl_zone *rapl_zone_create(const char *dirname, const char *id, uint32_t hash) {
+    char filename[FILENAME_MAX + 1], buffer[50 + 1];
+
+    snprintfz(filename, FILENAME_MAX, "%s/%s/energy_uj", dirname, id);
+
+    struct stat stbuf;
+    if(stat(filename, &stbuf) != 0)
+        return NULL;
+
+    struct rapl_zone *z = callocz(sizeof(struct rapl_zone), 1);
+    z->id = strdupz(id);
+    z->hash = hash;
+    z->energy_filename = strdupz(filename);
+
+    snprintfz(filename, FILENAME_MAX, "%s/%s/name", dirname, id);
+    if(likely(!read_file(filename, buffer, 50) && trim(buffer)))
+        z->name = strdupz(trim(buffer));
+    else
+        z->name = strdupz(id);
+
+    snprintfz(filename, FILENAME_MAX, "%s/%s/max_energy_range_uj", dirname, id);
+    read_single_number_file(filename, &z->max_energy_range);
+
+    z->next = rapl_zone_root;
+    rapl_zone_root = z;
+    return z;
+}
+
+// energy_uj wraps around at max_energy_range_uj, keep a monotonic counter for the incremental dimensions
+static void rapl_zone_read(struct rapl_zone *z) {
+    unsigned long long energy;
+
+    z->updated = 0;
+    if(unlikely(read_single_number_file(z->energy_filename, &energy)))
+        return;
+
+    if(likely(z->last_energy)) {
+        if(unlikely(energy < z->last_energy)) {
+            if(unlikely(!z->max_energy_range))
+                return;
+            z->energy += (collected_number)(z->max_energy_range - z->last_energy + energy);
+        }
+        else
+            z->energy += (collected_number)(energy - z->last_energy);
+    }
+
+    z->last_energy = energy;
+    z->updated = 1;
+}
+
+int do_sys_class_powercap(int update_every, usec_t dt) {
+    (void)dt;
+    static char *dirname = NULL;
+
+    if(unlikely(!dirname)) {
+        char filename[FILENAME_MAX + 1];
+        snprintfz(filename, FILENAME_MAX, "%s%s", netdata_configured_host_prefix, "/sys/class/powercap");
+        dirname = config_get("plugin:proc:/sys/class/powercap", "directory to monitor", filename);
+    }
+
+    DIR *dir = opendir(dirname);
+    if(unlikely(!dir)) {
+        error("Cannot read directory '%s'", dirname);
+        return 1;
+    }
+
+    struct dirent *de = NULL;
+    while(likely(de = readdir(dir))) {
+        if(unlikely(de->d_type != DT_LNK && de->d_type != DT_DIR))
+            continue;
+
+        // skip the control types (intel-rapl, intel-rapl-mmio), only zones have an index
+        char *colon = strchr(de->d_name, ':');
+        if(unlikely(!colon))
+            continue;
+
+        uint32_t hash = simple_hash(de->d_name);
+
+        struct rapl_zone *z = rapl_zone_find(de->d_name, hash);
+        if(unlikely(!z)) {
+            z = rapl_zone_create(dirname, de->d_name, hash);
+            if(unlikely(!z))
+                continue;
+        }
+
+        z->found = 1;
+        rapl_zone_read(z);
+    }
+
+    closedir(dir);
+
+    // --------------------------------------------------------------------
+    // link subzones to their zones
+
+    struct rapl_zone *z;
+    for(z = rapl_zone_root; z; z = z->next) {
+        if(likely(z->parent || !z->found))
+            continue;
+
+        char *last_colon = strrchr(z->id, ':');
+        if(last_colon == strchr(z->id, ':'))
+            continue;
+
+        char parent_id[FILENAME_MAX + 1];
+        strncpyz(parent_id, z->id, MIN((size_t)(last_colon - z->id), FILENAME_MAX));
+        z->parent = rapl_zone_find(parent_id, simple_hash(parent_id));
+
+        // a subzone without a zone is not charted
+        if(unlikely(!z->parent))
+            z->found = 0;
+    }
+
+    // --------------------------------------------------------------------
+
+    z = rapl_zone_root;
+    while(z) {
+        if(unlikely(!z->found)) {
+            struct rapl_zone *f = z;
+            z = z->next;
+            rapl_zone_free(f);
+
+            // freeing may have unlinked subzones, start over
+            z = rapl_zone_root;
+            continue;
+        }
+
+        z = z->next;
+    }
+
+    for(z = rapl_zone_root; z; z = z->next) {
+        if(z->parent || !z->updated)
+            continue;
+
+        if(unlikely(!z->st)) {
+            z->st = rrdset_create_localhost(
+                    "powercap"
+                    , z->id
+                    , NULL
+                    , z->name
+                    , "powercap.rapl_power"
+                    , "RAPL zone power"
+                    , "Watts"
+                    , PLUGIN_PROC_NAME
+                    , PLUGIN_PROC_MODULE_POWERCAP_NAME
+                    , NETDATA_CHART_PRIO_POWERCAP_RAPL
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+            rrdlabels_add(z->st->state->chart_labels, "zone", z->name, RRDLABEL_SRC_AUTO);
+        }
+        else
+            rrdset_next(z->st);
+
+        if(unlikely(!z->rd))
+            z->rd = rrddim_add(z->st, z->name, NULL, 1, 1000000, RRD_ALGORITHM_INCREMENTAL);
+        rrddim_set_by_pointer(z->st, z->rd, z->energy);
+
+        struct rapl_zone *s;
+        for(s = rapl_zone_root; s; s = s->next) {
+            if(s->parent != z || !s->updated)
+                continue;
+
+            if(unlikely(!s->rd))
+                s->rd = rrddim_add(z->st, s->name, NULL, 1, 1000000, RRD_ALGORITHM_INCREMENTAL);
+            rrddim_set_by_pointer(z->st, s->rd, s->energy);
+        }
+
+        rrdset_done(z->st);
+    }
+
+    for(z = rapl_zone_root; z; z = z->next)
+        z->found = 0;
+
+    return 0;
+}
@@ -0,0 +1,328 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+#include "plugin_proc.h"
+
+#define PLUGIN_PROC_MODULE_THERMAL_NAME "/sys/class/thermal"
+
+#define THERMAL_ZONE_PREFIX "thermal_zone"
+#define COOLING_DEVICE_PREFIX "cooling_device"
+
+// the first trip point of each of these types is exposed as a chart variable
+static const char *thermal_trip_types[] = {"critical", "hot", "passive", NULL};
+
+struct thermal_zone {
+    char *name;
+    uint32_t hash;
+    char *type;
+    int found;
+    int updated;
+
+    char *temp_filename;
+    long long temp;
+
+    RRDSET *st;
+    RRDDIM *rd_temp;
+
+    struct thermal_zone *next;
+};
+
+struct cooling_device {
+    char *name;
+    uint32_t hash;
+    char *type;
+    int found;
+    int updated;
+
+    char *cur_state_filename;
+    char *max_state_filename;
+    unsigned long long cur_state;
+    unsigned long long max_state;
+
+    RRDSET *st;
+    RRDDIM *rd_cur_state;
+    RRDDIM *rd_max_state;
+
+    struct cooling_device *next;
+};
+
+static struct thermal_zone *thermal_zone_root = NULL;
+static struct cooling_device *cooling_device_root = NULL;
+
+static void thermal_zone_free(struct thermal_zone *tz) {
+    if(likely(tz->st)) rrdset_is_obsolete(tz->st);
+
+    freez(tz->name);
+    freez(tz->type);
+    freez(tz->temp_filename);
+
+    // remove the zone from the linked list
+    if(likely(tz == thermal_zone_root)) {
+        thermal_zone_root = tz->next;
+    }
+    else {
+        struct thermal_zone *last;
+        for(last = thermal_zone_root; last && last->next != tz; last = last->next);
+        if(likely(last)) last->next = tz->next;
+    }
+
+    freez(tz);
+}
+
+static void cooling_device_free(struct cooling_device *cd) {
+    if(likely(cd->st)) rrdset_is_obsolete(cd->st);
+
+    freez(cd->name);
+    freez(cd->type);
+    freez(cd->cur_state_filename);
+    freez(cd->max_state_filename);
+
+    // remove the device from the linked list
+    if(likely(cd == cooling_device_root)) {
+        cooling_device_root = cd->next;
+    }
+    else {
+        struct cooling_device *last;
+        for(last = cooling_device_root; last && last->next != cd; last = last->next);
+        if(likely(last)) last->next = cd->next;
+    }
+
+    freez(cd);
+}
+
+static char *read_thermal_type(const char *dirname, const char *name) {
+    char filename[FILENAME_MAX + 1], buffer[50 + 1];
+    snprintfz(filename, FILENAME_MAX, "%s/%s/type", dirname, name);
+
+    if(unlikely(read_file(filename, buffer, 50) || !*buffer))
+        return strdupz(name);
+
+    return strdupz(trim(buffer));
+}
+
+static void add_zone_trip_points_variables(const char *dirname, struct thermal_zone *tz) {
+    char filename[FILENAME_MAX + 1], buffer[50 + 1];
+    int added[sizeof(thermal_trip_types) / sizeof(thermal_trip_types[0])] = { 0 };
+    size_t trip, t;
+
+    // trip points are numbered consecutively, the first missing one ends the list
+    for(trip = 0; ; trip++) {
+        snprintfz(filename, FILENAME_MAX, "%s/%s/trip_point_%zu_type", dirname, tz->name, trip);
+        if(read_file(filename, buffer, 50))
+            break;
+
+        char *type = trim(buffer);
+        if(unlikely(!type))
+            continue;
+
+        for(t = 0; thermal_trip_types[t]; t++) {
+            if(!added[t] && !strcmp(type, thermal_trip_types[t]))
+                break;
+        }
+        if(!thermal_trip_types[t])
+            continue;
+
+        long long temp;
+        snprintfz(filename, FILENAME_MAX, "%s/%s/trip_point_%zu_temp", dirname, tz->name, trip);
+        if(unlikely(read_single_signed_number_file(filename, &temp) || temp <= 0))
+            continue;
+
+        char var_name[50 + 1];
+        snprintfz(var_name, 50, "trip_%s", thermal_trip_types[t]);
+
+        RRDSETVAR *rs = rrdsetvar_custom_chart_variable_create(tz->st, var_name);
+        rrdsetvar_custom_chart_variable_set(rs, (NETDATA_DOUBLE)temp / 1000.0);
+        added[t] = 1;
+    }
+}
+
+static inline int is_numbered_entry(const char *name, const char *prefix, size_t prefix_len) {
+    return !strncmp(name, prefix, prefix_len) && isdigit(name[prefix_len]);
+}
+
+int do_sys_class_thermal(int update_every, usec_t dt) {
+    (void)dt;
+    static int do_zones = -1, do_cooling_devices = -1;
+    static char *dirname = NULL;
+
+    if(unlikely(do_zones == -1)) {
+        do_zones           = config_get_boolean("plugin:proc:/sys/class/thermal", "thermal zones", CONFIG_BOOLEAN_YES);
+        do_cooling_devices = config_get_boolean("plugin:proc:/sys/class/thermal", "cooling devices", CONFIG_BOOLEAN_YES);
+
+        char filename[FILENAME_MAX + 1];
+        snprintfz(filename, FILENAME_MAX, "%s%s", netdata_configured_host_prefix, "/sys/class/thermal");
+        dirname = config_get("plugin:proc:/sys/class/thermal", "directory to monitor", filename);
+    }
+
+    if(unlikely(do_zones == CONFIG_BOOLEAN_NO && do_cooling_devices == CONFIG_BOOLEAN_NO))
+        return 1;
+
+    DIR *dir = opendir(dirname);
+    if(unlikely(!dir)) {
+        error("Cannot read directory '%s'", dirname);
+        return 1;
+    }
+
+    char filename[FILENAME_MAX + 1];
+    struct dirent *de = NULL;
+    while(likely(de = readdir(dir))) {
+        if(unlikely(de->d_type != DT_LNK && de->d_type != DT_DIR))
+            continue;
+
+        uint32_t hash = simple_hash(de->d_name);
+
+        if(do_zones != CONFIG_BOOLEAN_NO && is_numbered_entry(de->d_name, THERMAL_ZONE_PREFIX, sizeof(THERMAL_ZONE_PREFIX) - 1)) {
+            struct thermal_zone *tz;
+            for(tz = thermal_zone_root; tz; tz = tz->next) {
+                if(unlikely(tz->hash == hash && !strcmp(tz->name, de->d_name)))
+                    break;
+            }
+
+            if(unlikely(!tz)) {
+                snprintfz(filename, FILENAME_MAX, "%s/%s/temp", dirname, de->d_name);
+
+                tz = callocz(sizeof(struct thermal_zone), 1);
+                tz->name = strdupz(de->d_name);
+                tz->hash = hash;
+                tz->type = read_thermal_type(dirname, de->d_name);
+                tz->temp_filename = strdupz(filename);
+                tz->next = thermal_zone_root;
+                thermal_zone_root = tz;
+            }
+
+            // disabled zones, or zones without a sensor, fail to read
+            tz->found = 1;
+            tz->updated = !read_single_signed_number_file(tz->temp_filename, &tz->temp);
+        }
+        else if(do_cooling_devices != CONFIG_BOOLEAN_NO && is_numbered_entry(de->d_name, COOLING_DEVICE_PREFIX, sizeof(COOLING_DEVICE_PREFIX) - 1)) {
+            struct cooling_device *cd;
+            for(cd = cooling_device_root; cd; cd = cd->next) {
+                if(unlikely(cd->hash == hash && !strcmp(cd->name, de->d_name)))
+                    break;
+            }
+
+            if(unlikely(!cd)) {
+                cd = callocz(sizeof(struct cooling_device), 1);
+                cd->name = strdupz(de->d_name);
+                cd->hash = hash;
+                cd->type = read_thermal_type(dirname, de->d_name);
+
+                snprintfz(filename, FILENAME_MAX, "%s/%s/cur_state", dirname, de->d_name);
+                cd->cur_state_filename = strdupz(filename);
+
+                snprintfz(filename, FILENAME_MAX, "%s/%s/max_state", dirname, de->d_name);
+                cd->max_state_filename = strdupz(filename);
+
+                cd->next = cooling_device_root;
+                cooling_device_root = cd;
+            }
+
+            cd->found = 1;
+            cd->updated = !read_single_number_file(cd->cur_state_filename, &cd->cur_state)
+                          && !read_single_number_file(cd->max_state_filename, &cd->max_state);
+        }
+    }
+
+    closedir(dir);
+
+    // --------------------------------------------------------------------
+
+    struct thermal_zone *tz = thermal_zone_root;
+    while(tz) {
+        if(unlikely(!tz->found)) {
+            struct thermal_zone *f = tz;
+            tz = tz->next;
+            thermal_zone_free(f);
+            continue;
+        }
+
+        if(unlikely(!tz->updated)) {
+            tz = tz->next;
+            continue;
+        }
+
+        if(unlikely(!tz->st)) {
+            tz->st = rrdset_create_localhost(
+                    "thermal"
+                    , tz->name
+                    , NULL
+                    , tz->type
+                    , "thermal.zone_temperature"
+                    , "Thermal zone temperature"
+                    , "Celsius"
+                    , PLUGIN_PROC_NAME
+                    , PLUGIN_PROC_MODULE_THERMAL_NAME
+                    , NETDATA_CHART_PRIO_THERMAL_ZONE_TEMPERATURE
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+            rrdlabels_add(tz->st->state->chart_labels, "zone", tz->name, RRDLABEL_SRC_AUTO);
+            rrdlabels_add(tz->st->state->chart_labels, "type", tz->type, RRDLABEL_SRC_AUTO);
+
+            tz->rd_temp = rrddim_add(tz->st, "temperature", NULL, 1, 1000, RRD_ALGORITHM_ABSOLUTE);
+
+            add_zone_trip_points_variables(dirname, tz);
+        }
+        else
+            rrdset_next(tz->st);
+
+        rrddim_set_by_pointer(tz->st, tz->rd_temp, tz->temp);
+        rrdset_done(tz->st);
+
+        tz = tz->next;
+    }
+
+    struct cooling_device *cd = cooling_device_root;
+    while(cd) {
+        if(unlikely(!cd->found)) {
+            struct cooling_device *f = cd;
+            cd = cd->next;
+            cooling_device_free(f);
+            continue;
+        }
+
+        if(unlikely(!cd->updated)) {
+            cd = cd->next;
+            continue;
+        }
+
+        if(unlikely(!cd->st)) {
+            cd->st = rrdset_create_localhost(
+                    "thermal"
+                    , cd->name
+                    , NULL
+                    , cd->type
+                    , "thermal.cooling_device_state"
+                    , "Cooling device state"
+                    , "state"
+                    , PLUGIN_PROC_NAME
+                    , PLUGIN_PROC_MODULE_THERMAL_NAME
+                    , NETDATA_CHART_PRIO_THERMAL_COOLING_DEVICE
+                    , update_every
+                    , RRDSET_TYPE_LINE
+            );
+
+            rrdlabels_add(cd->st->state->chart_labels, "device", cd->name, RRDLABEL_SRC_AUTO);
+            rrdlabels_add(cd->st->state->chart_labels, "type", cd->type, RRDLABEL_SRC_AUTO);
+
+            cd->rd_cur_state = rrddim_add(cd->st, "current", NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+            cd->rd_max_state = rrddim_add(cd->st, "max", NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+        }
+        else
+            rrdset_next(cd->st);
+
+        rrddim_set_by_pointer(cd->st, cd->rd_cur_state, cd->cur_state);
+        rrddim_set_by_pointer(cd->st, cd->rd_max_state, cd->max_state);
+        rrdset_done(cd->st);
+
+        cd = cd->next;
+    }
+
+    for(tz = thermal_zone_root; tz; tz = tz->next)
+        tz->found = 0;
+
+    for(cd = cooling_device_root; cd; cd = cd->next)
+        cd->found = 0;
+
+    return 0;
+}
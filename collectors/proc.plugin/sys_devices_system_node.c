@@ -2,15 +2,75 @@
 
 #include "plugin_proc.h"
 
+struct node_hugepages {
+    char *name;
+    char *nr_filename;
+    char *free_filename;
+    char *surplus_filename;
+    RRDSET *st;
+    RRDDIM *rd_free;
+    RRDDIM *rd_used;
+    RRDDIM *rd_surplus;
+    struct node_hugepages *next;
+};
+
 struct node {
     char *name;
     char *numastat_filename;
     procfile *numastat_ff;
     RRDSET *numastat_st;
+    RRDSET *locality_st;
+    RRDDIM *rd_locality_local;
+    RRDDIM *rd_locality_remote;
+    char *meminfo_filename;
+    procfile *meminfo_ff;
+    RRDSET *meminfo_st;
+    RRDDIM *rd_meminfo_free;
+    RRDDIM *rd_meminfo_file;
+    RRDDIM *rd_meminfo_anon;
+    RRDDIM *rd_meminfo_slab;
+    RRDDIM *rd_meminfo_other;
+    struct node_hugepages *hugepages_root;
     struct node *next;
 };
 static struct node *numa_root = NULL;
 
+static void find_node_hugepages(struct node *m, const char *dirname) {
+    char name[FILENAME_MAX + 1];
+    snprintfz(name, FILENAME_MAX, "%s/%s/hugepages", dirname, m->name);
+
+    DIR *dir = opendir(name);
+    if(!dir)
+        return;
+
+    struct dirent *de = NULL;
+    while((de = readdir(dir))) {
+        if(de->d_type != DT_DIR)
+            continue;
+
+        // hugepages-2048kB, hugepages-1048576kB
+        if(strncmp(de->d_name, "hugepages-", 10) != 0 || !isdigit(de->d_name[10]))
+            continue;
+
+        struct node_hugepages *h = callocz(1, sizeof(struct node_hugepages));
+        h->name = strdupz(&de->d_name[10]);
+
+        snprintfz(name, FILENAME_MAX, "%s/%s/hugepages/%s/nr_hugepages", dirname, m->name, de->d_name);
+        h->nr_filename = strdupz(name);
+
+        snprintfz(name, FILENAME_MAX, "%s/%s/hugepages/%s/free_hugepages", dirname, m->name, de->d_name);
+        h->free_filename = strdupz(name);
+
+        snprintfz(name, FILENAME_MAX, "%s/%s/hugepages/%s/surplus_hugepages", dirname, m->name, de->d_name);
+        h->surplus_filename = strdupz(name);
+
+        h->next = m->hugepages_root;
+        m->hugepages_root = h;
+    }
+
+    closedir(dir);
+}
+
 static int find_all_nodes() {
     int numa_node_count = 0;
     char name[FILENAME_MAX + 1];
@@ -50,6 +110,12 @@ static int find_all_nodes() {
 
         m->numastat_filename = strdupz(name);
 
+        snprintfz(name, FILENAME_MAX, "%s/%s/meminfo", dirname, de->d_name);
+        if(stat(name, &st) != -1)
+            m->meminfo_filename = strdupz(name);
+
+        find_node_hugepages(m, dirname);
+
         m->next = numa_root;
         numa_root = m;
     }
@@ -59,11 +125,153 @@ static int find_all_nodes() {
     return numa_node_count;
 }
 
+static void do_node_meminfo(struct node *m, int update_every) {
+    static uint32_t hash_mem_free = 0, hash_mem_used = 0, hash_file_pages = 0, hash_anon_pages = 0, hash_slab = 0;
+
+    if(unlikely(!hash_mem_free)) {
+        hash_mem_free   = simple_hash("MemFree");
+        hash_mem_used   = simple_hash("MemUsed");
+        hash_file_pages = simple_hash("FilePages");
+        hash_anon_pages = simple_hash("AnonPages");
+        hash_slab       = simple_hash("Slab");
+    }
+
+    if(unlikely(!m->meminfo_ff)) {
+        m->meminfo_ff = procfile_open(m->meminfo_filename, " :", PROCFILE_FLAG_DEFAULT);
+
+        if(unlikely(!m->meminfo_ff))
+            return;
+    }
+
+    m->meminfo_ff = procfile_readall(m->meminfo_ff);
+    if(unlikely(!m->meminfo_ff || procfile_lines(m->meminfo_ff) < 1))
+        return;
+
+    unsigned long long mem_free = 0, mem_used = 0, file_pages = 0, anon_pages = 0, slab = 0;
+
+    // Node 0 MemFree:         3233044 kB
+    size_t lines = procfile_lines(m->meminfo_ff), l;
+    for(l = 0; l < lines; l++) {
+        size_t words = procfile_linewords(m->meminfo_ff, l);
+        if(unlikely(words < 4))
+            continue;
+
+        char *name  = procfile_lineword(m->meminfo_ff, l, 2);
+        char *value = procfile_lineword(m->meminfo_ff, l, 3);
+
+        uint32_t hash = simple_hash(name);
+        if(hash == hash_mem_free && !strcmp(name, "MemFree"))
+            mem_free = str2ull(value);
+        else if(hash == hash_mem_used && !strcmp(name, "MemUsed"))
+            mem_used = str2ull(value);
+        else if(hash == hash_file_pages && !strcmp(name, "FilePages"))
+            file_pages = str2ull(value);
+        else if(hash == hash_anon_pages && !strcmp(name, "AnonPages"))
+            anon_pages = str2ull(value);
+        else if(hash == hash_slab && !strcmp(name, "Slab"))
+            slab = str2ull(value);
+    }
+
+    unsigned long long other = mem_used > file_pages + anon_pages + slab ? mem_used - file_pages - anon_pages - slab : 0;
+
+    if(unlikely(!m->meminfo_st)) {
+        char id[RRD_ID_LENGTH_MAX + 1];
+        snprintfz(id, RRD_ID_LENGTH_MAX, "%s_memory", m->name);
+
+        m->meminfo_st = rrdset_create_localhost(
+                "mem"
+                , id
+                , NULL
+                , "numa"
+                , "mem.numa_node_memory"
+                , "NUMA node memory usage"
+                , "MiB"
+                , PLUGIN_PROC_NAME
+                , "/sys/devices/system/node"
+                , NETDATA_CHART_PRIO_MEM_NUMA_NODES_MEMINFO
+                , update_every
+                , RRDSET_TYPE_STACKED
+        );
+
+        rrdlabels_add(m->meminfo_st->state->chart_labels, "numa_node", m->name, RRDLABEL_SRC_AUTO);
+
+        rrdset_flag_set(m->meminfo_st, RRDSET_FLAG_DETAIL);
+
+        m->rd_meminfo_free  = rrddim_add(m->meminfo_st, "free",  NULL, 1, 1024, RRD_ALGORITHM_ABSOLUTE);
+        m->rd_meminfo_file  = rrddim_add(m->meminfo_st, "file",  NULL, 1, 1024, RRD_ALGORITHM_ABSOLUTE);
+        m->rd_meminfo_anon  = rrddim_add(m->meminfo_st, "anon",  NULL, 1, 1024, RRD_ALGORITHM_ABSOLUTE);
+        m->rd_meminfo_slab  = rrddim_add(m->meminfo_st, "slab",  NULL, 1, 1024, RRD_ALGORITHM_ABSOLUTE);
+        m->rd_meminfo_other = rrddim_add(m->meminfo_st, "other", NULL, 1, 1024, RRD_ALGORITHM_ABSOLUTE);
+    }
+    else rrdset_next(m->meminfo_st);
+
+    rrddim_set_by_pointer(m->meminfo_st, m->rd_meminfo_free,  (collected_number)mem_free);
+    rrddim_set_by_pointer(m->meminfo_st, m->rd_meminfo_file,  (collected_number)file_pages);
+    rrddim_set_by_pointer(m->meminfo_st, m->rd_meminfo_anon,  (collected_number)anon_pages);
+    rrddim_set_by_pointer(m->meminfo_st, m->rd_meminfo_slab,  (collected_number)slab);
+    rrddim_set_by_pointer(m->meminfo_st, m->rd_meminfo_other, (collected_number)other);
+    rrdset_done(m->meminfo_st);
+}
+
+static void do_node_hugepages(struct node *m, int update_every) {
+    struct node_hugepages *h;
+    for(h = m->hugepages_root; h; h = h->next) {
+        unsigned long long nr = 0, free_pages = 0, surplus = 0;
+
+        if(unlikely(read_single_number_file(h->nr_filename, &nr)
+                    || read_single_number_file(h->free_filename, &free_pages)
+                    || read_single_number_file(h->surplus_filename, &surplus)))
+            continue;
+
+        // pools that were never configured are not charted
+        if(unlikely(!h->st && !nr && !surplus && netdata_zero_metrics_enabled != CONFIG_BOOLEAN_YES))
+            continue;
+
+        if(unlikely(!h->st)) {
+            char id[RRD_ID_LENGTH_MAX + 1];
+            snprintfz(id, RRD_ID_LENGTH_MAX, "%s_hugepages_%s", m->name, h->name);
+
+            h->st = rrdset_create_localhost(
+                    "mem"
+                    , id
+                    , NULL
+                    , "numa"
+                    , "mem.numa_node_hugepages"
+                    , "NUMA node hugepages"
+                    , "pages"
+                    , PLUGIN_PROC_NAME
+                    , "/sys/devices/system/node"
+                    , NETDATA_CHART_PRIO_MEM_NUMA_NODES_HUGEPAGES
+                    , update_every
+                    , RRDSET_TYPE_STACKED
+            );
+
+            rrdlabels_add(h->st->state->chart_labels, "numa_node", m->name, RRDLABEL_SRC_AUTO);
+            rrdlabels_add(h->st->state->chart_labels, "page_size", h->name, RRDLABEL_SRC_AUTO);
+
+            rrdset_flag_set(h->st, RRDSET_FLAG_DETAIL);
+
+            h->rd_free    = rrddim_add(h->st, "free",    NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+            h->rd_used    = rrddim_add(h->st, "used",    NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+            h->rd_surplus = rrddim_add(h->st, "surplus", NULL, 1, 1, RRD_ALGORITHM_ABSOLUTE);
+        }
+        else rrdset_next(h->st);
+
+        // nr_hugepages includes the surplus pages
+        unsigned long long persistent = nr > surplus ? nr - surplus : 0;
+
+        rrddim_set_by_pointer(h->st, h->rd_free,    (collected_number)free_pages);
+        rrddim_set_by_pointer(h->st, h->rd_used,    (collected_number)(persistent > free_pages ? persistent - free_pages : 0));
+        rrddim_set_by_pointer(h->st, h->rd_surplus, (collected_number)surplus);
+        rrdset_done(h->st);
+    }
+}
+
 int do_proc_sys_devices_system_node(int update_every, usec_t dt) {
     (void)dt;
 
     static uint32_t hash_local_node = 0, hash_numa_foreign = 0, hash_interleave_hit = 0, hash_other_node = 0, hash_numa_hit = 0, hash_numa_miss = 0;
-    static int do_numastat = -1, numa_node_count = 0;
+    static int do_numastat = -1, do_meminfo = -1, do_hugepages = -1, numa_node_count = 0;
     struct node *m;
 
     if(unlikely(numa_root == NULL)) {
@@ -73,7 +281,9 @@ int do_proc_sys_devices_system_node(int update_every, usec_t dt) {
     }
 
     if(unlikely(do_numastat == -1)) {
-        do_numastat = config_get_boolean_ondemand("plugin:proc:/sys/devices/system/node", "enable per-node numa metrics", CONFIG_BOOLEAN_AUTO);
+        do_numastat  = config_get_boolean_ondemand("plugin:proc:/sys/devices/system/node", "enable per-node numa metrics", CONFIG_BOOLEAN_AUTO);
+        do_meminfo   = config_get_boolean_ondemand("plugin:proc:/sys/devices/system/node", "enable per-node memory usage", CONFIG_BOOLEAN_AUTO);
+        do_hugepages = config_get_boolean_ondemand("plugin:proc:/sys/devices/system/node", "enable per-node hugepages", CONFIG_BOOLEAN_AUTO);
 
         hash_local_node     = simple_hash("local_node");
         hash_numa_foreign   = simple_hash("numa_foreign");
@@ -129,6 +339,8 @@ int do_proc_sys_devices_system_node(int update_every, usec_t dt) {
                 }
                 else rrdset_next(m->numastat_st);
 
+                collected_number local_node = 0, other_node = 0;
+
                 size_t lines = procfile_lines(m->numastat_ff), l;
                 for(l = 0; l < lines; l++) {
                     size_t words = procfile_linewords(m->numastat_ff, l);
@@ -146,6 +358,11 @@ int do_proc_sys_devices_system_node(int update_every, usec_t dt) {
                         continue;
 
                     uint32_t hash = simple_hash(name);
+                    if(hash == hash_local_node && !strcmp(name, "local_node"))
+                        local_node = (collected_number)str2kernel_uint_t(value);
+                    else if(hash == hash_other_node && !strcmp(name, "other_node"))
+                        other_node = (collected_number)str2kernel_uint_t(value);
+
                     if(likely(
                                (hash == hash_numa_hit       && !strcmp(name, "numa_hit"))
                             || (hash == hash_numa_miss      && !strcmp(name, "numa_miss"))
@@ -158,9 +375,56 @@ int do_proc_sys_devices_system_node(int update_every, usec_t dt) {
                 }
 
                 rrdset_done(m->numastat_st);
+
+                // the pages allocated from this node, split by where the allocating process was running
+                if(unlikely(!m->locality_st)) {
+                    char id[RRD_ID_LENGTH_MAX + 1];
+                    snprintfz(id, RRD_ID_LENGTH_MAX, "%s_locality", m->name);
+
+                    m->locality_st = rrdset_create_localhost(
+                            "mem"
+                            , id
+                            , NULL
+                            , "numa"
+                            , "mem.numa_node_locality"
+                            , "NUMA node allocations locality"
+                            , "percentage"
+                            , PLUGIN_PROC_NAME
+                            , "/sys/devices/system/node"
+                            , NETDATA_CHART_PRIO_MEM_NUMA_NODES_LOCALITY
+                            , update_every
+                            , RRDSET_TYPE_STACKED
+                    );
+
+                    rrdlabels_add(m->locality_st->state->chart_labels, "numa_node", m->name, RRDLABEL_SRC_AUTO);
+
+                    rrdset_flag_set(m->locality_st, RRDSET_FLAG_DETAIL);
+
+                    m->rd_locality_local  = rrddim_add(m->locality_st, "local",  NULL, 1, 1, RRD_ALGORITHM_PCENT_OVER_DIFF_TOTAL);
+                    m->rd_locality_remote = rrddim_add(m->locality_st, "remote", NULL, 1, 1, RRD_ALGORITHM_PCENT_OVER_DIFF_TOTAL);
+                }
+                else rrdset_next(m->locality_st);
+
+                rrddim_set_by_pointer(m->locality_st, m->rd_locality_local,  local_node);
+                rrddim_set_by_pointer(m->locality_st, m->rd_locality_remote, other_node);
+                rrdset_done(m->locality_st);
             }
         }
     }
 
+    if(do_meminfo == CONFIG_BOOLEAN_YES || (do_meminfo == CONFIG_BOOLEAN_AUTO &&
+                                            (numa_node_count >= 2 || netdata_zero_metrics_enabled == CONFIG_BOOLEAN_YES))) {
+        for(m = numa_root; m; m = m->next) {
+            if(m->meminfo_filename)
+                do_node_meminfo(m, update_every);
+        }
+    }
+
+    if(do_hugepages == CONFIG_BOOLEAN_YES || (do_hugepages == CONFIG_BOOLEAN_AUTO &&
+                                              (numa_node_count >= 2 || netdata_zero_metrics_enabled == CONFIG_BOOLEAN_YES))) {
+        for(m = numa_root; m; m = m->next)
+            do_node_hugepages(m, update_every);
+    }
+
     return 0;
 }
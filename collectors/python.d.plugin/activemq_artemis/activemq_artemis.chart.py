@@ -0,0 +1,313 @@
+# -*- coding: utf-8 -*-
+# Description: activemq artemis netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+from copy import deepcopy
+from fnmatch import fnmatch
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+MBEAN_BROKER = 'org.apache.activemq.artemis:broker=*'
+MBEAN_ADDRESSES = 'org.apache.activemq.artemis:broker=*,component=addresses,address=*'
+MBEAN_QUEUES = ('org.apache.activemq.artemis:broker=*,component=addresses,address=*,subcomponent=queues,'
+                'routing-type=*,queue=*')
+
+BROKER_ATTRIBUTES = (
+    ('ConnectionCount', 'connections'),
+    ('TotalConsumerCount', 'consumers'),
+    ('TotalMessageCount', 'messages'),
+    ('TotalMessagesAdded', 'messages_added'),
+    ('TotalMessagesAcknowledged', 'messages_acknowledged'),
+    ('AddressMemoryUsage', 'address_memory_used'),
+    ('AddressMemoryUsagePercentage', 'address_memory_usage'),
+    ('DiskStoreUsage', 'disk_store_usage'),
+)
+
+ADDRESS_ATTRIBUTES = (
+    ('NumberOfMessages', 'messages'),
+    ('AddressSize', 'size'),
+    ('RoutedMessageCount', 'routed'),
+    ('UnRoutedMessageCount', 'unrouted'),
+)
+
+QUEUE_ATTRIBUTES = (
+    ('MessageCount', 'messages'),
+    ('DeliveringCount', 'delivering'),
+    ('ConsumerCount', 'consumers'),
+    ('MessagesAdded', 'added'),
+    ('MessagesAcknowledged', 'acknowledged'),
+    ('MessagesExpired', 'expired'),
+    ('MessagesKilled', 'killed'),
+)
+
+# the attributes missing on some mbeans of the patterns are ignored
+READ_CONFIG = {'ignoreErrors': True}
+
+# a single jolokia bulk request
+READ_REQUESTS = [
+    {'type': 'read', 'mbean': MBEAN_BROKER, 'attribute': [a for a, _ in BROKER_ATTRIBUTES], 'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_ADDRESSES, 'attribute': [a for a, _ in ADDRESS_ATTRIBUTES],
+     'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_QUEUES, 'attribute': [a for a, _ in QUEUE_ATTRIBUTES], 'config': READ_CONFIG},
+]
+
+ORDER = [
+    'connections',
+    'consumers',
+    'messages',
+    'message_rates',
+    'address_memory',
+    'address_memory_usage',
+    'disk_store_usage',
+]
+
+CHARTS = {
+    'connections': {
+        'options': [None, 'Client Connections', 'connections', 'broker', 'activemq_artemis.connections', 'line'],
+        'lines': [
+            ['connections', 'connections'],
+        ]
+    },
+    'consumers': {
+        'options': [None, 'Consumers', 'consumers', 'broker', 'activemq_artemis.consumers', 'line'],
+        'lines': [
+            ['consumers', 'consumers'],
+        ]
+    },
+    'messages': {
+        'options': [None, 'Messages In Queues', 'messages', 'broker', 'activemq_artemis.messages', 'line'],
+        'lines': [
+            ['messages', 'messages'],
+        ]
+    },
+    'message_rates': {
+        'options': [None, 'Message Rates', 'messages/s', 'broker', 'activemq_artemis.message_rates', 'line'],
+        'lines': [
+            ['messages_added', 'added', 'incremental'],
+            ['messages_acknowledged', 'acknowledged', 'incremental'],
+        ]
+    },
+    'address_memory': {
+        'options': [None, 'Address Memory', 'MiB', 'memory', 'activemq_artemis.address_memory', 'area'],
+        'lines': [
+            ['address_memory_used', 'used', 'absolute', 1, 1 << 20],
+        ]
+    },
+    'address_memory_usage': {
+        'options': [None, 'Address Memory Usage Of The Global Max Size', 'percentage', 'memory',
+                    'activemq_artemis.address_memory_usage', 'area'],
+        'lines': [
+            ['address_memory_usage', 'used', 'absolute', 1, 100],
+        ]
+    },
+    'disk_store_usage': {
+        'options': [None, 'Disk Store Usage', 'percentage', 'memory', 'activemq_artemis.disk_store_usage', 'area'],
+        'lines': [
+            ['disk_store_usage', 'used', 'absolute', 1, 100],
+        ]
+    },
+}
+
+
+def address_charts(address_id, address):
+    order = [
+        'address_{0}_messages'.format(address_id),
+        'address_{0}_routed'.format(address_id),
+        'address_{0}_size'.format(address_id),
+    ]
+    family = 'address ' + address
+    charts = {
+        order[0]: {
+            'options': [None, 'Address Messages', 'messages', family, 'activemq_artemis.address_messages', 'line'],
+            'lines': [
+                ['address_{0}_messages'.format(address_id), 'messages'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Address Routed Messages', 'messages/s', family, 'activemq_artemis.address_routed',
+                        'line'],
+            'lines': [
+                ['address_{0}_routed'.format(address_id), 'routed', 'incremental'],
+                ['address_{0}_unrouted'.format(address_id), 'unrouted', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Address Size', 'KiB', family, 'activemq_artemis.address_size', 'area'],
+            'lines': [
+                ['address_{0}_size'.format(address_id), 'size', 'absolute', 1, 1 << 10],
+            ]
+        },
+    }
+    return order, charts
+
+
+def queue_charts(queue_id, address, queue):
+    order = [
+        'queue_{0}_messages'.format(queue_id),
+        'queue_{0}_consumers'.format(queue_id),
+        'queue_{0}_rates'.format(queue_id),
+    ]
+    family = 'address ' + address
+    charts = {
+        order[0]: {
+            'options': [None, 'Queue {0} Messages'.format(queue), 'messages', family,
+                        'activemq_artemis.queue_messages', 'line'],
+            'lines': [
+                ['queue_{0}_messages'.format(queue_id), 'messages'],
+                ['queue_{0}_delivering'.format(queue_id), 'delivering'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Queue {0} Consumers'.format(queue), 'consumers', family,
+                        'activemq_artemis.queue_consumers', 'line'],
+            'lines': [
+                ['queue_{0}_consumers'.format(queue_id), 'consumers'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Queue {0} Message Rates'.format(queue), 'messages/s', family,
+                        'activemq_artemis.queue_rates', 'line'],
+            'lines': [
+                ['queue_{0}_added'.format(queue_id), 'added', 'incremental'],
+                ['queue_{0}_acknowledged'.format(queue_id), 'acknowledged', 'incremental'],
+                ['queue_{0}_expired'.format(queue_id), 'expired', 'incremental'],
+                ['queue_{0}_killed'.format(queue_id), 'killed', 'incremental'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def parse_mbean(name):
+    """
+    :param name: <str> 'domain:key="value",key=value', jolokia sorts the keys
+    :return: <dict> of the key properties, without the quotes
+    """
+    _, _, properties = name.partition(':')
+    return dict((k, v.strip('"')) for k, _, v in (p.partition('=') for p in properties.split(',')) if v)
+
+
+class AddressSelector:
+    """
+    Shell wildcard patterns, patterns starting with '!' are negative, the first matching pattern wins.
+    Addresses not matching any pattern are selected only if all the patterns are negative.
+    """
+
+    def __init__(self, patterns):
+        self.patterns = list()
+        for pattern in patterns or list():
+            pattern = str(pattern)
+            self.patterns.append((not pattern.startswith('!'), pattern.lstrip('!')))
+        self.default = not any(p[0] for p in self.patterns)
+
+    def selected(self, address):
+        for positive, pattern in self.patterns:
+            if fnmatch(address, pattern):
+                return positive
+        return self.default
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8161/console/jolokia').rstrip('/') + '/'
+        self.method = 'POST'
+        self.body = json.dumps(READ_REQUESTS)
+        # the console checks the origin of the jolokia requests, see jolokia-access.xml
+        self.header = dict(self.header or dict(), Origin=self.configuration.get('origin', 'http://localhost'))
+        self.do_addresses = self.configuration.get('address_charts', True)
+        self.selector = AddressSelector(self.configuration.get('addresses'))
+        # address or queue id -> <list> of its charts
+        self.collected = dict()
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        try:
+            responses = json.loads(raw)
+        except ValueError as error:
+            self.error('invalid jolokia response: {0}'.format(error))
+            return None
+
+        mbeans = dict()
+        for response in responses:
+            if response.get('status') != 200:
+                self.debug('jolokia {0}: {1}'.format(response.get('request', dict()).get('mbean'),
+                                                     response.get('error')))
+                continue
+            mbeans.update(response['value'])
+
+        if not mbeans:
+            self.error('no artemis mbeans found, is the url the jolokia endpoint of the management console?')
+            return None
+
+        data = dict()
+        seen = set()
+        for mbean, attributes in mbeans.items():
+            properties = parse_mbean(mbean)
+            component, address = properties.get('component'), properties.get('address')
+            if component is None:
+                data.update(self.broker_data(attributes))
+                continue
+            if component != 'addresses' or not address:
+                continue
+            if not self.do_addresses or not self.selector.selected(address):
+                continue
+
+            if properties.get('subcomponent') == 'queues':
+                queue = properties.get('queue')
+                if not queue:
+                    continue
+                chart_id, prefix = 'queue_' + clean_name(queue), 'queue_{0}_'.format(clean_name(queue))
+                if chart_id not in self.collected:
+                    self.add_collected_charts(chart_id, *queue_charts(clean_name(queue), address, queue))
+                metrics = QUEUE_ATTRIBUTES
+            elif 'subcomponent' not in properties:
+                chart_id, prefix = 'address_' + clean_name(address), 'address_{0}_'.format(clean_name(address))
+                if chart_id not in self.collected:
+                    self.add_collected_charts(chart_id, *address_charts(clean_name(address), address))
+                metrics = ADDRESS_ATTRIBUTES
+            else:
+                continue
+
+            seen.add(chart_id)
+            for attribute, key in metrics:
+                data[prefix + key] = attributes.get(attribute) or 0
+
+        # deleted addresses and queues, auto-created ones are deleted when they are not used
+        for chart_id in set(self.collected) - seen:
+            self.remove_collected_charts(chart_id)
+
+        return data
+
+    @staticmethod
+    def broker_data(attributes):
+        data = dict()
+        for attribute, key in BROKER_ATTRIBUTES:
+            value = attributes.get(attribute)
+            if value is None:
+                continue
+            if key == 'address_memory_usage':
+                # percentage, 0 - 100
+                value = int(value * 100)
+            elif key == 'disk_store_usage':
+                # 0.0 - 1.0
+                value = int(value * 10000)
+            data[key] = value
+        return data
+
+    def add_collected_charts(self, chart_id, order, charts):
+        self.collected[chart_id] = order
+        self.add_charts(order, charts)
+
+    def remove_collected_charts(self, chart_id):
+        self.remove_charts(self.collected.pop(chart_id))
+
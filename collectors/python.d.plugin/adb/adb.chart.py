@@ -0,0 +1,219 @@
+# -*- coding: utf-8 -*-
+# Description: adb netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import re
+from copy import deepcopy
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import find_binary
+
+disabled_by_default = True
+
+update_every = 10
+
+ADB = 'adb'
+
+ORDER = [
+    'devices',
+]
+
+CHARTS = {
+    'devices': {
+        'options': [None, 'Devices', 'devices', 'devices', 'adb.devices', 'stacked'],
+        'lines': [
+            ['devices_online', 'online'],
+            ['devices_offline', 'offline'],
+            ['devices_unauthorized', 'unauthorized'],
+        ]
+    },
+}
+
+
+def device_charts(serial, model):
+    family = '{0} {1}'.format(model, serial) if model else serial
+    order = [
+        'device_{0}_battery_level'.format(serial),
+        'device_{0}_battery_temperature'.format(serial),
+        'device_{0}_battery_voltage'.format(serial),
+        'device_{0}_power_source'.format(serial),
+        'device_{0}_storage'.format(serial),
+        'device_{0}_uptime'.format(serial),
+    ]
+    charts = {
+        order[0]: {
+            'options': [None, 'Battery Level', 'percentage', family, 'adb.battery_level', 'line'],
+            'lines': [
+                ['device_{0}_battery_level'.format(serial), 'level'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Battery Temperature', 'celsius', family, 'adb.battery_temperature', 'line'],
+            'lines': [
+                ['device_{0}_battery_temperature'.format(serial), 'temperature', 'absolute', 1, 10],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Battery Voltage', 'volts', family, 'adb.battery_voltage', 'line'],
+            'lines': [
+                ['device_{0}_battery_voltage'.format(serial), 'voltage', 'absolute', 1, 1000],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Power Source', 'status', family, 'adb.power_source', 'line'],
+            'lines': [
+                ['device_{0}_powered_ac'.format(serial), 'ac'],
+                ['device_{0}_powered_usb'.format(serial), 'usb'],
+                ['device_{0}_powered_wireless'.format(serial), 'wireless'],
+                ['device_{0}_powered_none'.format(serial), 'battery'],
+            ]
+        },
+        order[4]: {
+            'options': [None, 'Data Partition Usage', 'MiB', family, 'adb.storage', 'stacked'],
+            'lines': [
+                ['device_{0}_storage_avail'.format(serial), 'avail', 'absolute', 1, 1 << 10],
+                ['device_{0}_storage_used'.format(serial), 'used', 'absolute', 1, 1 << 10],
+            ]
+        },
+        order[5]: {
+            'options': [None, 'Uptime', 'seconds', family, 'adb.uptime', 'line'],
+            'lines': [
+                ['device_{0}_uptime'.format(serial), 'uptime'],
+            ]
+        },
+    }
+    return order, charts
+
+
+# 'shell' runs all of them in one round trip, the output sections are told apart by their content
+DEVICE_SHELL_COMMAND = 'dumpsys battery; cat /proc/uptime; df -k /data'
+
+RE_UPTIME = re.compile(r'^(\d+)\.\d+ \d+\.\d+$')
+RE_KEY_VALUE = re.compile(r'^([A-Za-z ]+): (\S+)$')
+RE_DF = re.compile(r'^\S+\s+(\d+)\s+(\d+)\s+(\d+)\s+\d+%\s+/data$')
+
+BATTERY_STATS = {
+    'level': 'battery_level',
+    'temperature': 'battery_temperature',
+    'voltage': 'battery_voltage',
+    'AC powered': 'powered_ac',
+    'USB powered': 'powered_usb',
+    'Wireless powered': 'powered_wireless',
+}
+
+
+def parse_devices(raw):
+    devices = list()
+    for line in raw:
+        parts = line.split()
+        if len(parts) < 2 or line.startswith('List of devices'):
+            continue
+        serial, state = parts[0], parts[1]
+        model = str()
+        for part in parts[2:]:
+            if part.startswith('model:'):
+                model = part[6:]
+        devices.append((serial, state, model))
+    return devices
+
+
+def parse_device_stats(raw):
+    stats = dict()
+    for line in raw:
+        line = line.strip()
+
+        match = RE_KEY_VALUE.match(line)
+        if match and match.group(1) in BATTERY_STATS:
+            key, value = BATTERY_STATS[match.group(1)], match.group(2)
+            if value in ('true', 'false'):
+                value = int(value == 'true')
+            stats[key] = value
+            continue
+
+        match = RE_UPTIME.match(line)
+        if match:
+            stats['uptime'] = match.group(1)
+            continue
+
+        match = RE_DF.match(line)
+        if match:
+            stats['storage_used'] = match.group(2)
+            stats['storage_avail'] = match.group(3)
+
+    powered = [k for k in ('powered_ac', 'powered_usb', 'powered_wireless') if k in stats]
+    if powered:
+        stats['powered_none'] = int(not any(stats[k] for k in powered))
+
+    return stats
+
+
+def clean_serial(serial):
+    # network devices are listed as 'host:port'
+    return serial.replace(':', '_').replace('.', '_')
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.adb = self.configuration.get('adb_path')
+        self.devices = self.configuration.get('devices') or list()
+        self.collected_devices = set()
+
+    def check(self):
+        self.adb = self.adb or find_binary(ADB)
+        if not self.adb:
+            self.error('can\'t locate "{0}" binary'.format(ADB))
+            return False
+
+        data = self._get_data()
+        if not data:
+            return False
+
+        if not data.get('devices_online') and not self.devices:
+            self.error('no online devices found')
+            return False
+        return True
+
+    def _get_data(self):
+        raw = self._get_raw_data(command=[self.adb, 'devices', '-l'])
+        if raw is None:
+            return None
+
+        data = {
+            'devices_online': 0,
+            'devices_offline': 0,
+            'devices_unauthorized': 0,
+        }
+
+        for serial, state, model in parse_devices(raw):
+            if self.devices and serial not in self.devices:
+                continue
+
+            if state == 'device':
+                data['devices_online'] += 1
+            elif state == 'unauthorized':
+                data['devices_unauthorized'] += 1
+                continue
+            else:
+                data['devices_offline'] += 1
+                continue
+
+            raw_stats = self._get_raw_data(command=[self.adb, '-s', serial, 'shell', DEVICE_SHELL_COMMAND])
+            if not raw_stats:
+                continue
+
+            serial_id = clean_serial(serial)
+            if serial_id not in self.collected_devices:
+                self.collected_devices.add(serial_id)
+                self.add_device_charts(serial_id, model)
+
+            for key, value in parse_device_stats(raw_stats).items():
+                data['device_{0}_{1}'.format(serial_id, key)] = value
+
+        return data
+
+    def add_device_charts(self, serial, model):
+        order, charts = device_charts(serial, model)
+        self.add_charts(order, charts)
@@ -120,7 +120,8 @@ def tube_chart_template(name):
         '{0}_jobs'.format(name),
         '{0}_connections'.format(name),
         '{0}_commands'.format(name),
-        '{0}_pause'.format(name)
+        '{0}_pause'.format(name),
+        '{0}_workers'.format(name)
     ]
     family = 'tube {0}'.format(name)
 
@@ -162,6 +163,13 @@ def tube_chart_template(name):
                 ['_'.join([name, 'pause']), 'since'],
                 ['_'.join([name, 'pause-time-left']), 'left']
             ]
+        },
+        order[5]: {
+            'options': [None, 'Watching Workers', 'workers', family, 'beanstalk.workers', 'stacked'],
+            'lines': [
+                ['_'.join([name, 'current-busy']), 'busy'],
+                ['_'.join([name, 'current-waiting']), 'idle']
+            ]
         }
     }
 
@@ -207,6 +215,9 @@ class Service(SimpleService):
 
                 for stat in stats:
                     data['_'.join([tube, stat])] = stats[stat]
+                # workers watching the tube but not blocked in a reserve, they are processing a job
+                data['_'.join([tube, 'current-busy'])] = max(
+                    stats.get('current-watching', 0) - stats.get('current-waiting', 0), 0)
 
         except beanstalkc.SocketError:
             self.alive = False
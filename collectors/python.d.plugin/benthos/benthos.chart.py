@@ -0,0 +1,127 @@
+# -*- coding: utf-8 -*-
+# Description: benthos netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse
+
+update_every = 5
+
+API_METRICS = 'metrics'
+API_READY = 'ready'
+
+# streams mode labels every series with the stream it belongs to
+DEFAULT_STREAM = 'pipeline'
+
+METRICS = (
+    'input_received',
+    'processor_sent',
+    'output_sent',
+    'processor_error',
+    'output_error',
+    'input_connection_failed',
+    'input_connection_lost',
+    'output_connection_failed',
+    'output_connection_lost',
+    'input_connection_up',
+    'output_connection_up',
+)
+
+ORDER = [
+    'ready',
+]
+
+CHARTS = {
+    'ready': {
+        'options': [None, 'Readiness', 'status', 'ready', 'benthos.ready', 'line'],
+        'lines': [
+            ['ready', 'ready'],
+        ]
+    },
+}
+
+
+def stream_charts(stream_id, stream):
+    order = [
+        'stream_{0}_messages'.format(stream_id),
+        'stream_{0}_errors'.format(stream_id),
+        'stream_{0}_connection_errors'.format(stream_id),
+        'stream_{0}_connections'.format(stream_id),
+    ]
+    family = stream
+    charts = {
+        order[0]: {
+            'options': [None, 'Messages', 'messages/s', family, 'benthos.messages', 'line'],
+            'lines': [
+                ['stream_{0}_input_received'.format(stream_id), 'received', 'incremental'],
+                ['stream_{0}_processor_sent'.format(stream_id), 'processed', 'incremental'],
+                ['stream_{0}_output_sent'.format(stream_id), 'sent', 'incremental'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Errors', 'errors/s', family, 'benthos.errors', 'stacked'],
+            'lines': [
+                ['stream_{0}_processor_error'.format(stream_id), 'processor', 'incremental'],
+                ['stream_{0}_output_error'.format(stream_id), 'output', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Connection Errors', 'errors/s', family, 'benthos.connection_errors', 'stacked'],
+            'lines': [
+                ['stream_{0}_input_connection_failed'.format(stream_id), 'input failed', 'incremental'],
+                ['stream_{0}_input_connection_lost'.format(stream_id), 'input lost', 'incremental'],
+                ['stream_{0}_output_connection_failed'.format(stream_id), 'output failed', 'incremental'],
+                ['stream_{0}_output_connection_lost'.format(stream_id), 'output lost', 'incremental'],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Connected Components', 'components', family, 'benthos.connections', 'line'],
+            'lines': [
+                ['stream_{0}_input_connection_up'.format(stream_id), 'inputs'],
+                ['stream_{0}_output_connection_up'.format(stream_id), 'outputs'],
+            ]
+        },
+    }
+    return order, charts
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:4195').rstrip('/')
+        self.collected_streams = set()
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_METRICS))
+        if not raw:
+            return None
+
+        samples = parse(raw, names=METRICS)
+        if not samples:
+            self.error('no benthos metrics found at {0}/{1}'.format(self.url, API_METRICS))
+            return None
+
+        data = dict()
+        for name, labels, value in samples:
+            stream = labels.get('stream') or DEFAULT_STREAM
+            stream_id = clean_name(stream)
+            if stream_id not in self.collected_streams:
+                self.collected_streams.add(stream_id)
+                self.add_stream_charts(stream_id, stream)
+
+            key = 'stream_{0}_{1}'.format(stream_id, name)
+            data[key] = data.get(key, 0) + int(value)
+
+        # /ready answers 503 while an input or output is not connected
+        data['ready'] = int(self._get_raw_data('{0}/{1}'.format(self.url, API_READY)) is not None)
+
+        return data
+
+    def add_stream_charts(self, stream_id, stream):
+        order, charts = stream_charts(stream_id, stream)
+        self.add_charts(order, charts)
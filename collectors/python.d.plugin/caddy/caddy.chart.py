@@ -0,0 +1,239 @@
+# -*- coding: utf-8 -*-
+# Description: caddy netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import socket
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse, sum_by
+
+update_every = 5
+
+API_METRICS = 'metrics'
+API_UPSTREAMS = 'reverse_proxy/upstreams'
+
+# 'metrics' global option, Caddy 2.8 and newer do not collect the http metrics without it
+METRIC_REQUESTS = 'caddy_http_requests_total'
+METRIC_ERRORS = 'caddy_http_request_errors_total'
+METRIC_IN_FLIGHT = 'caddy_http_requests_in_flight'
+METRIC_RESPONSES = 'caddy_http_request_duration_seconds_count'
+METRIC_UPSTREAM_HEALTHY = 'caddy_reverse_proxy_upstreams_healthy'
+METRIC_RELOAD_SUCCESSFUL = 'caddy_config_last_reload_successful'
+METRIC_RELOAD_TIMESTAMP = 'caddy_config_last_reload_success_timestamp_seconds'
+
+CODE_CLASSES = ('1xx', '2xx', '3xx', '4xx', '5xx', 'other')
+
+# the admin endpoint over a unix socket only allows these Host headers
+UNIX_SOCKET_HOST = '127.0.0.1'
+
+ORDER = [
+    'config_reloads',
+    'config_reload_status',
+    'upstreams_health',
+    'upstreams_requests',
+    'upstreams_fails',
+]
+
+CHARTS = {
+    'config_reloads': {
+        'options': [None, 'Config Reloads', 'reloads', 'config', 'caddy.config_reloads', 'line'],
+        'lines': [
+            ['config_reloads', 'reloads'],
+        ]
+    },
+    'config_reload_status': {
+        'options': [None, 'Last Config Reload Status', 'status', 'config', 'caddy.config_reload_status', 'line'],
+        'lines': [
+            ['config_reload_successful', 'successful'],
+            ['config_reload_failed', 'failed'],
+        ]
+    },
+    'upstreams_health': {
+        'options': [None, 'Reverse Proxy Upstreams Health', 'status', 'upstreams', 'caddy.upstreams_health',
+                    'line'],
+        'lines': []
+    },
+    'upstreams_requests': {
+        'options': [None, 'Reverse Proxy Upstreams Active Requests', 'requests', 'upstreams',
+                    'caddy.upstreams_requests', 'stacked'],
+        'lines': []
+    },
+    'upstreams_fails': {
+        'options': [None, 'Reverse Proxy Upstreams Recent Failures', 'failures', 'upstreams',
+                    'caddy.upstreams_fails', 'stacked'],
+        'lines': []
+    },
+}
+
+
+def server_charts(server_id, server):
+    order = [
+        'server_{0}_requests'.format(server_id),
+        'server_{0}_responses'.format(server_id),
+        'server_{0}_in_flight'.format(server_id),
+    ]
+    family = 'server ' + server
+    charts = {
+        order[0]: {
+            'options': [None, 'Requests', 'requests/s', family, 'caddy.server_requests', 'line'],
+            'lines': [
+                ['server_{0}_requests'.format(server_id), 'requests', 'incremental'],
+                ['server_{0}_errors'.format(server_id), 'errors', 'incremental'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Responses By Status Code Class', 'responses/s', family, 'caddy.server_responses',
+                        'stacked'],
+            'lines': [['server_{0}_{1}'.format(server_id, c), c, 'incremental'] for c in CODE_CLASSES]
+        },
+        order[2]: {
+            'options': [None, 'Requests In Flight', 'requests', family, 'caddy.server_in_flight', 'line'],
+            'lines': [
+                ['server_{0}_in_flight'.format(server_id), 'in_flight'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def code_class(code):
+    if len(code) == 3 and code[0] in '12345':
+        return code[0] + 'xx'
+    return 'other'
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:2019').rstrip('/')
+        # set with 'admin unix//run/caddy/admin.sock', only the paths of the url are used then
+        self.unix_socket = self.configuration.get('unix_socket')
+        self.collected_dims = set()
+        # server id -> <list> of its charts
+        self.collected_servers = dict()
+        self.reload_timestamp = None
+
+    def _get_raw_data(self, url=None, manager=None, **kwargs):
+        if not self.unix_socket:
+            return UrlService._get_raw_data(self, url, manager, **kwargs)
+        return self.get_unix_socket(url or self.url)
+
+    def get_unix_socket(self, url):
+        path = '/' + url.split('://', 1)[-1].partition('/')[2]
+        # HTTP/1.0, the response is not chunked and the connection is closed after it
+        request = 'GET {0} HTTP/1.0\r\nHost: {1}\r\n\r\n'.format(path, UNIX_SOCKET_HOST)
+        sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+        try:
+            sock.settimeout(self.update_every)
+            sock.connect(self.unix_socket)
+            sock.sendall(request.encode())
+            response = b''
+            while True:
+                chunk = sock.recv(65536)
+                if not chunk:
+                    break
+                response += chunk
+        except socket.error as error:
+            self.error('unix socket "{0}": {1}'.format(self.unix_socket, error))
+            return None
+        finally:
+            sock.close()
+
+        head, _, body = response.partition(b'\r\n\r\n')
+        status = head.split(b'\r\n', 1)[0].split()
+        if len(status) < 2 or status[1] != b'200':
+            self.debug('unix socket "{0}", {1}: {2}'.format(self.unix_socket, path, head[:64]))
+            return None
+        return body.decode(errors='ignore')
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_METRICS))
+        if not raw:
+            return None
+        samples = parse(raw)
+
+        data = dict()
+        data.update(self.config_data(samples))
+        data.update(self.servers_data(samples))
+
+        healthy = sum_by(samples, METRIC_UPSTREAM_HEALTHY, 'upstream')
+        for upstream, value in healthy.items():
+            if upstream:
+                data[self.upstream_dim('upstreams_health', upstream)] = int(value)
+
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_UPSTREAMS))
+        try:
+            upstreams = json.loads(raw) if raw else list()
+        except ValueError:
+            upstreams = list()
+        for upstream in upstreams:
+            address = upstream.get('address')
+            if not address:
+                continue
+            data[self.upstream_dim('upstreams_requests', address)] = upstream.get('num_requests', 0)
+            data[self.upstream_dim('upstreams_fails', address)] = upstream.get('fails', 0)
+
+        return data
+
+    def config_data(self, samples):
+        successful = sum_by(samples, METRIC_RELOAD_SUCCESSFUL).get(None)
+        timestamp = sum_by(samples, METRIC_RELOAD_TIMESTAMP).get(None)
+        if successful is None:
+            return dict()
+
+        # a reload is a change of the time of the last successful one, failed reloads change the status only
+        previous, self.reload_timestamp = self.reload_timestamp, timestamp
+        return {
+            'config_reloads': int(previous is not None and timestamp != previous),
+            'config_reload_successful': int(successful == 1),
+            'config_reload_failed': int(successful != 1),
+        }
+
+    def servers_data(self, samples):
+        data = dict()
+        requests = sum_by(samples, METRIC_REQUESTS, 'server')
+        errors = sum_by(samples, METRIC_ERRORS, 'server')
+        in_flight = sum_by(samples, METRIC_IN_FLIGHT, 'server')
+        for server, value in requests.items():
+            if not server:
+                continue
+            server_id = clean_name(server)
+            if server_id not in self.collected_servers:
+                self.add_server_charts(server_id, server)
+            prefix = 'server_{0}_'.format(server_id)
+            data[prefix + 'requests'] = int(value)
+            data[prefix + 'errors'] = int(errors.get(server, 0))
+            data[prefix + 'in_flight'] = int(in_flight.get(server, 0))
+            for cls in CODE_CLASSES:
+                data[prefix + cls] = 0
+
+        for name, labels, value in samples:
+            if name != METRIC_RESPONSES or labels.get('server') not in requests:
+                continue
+            key = 'server_{0}_{1}'.format(clean_name(labels['server']), code_class(labels.get('code', '')))
+            data[key] += int(value)
+
+        # servers removed from the config
+        for server_id in set(self.collected_servers) - set(clean_name(s) for s in requests):
+            self.remove_server_charts(server_id)
+        return data
+
+    def upstream_dim(self, chart, upstream):
+        dim_id = '{0}_{1}'.format(chart, clean_name(upstream))
+        if dim_id not in self.collected_dims:
+            self.collected_dims.add(dim_id)
+            self.add_dimension(chart, [dim_id, upstream])
+        return dim_id
+
+    def add_server_charts(self, server_id, server):
+        order, charts = server_charts(server_id, server)
+        self.collected_servers[server_id] = order
+        self.add_charts(order, charts)
+
+    def remove_server_charts(self, server_id):
+        self.remove_charts(self.collected_servers.pop(server_id))
@@ -0,0 +1,394 @@
+# -*- coding: utf-8 -*-
+# Description: celery netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import socket
+import threading
+import time
+from base64 import b64decode
+from collections import defaultdict
+from copy import deepcopy
+
+try:
+    from collections import OrderedDict
+except ImportError:
+    from third_party.ordereddict import OrderedDict
+
+from bases.FrameworkServices.SimpleService import SimpleService
+from bases.collection import clean_name
+from bases.resp import ReplyError, encode_command, execute, parse_replies
+
+update_every = 5
+
+DEFAULT_HOST = '127.0.0.1'
+DEFAULT_PORT = 6379
+DEFAULT_DB = 0
+DEFAULT_TIMEOUT = 2
+DEFAULT_QUEUES = ['celery']
+
+# kombu keeps the queues bound to an exchange in the '_kombu.binding.<exchange>' set, the default exchange of a
+# queue has the name of the queue
+BINDING_PREFIX = '_kombu.binding.'
+EVENTS_EXCHANGE = 'celeryev'
+
+# kombu publishes the events of the 'celeryev' exchange to '/<db>.celeryev/<routing key>'
+EVENTS_PATTERN = '*celeryev/*'
+
+# the redis transport emulates priorities with one list per priority step: '<queue>\x06\x16<step>'
+PRIORITY_SEPARATOR = '\x06\x16'
+PRIORITY_STEPS = ('3', '6', '9')
+
+# tasks whose queue is unknown, 'task-sent' events (task_send_sent_event) are needed to find it
+UNKNOWN_QUEUE = 'unknown'
+
+# number of sent/received tasks waiting to start that are kept to compute their latency
+PENDING_LIMIT = 100000
+
+RECONNECT_DELAY = 5
+
+TASK_EVENTS = (
+    'sent',
+    'received',
+    'started',
+    'succeeded',
+    'failed',
+    'retried',
+    'rejected',
+)
+
+ORDER = [
+    'workers',
+    'workers_active',
+    'workers_processed',
+]
+
+CHARTS = {
+    'workers': {
+        'options': [None, 'Workers Online', 'workers', 'workers', 'celery.workers', 'line'],
+        'lines': [
+            ['workers_online', 'online'],
+        ]
+    },
+    'workers_active': {
+        'options': [None, 'Active Tasks', 'tasks', 'workers', 'celery.workers_active', 'stacked'],
+        'lines': []
+    },
+    'workers_processed': {
+        'options': [None, 'Processed Tasks', 'tasks/s', 'workers', 'celery.workers_processed', 'stacked'],
+        'lines': []
+    },
+}
+
+
+def queue_charts(queue_id, queue):
+    order = [
+        'queue_{0}_length'.format(queue_id),
+        'queue_{0}_tasks'.format(queue_id),
+        'queue_{0}_latency'.format(queue_id),
+    ]
+    family = 'queue ' + queue
+    charts = {
+        order[0]: {
+            'options': [None, 'Queue Length', 'messages', family, 'celery.queue_length', 'line'],
+            'lines': [
+                ['queue_{0}_length'.format(queue_id), 'messages'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Tasks', 'tasks/s', family, 'celery.queue_tasks', 'line'],
+            'lines': [
+                ['queue_{0}_sent'.format(queue_id), 'sent', 'incremental'],
+                ['queue_{0}_succeeded'.format(queue_id), 'succeeded', 'incremental'],
+                ['queue_{0}_failed'.format(queue_id), 'failed', 'incremental'],
+                ['queue_{0}_retried'.format(queue_id), 'retried', 'incremental'],
+                ['queue_{0}_rejected'.format(queue_id), 'rejected', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Tasks Average Latency', 'milliseconds', family, 'celery.queue_latency', 'line'],
+            'lines': [
+                ['queue_{0}_wait'.format(queue_id), 'wait', 'absolute', 1, 1000],
+                ['queue_{0}_runtime'.format(queue_id), 'runtime', 'absolute', 1, 1000],
+            ]
+        },
+    }
+    return order, charts
+
+
+def decode_events(payload):
+    """
+    Decode a kombu message of the 'celeryev' exchange, task events are sent in batches.
+    :return: <list> of event dicts
+    """
+    message = json.loads(payload)
+    body = message['body']
+    if message.get('properties', dict()).get('body_encoding') == 'base64':
+        body = b64decode(body).decode('utf-8')
+    if message.get('content-type') != 'application/json':
+        return list()
+
+    events = json.loads(body)
+    return events if isinstance(events, list) else [events]
+
+
+class EventsListener(threading.Thread):
+    def __init__(self, service):
+        threading.Thread.__init__(self)
+        self.daemon = True
+        self.service = service
+        self.lock = threading.RLock()
+        self.exit = False
+        self.workers = dict()
+        self.queues = defaultdict(lambda: defaultdict(int))
+        self.pending = OrderedDict()
+
+    def run(self):
+        while not self.exit:
+            try:
+                self.listen()
+            except (socket.error, ValueError) as error:
+                self.service.error('events listener: {0}'.format(error))
+            time.sleep(RECONNECT_DELAY)
+
+    def listen(self):
+        sock = self.service.connect()
+        try:
+            sock.sendall(encode_command('PSUBSCRIBE', EVENTS_PATTERN))
+            buf = b''
+            while not self.exit:
+                try:
+                    chunk = sock.recv(65536)
+                except socket.timeout:
+                    continue
+                if not chunk:
+                    raise socket.error('connection closed')
+                replies, buf = parse_replies(buf + chunk)
+                for reply in replies:
+                    if isinstance(reply, ReplyError):
+                        raise ValueError(reply)
+                    # ['pmessage', <pattern>, <channel>, <payload>]
+                    if isinstance(reply, list) and len(reply) == 4 and reply[0] == 'pmessage':
+                        self.process(reply[3])
+        finally:
+            sock.close()
+
+    def process(self, payload):
+        try:
+            events = decode_events(payload)
+        except (ValueError, KeyError, TypeError, UnicodeDecodeError):
+            return
+
+        with self.lock:
+            for event in events:
+                kind, _, name = event.get('type', '').partition('-')
+                if kind == 'worker':
+                    self.process_worker_event(name, event)
+                elif kind == 'task' and name in TASK_EVENTS:
+                    self.process_task_event(name, event)
+
+    def process_worker_event(self, name, event):
+        hostname = event.get('hostname')
+        if not hostname:
+            return
+        if name == 'offline':
+            self.workers.pop(hostname, None)
+            return
+        self.workers[hostname] = {
+            'seen': time.time(),
+            'freq': event.get('freq', 2.0),
+            'active': event.get('active', 0),
+            'processed': event.get('processed', 0),
+        }
+
+    def process_task_event(self, name, event):
+        uuid = event.get('uuid')
+        if name == 'sent':
+            self.pending[uuid] = [event.get('queue') or UNKNOWN_QUEUE, event.get('timestamp')]
+        elif name == 'received' and uuid not in self.pending:
+            self.pending[uuid] = [UNKNOWN_QUEUE, event.get('timestamp')]
+        while len(self.pending) > PENDING_LIMIT:
+            self.pending.popitem(last=False)
+
+        queue, since = self.pending.get(uuid) or (UNKNOWN_QUEUE, None)
+        stats = self.queues[queue]
+        stats[name] += 1
+
+        if name == 'started':
+            if since and event.get('timestamp'):
+                stats['wait_sum'] += max(event['timestamp'] - since, 0)
+                stats['wait_count'] += 1
+        elif name in ('succeeded', 'failed', 'rejected'):
+            self.pending.pop(uuid, None)
+            if name == 'succeeded' and event.get('runtime') is not None:
+                stats['runtime_sum'] += event['runtime']
+                stats['runtime_count'] += 1
+
+    def snapshot(self):
+        with self.lock:
+            workers = deepcopy(self.workers)
+            queues = dict((q, dict(s)) for q, s in self.queues.items())
+        return workers, queues
+
+    def shutdown(self):
+        self.exit = True
+
+
+class Service(SimpleService):
+    def __init__(self, configuration=None, name=None):
+        SimpleService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.host = self.configuration.get('host', DEFAULT_HOST)
+        self.port = self.configuration.get('port', DEFAULT_PORT)
+        self.db = self.configuration.get('db', DEFAULT_DB)
+        self.password = self.configuration.get('pass')
+        self.timeout = self.configuration.get('timeout', DEFAULT_TIMEOUT)
+        self.queues = self.configuration.get('queues') or list(DEFAULT_QUEUES)
+        self.listener = EventsListener(self)
+        self.collected_queues = set()
+        self.collected_workers = set()
+        self.latency = dict()
+
+    def check(self):
+        # LLEN of a missing key is 0, any redis would pass without looking for celery first
+        try:
+            found = self.find_celery()
+        except (socket.error, ValueError) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return False
+        if not found:
+            self.error('no celery queue or kombu binding in {0}:{1} db {2}'.format(self.host, self.port, self.db))
+            return False
+
+        data = self.get_data()
+        if not data:
+            return False
+        if self.listener.ident is None:
+            self.listener.start()
+        return True
+
+    def connect(self):
+        sock = socket.create_connection((self.host, self.port), timeout=self.timeout)
+        if not self.password:
+            return sock
+        try:
+            reply = execute(sock, ('AUTH', self.password))[0]
+        except (socket.error, ValueError):
+            sock.close()
+            raise
+        if isinstance(reply, ReplyError):
+            sock.close()
+            raise ValueError('AUTH failed: {0}'.format(reply))
+        return sock
+
+    def find_celery(self):
+        commands = [('SELECT', self.db), ('EXISTS', BINDING_PREFIX + EVENTS_EXCHANGE)]
+        for queue in self.queues:
+            commands.append(('EXISTS', BINDING_PREFIX + queue))
+            commands.append(('TYPE', queue))
+
+        sock = self.connect()
+        try:
+            replies = execute(sock, *commands)
+        finally:
+            sock.close()
+
+        if isinstance(replies[0], ReplyError):
+            raise ValueError('SELECT {0}: {1}'.format(self.db, replies[0]))
+
+        # a drained queue list is deleted, its binding stays
+        return any(r == 1 or r == 'list' for r in replies[1:])
+
+    def queue_lengths(self):
+        commands = [('SELECT', self.db)]
+        for queue in self.queues:
+            commands.append(('LLEN', queue))
+            commands.extend(('LLEN', queue + PRIORITY_SEPARATOR + step) for step in PRIORITY_STEPS)
+
+        sock = self.connect()
+        try:
+            replies = execute(sock, *commands)
+        finally:
+            sock.close()
+
+        if isinstance(replies[0], ReplyError):
+            raise ValueError('SELECT {0}: {1}'.format(self.db, replies[0]))
+
+        lengths = dict()
+        step = len(PRIORITY_STEPS) + 1
+        for idx, queue in enumerate(self.queues):
+            values = replies[1 + idx * step:1 + (idx + 1) * step]
+            lengths[queue] = sum(v for v in values if isinstance(v, int))
+        return lengths
+
+    def _get_data(self):
+        try:
+            lengths = self.queue_lengths()
+        except (socket.error, ValueError) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return None
+
+        workers, queues = self.listener.snapshot()
+        now = time.time()
+
+        data = {
+            'workers_online': 0,
+        }
+        for hostname, worker in workers.items():
+            # celery considers a worker offline after missing heartbeats for two intervals
+            if now - worker['seen'] > worker['freq'] * 2 + 1:
+                continue
+            data['workers_online'] += 1
+
+            worker_id = clean_name(hostname)
+            if worker_id not in self.collected_workers:
+                self.collected_workers.add(worker_id)
+                self.add_worker_dimensions(worker_id, hostname)
+            data['worker_{0}_active'.format(worker_id)] = worker['active']
+            data['worker_{0}_processed'.format(worker_id)] = worker['processed']
+
+        for queue in set(self.queues) | set(queues):
+            queue_id = clean_name(queue)
+            if queue_id not in self.collected_queues:
+                self.collected_queues.add(queue_id)
+                self.add_queue_charts(queue_id, queue)
+
+            prefix = 'queue_{0}_'.format(queue_id)
+            if queue in lengths:
+                data[prefix + 'length'] = lengths[queue]
+
+            stats = queues.get(queue, dict())
+            for name in ('sent', 'succeeded', 'failed', 'retried', 'rejected'):
+                data[prefix + name] = stats.get(name, 0)
+            data.update(self.latency_data(prefix, stats))
+
+        return data
+
+    def latency_data(self, prefix, stats):
+        """
+        Average latencies of the tasks completed since the last run, in microseconds
+        """
+        data = dict()
+        for metric in ('wait', 'runtime'):
+            key = prefix + metric
+            total, count = stats.get(metric + '_sum', 0), stats.get(metric + '_count', 0)
+            prev_total, prev_count = self.latency.get(key, (total, count))
+            self.latency[key] = (total, count)
+            data[key] = int((total - prev_total) / (count - prev_count) * 1e6) if count > prev_count else 0
+        return data
+
+    def add_worker_dimensions(self, worker_id, hostname):
+        dimensions = [
+            ('workers_active', ['worker_{0}_active'.format(worker_id), hostname]),
+            ('workers_processed', ['worker_{0}_processed'.format(worker_id), hostname, 'incremental']),
+        ]
+        for chart, dimension in dimensions:
+            self.add_dimension(chart, dimension)
+
+    def add_queue_charts(self, queue_id, queue):
+        order, charts = queue_charts(queue_id, queue)
+        if queue not in self.queues:
+            # the length is known only for the configured queues
+            order = order[1:]
+        self.add_charts(order, charts)
@@ -0,0 +1,161 @@
+# -*- coding: utf-8 -*-
+# Description: cilium netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse, sum_by
+
+update_every = 10
+
+METRIC_ENDPOINT_STATE = 'cilium_endpoint_state'
+METRIC_REGENERATIONS = 'cilium_endpoint_regenerations_total'
+METRIC_REGENERATION_TIME_SUM = 'cilium_endpoint_regeneration_time_stats_seconds_sum'
+METRIC_REGENERATION_TIME_COUNT = 'cilium_endpoint_regeneration_time_stats_seconds_count'
+METRIC_MAP_PRESSURE = 'cilium_bpf_map_pressure'
+METRIC_DROPS = 'cilium_drop_count_total'
+METRIC_FLOWS = 'hubble_flows_processed_total'
+METRIC_OPERATOR_IPS = 'cilium_operator_ipam_ips'
+
+# one policy map per endpoint, charted as the fullest one
+POLICY_MAP_PREFIX = 'cilium_policy'
+
+ORDER = [
+    'endpoints',
+    'regenerations',
+    'regeneration_time',
+    'policy_map_pressure',
+    'map_pressure',
+    'drops',
+    'flows',
+    'operator_ips',
+]
+
+CHARTS = {
+    'endpoints': {
+        'options': [None, 'Endpoints By State', 'endpoints', 'endpoints', 'cilium.endpoints', 'stacked'],
+        'lines': []
+    },
+    'regenerations': {
+        'options': [None, 'Endpoint Regenerations', 'regenerations/s', 'endpoints', 'cilium.regenerations',
+                    'stacked'],
+        'lines': [
+            ['regenerations_success', 'success', 'incremental'],
+            ['regenerations_fail', 'fail', 'incremental'],
+        ]
+    },
+    'regeneration_time': {
+        'options': [None, 'Average Endpoint Regeneration Time', 'milliseconds', 'endpoints',
+                    'cilium.regeneration_time', 'line'],
+        'lines': [
+            ['regeneration_time', 'time', 'absolute', 1, 1000],
+        ]
+    },
+    'policy_map_pressure': {
+        'options': [None, 'Policy Map Pressure', 'percentage', 'bpf maps', 'cilium.policy_map_pressure', 'line'],
+        'lines': [
+            ['policy_map_pressure', 'max', 'absolute', 1, 100],
+        ]
+    },
+    'map_pressure': {
+        'options': [None, 'BPF Map Pressure', 'percentage', 'bpf maps', 'cilium.map_pressure', 'line'],
+        'lines': []
+    },
+    'drops': {
+        'options': [None, 'Dropped Packets By Reason', 'packets/s', 'datapath', 'cilium.drops', 'stacked'],
+        'lines': []
+    },
+    'flows': {
+        'options': [None, 'Hubble Flows By Verdict', 'flows/s', 'hubble', 'cilium.flows', 'stacked'],
+        'lines': []
+    },
+    'operator_ips': {
+        'options': [None, 'Operator IPAM Addresses', 'addresses', 'operator', 'cilium.operator_ips', 'line'],
+        'lines': []
+    },
+}
+
+# (chart, metric, label, algorithm, divisor), one dimension per label value.
+# The map pressure is a 0-1 ratio, collected as a percentage * 100
+DYNAMIC_CHARTS = (
+    ('endpoints', METRIC_ENDPOINT_STATE, 'endpoint_state', 'absolute', 1),
+    ('map_pressure', METRIC_MAP_PRESSURE, 'map_name', 'absolute', 100),
+    ('drops', METRIC_DROPS, 'reason', 'incremental', 1),
+    ('flows', METRIC_FLOWS, 'verdict', 'incremental', 1),
+    ('operator_ips', METRIC_OPERATOR_IPS, 'type', 'absolute', 1),
+)
+
+
+def is_policy_map(sample):
+    name, labels, _ = sample
+    return name == METRIC_MAP_PRESSURE and labels.get('map_name', '').startswith(POLICY_MAP_PREFIX)
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:9962/metrics')
+        # the hubble metrics server has its own port, the operator usually runs on another node
+        self.hubble_url = self.configuration.get('hubble_url', 'http://127.0.0.1:9965/metrics')
+        self.operator_url = self.configuration.get('operator_url')
+        if not self.hubble_url:
+            self.order.remove('flows')
+        if not self.operator_url:
+            self.order.remove('operator_ips')
+        # chart -> <set> of its dimensions
+        self.collected_dims = dict()
+        # (sum, count) of the regeneration time at the previous run
+        self.regeneration_time = None
+
+    def get_samples(self, url):
+        raw = self._get_raw_data(url)
+        if not raw:
+            return None
+        return parse(raw)
+
+    def _get_data(self):
+        samples = self.get_samples(self.url)
+        if not samples:
+            return None
+
+        for url in (self.hubble_url, self.operator_url):
+            if url:
+                samples.extend(self.get_samples(url) or list())
+
+        data = dict()
+        regenerations = sum_by(samples, METRIC_REGENERATIONS, 'outcome')
+        for outcome in ('success', 'fail'):
+            data['regenerations_' + outcome] = int(regenerations.get(outcome, 0))
+        data['regeneration_time'] = self.regeneration_time_data(samples)
+
+        policy_maps = [s[2] for s in samples if is_policy_map(s)]
+        data['policy_map_pressure'] = int(max(policy_maps or [0]) * 10000)
+        samples = [s for s in samples if not is_policy_map(s)]
+
+        for chart, metric, label, algorithm, divisor in DYNAMIC_CHARTS:
+            for value, total in sum_by(samples, metric, label).items():
+                if not value:
+                    continue
+                dim_id = '{0}_{1}'.format(chart, clean_name(value))
+                if dim_id not in self.collected_dims.setdefault(chart, set()):
+                    self.collected_dims[chart].add(dim_id)
+                    self.add_dimension(chart, [dim_id, value.lower(), algorithm, 1, divisor])
+                data[dim_id] = int(total * 100 * divisor) if divisor != 1 else int(total)
+
+        return data
+
+    def regeneration_time_data(self, samples):
+        # the average of the regenerations since the previous run, from the histogram of all the scopes
+        total = [s for s in samples if s[1].get('scope') == 'total']
+        current = (
+            sum_by(total, METRIC_REGENERATION_TIME_SUM).get(None, 0),
+            sum_by(total, METRIC_REGENERATION_TIME_COUNT).get(None, 0),
+        )
+        previous, self.regeneration_time = self.regeneration_time, current
+        if not previous or current[1] <= previous[1]:
+            return 0
+        return int((current[0] - previous[0]) / (current[1] - previous[1]) * 1000000)
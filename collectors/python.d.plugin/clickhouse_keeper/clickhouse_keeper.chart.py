@@ -0,0 +1,180 @@
+# -*- coding: utf-8 -*-
+# Description: clickhouse keeper netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import urllib3
+
+from bases.FrameworkServices.SocketService import SocketService
+
+update_every = 5
+
+# four letter word command, it needs to be in 'four_letter_word_white_list' of the keeper configuration
+REQUEST = 'mntr'
+
+ROLES = ('leader', 'follower', 'observer', 'standalone')
+
+ORDER = [
+    'outstanding_requests',
+    'latency',
+    'packets',
+    'connections',
+    'znodes',
+    'ephemerals',
+    'watches',
+    'data_size',
+    'file_descriptors',
+    'role',
+    'followers',
+    'ready',
+]
+
+CHARTS = {
+    'outstanding_requests': {
+        'options': [None, 'Outstanding Requests', 'requests', 'requests', 'clickhouse_keeper.outstanding_requests',
+                    'line'],
+        'lines': [
+            ['zk_outstanding_requests', 'outstanding'],
+        ]
+    },
+    'latency': {
+        'options': [None, 'Requests Latency', 'milliseconds', 'requests', 'clickhouse_keeper.latency', 'line'],
+        'lines': [
+            ['zk_min_latency', 'min'],
+            ['zk_avg_latency', 'avg'],
+            ['zk_max_latency', 'max'],
+        ]
+    },
+    'packets': {
+        'options': [None, 'Packets', 'packets/s', 'network', 'clickhouse_keeper.packets', 'area'],
+        'lines': [
+            ['zk_packets_received', 'received', 'incremental'],
+            ['zk_packets_sent', 'sent', 'incremental', -1, 1],
+        ]
+    },
+    'connections': {
+        'options': [None, 'Alive Connections', 'connections', 'network', 'clickhouse_keeper.connections', 'line'],
+        'lines': [
+            ['zk_num_alive_connections', 'alive'],
+        ]
+    },
+    'znodes': {
+        'options': [None, 'Znodes', 'znodes', 'data', 'clickhouse_keeper.znodes', 'line'],
+        'lines': [
+            ['zk_znode_count', 'znodes'],
+        ]
+    },
+    'ephemerals': {
+        'options': [None, 'Ephemeral Nodes', 'znodes', 'data', 'clickhouse_keeper.ephemerals', 'line'],
+        'lines': [
+            ['zk_ephemerals_count', 'ephemerals'],
+        ]
+    },
+    'watches': {
+        'options': [None, 'Watches', 'watches', 'data', 'clickhouse_keeper.watches', 'line'],
+        'lines': [
+            ['zk_watch_count', 'watches'],
+        ]
+    },
+    'data_size': {
+        'options': [None, 'Approximate Data Size', 'KiB', 'data', 'clickhouse_keeper.data_size', 'area'],
+        'lines': [
+            ['zk_approximate_data_size', 'size', 'absolute', 1, 1 << 10],
+        ]
+    },
+    'file_descriptors': {
+        'options': [None, 'File Descriptors', 'descriptors', 'server', 'clickhouse_keeper.file_descriptors',
+                    'line'],
+        'lines': [
+            ['zk_open_file_descriptor_count', 'open'],
+            ['zk_max_file_descriptor_count', 'max'],
+        ]
+    },
+    'role': {
+        'options': [None, 'Server Role', 'role', 'server', 'clickhouse_keeper.role', 'line'],
+        'lines': [['role_' + r, r] for r in ROLES]
+    },
+    'followers': {
+        'options': [None, 'Followers Of The Leader', 'followers', 'server', 'clickhouse_keeper.followers', 'line'],
+        'lines': [
+            ['zk_followers', 'followers'],
+            ['zk_synced_followers', 'synced'],
+        ]
+    },
+    'ready': {
+        'options': [None, 'Readiness', 'status', 'server', 'clickhouse_keeper.ready', 'line'],
+        'lines': [
+            ['ready', 'ready'],
+            ['not_ready', 'not_ready'],
+        ]
+    },
+}
+
+
+class Service(SocketService):
+    def __init__(self, configuration=None, name=None):
+        SocketService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = CHARTS
+        self.request = REQUEST
+        self.host = 'localhost'
+        self.port = 9181
+        # the keeper closes the connection after every command
+        self._keep_alive = False
+        # '/ready' of the 'http_control' endpoint, ClickHouse 23.x and newer
+        self.ready_url = self.configuration.get('ready_url')
+        self.http = None
+
+    def check(self):
+        if not self.ready_url:
+            self.order.remove('ready')
+        else:
+            self.http = urllib3.PoolManager(timeout=self.update_every, retries=False)
+        return SocketService.check(self)
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        stats = dict()
+        for line in raw.splitlines():
+            parts = line.split('\t')
+            if len(parts) == 2 and parts[0].startswith('zk_'):
+                stats[parts[0]] = parts[1].strip()
+
+        if 'zk_server_state' not in stats:
+            # "mntr is not executed because it is not in the whitelist."
+            self.error('unexpected response to "{0}": {1}'.format(REQUEST, raw.strip()[:128]))
+            return None
+
+        data = dict()
+        for key, value in stats.items():
+            try:
+                data[key] = int(float(value))
+            except ValueError:
+                continue
+
+        state = stats['zk_server_state']
+        for role in ROLES:
+            data['role_' + role] = int(state == role)
+
+        if self.ready_url:
+            ready = self.is_ready()
+            data['ready'] = int(ready)
+            data['not_ready'] = int(not ready)
+
+        return data
+
+    def is_ready(self):
+        # 200 when the keeper has a leader and serves requests, 503 otherwise
+        try:
+            response = self.http.request('GET', self.ready_url)
+        except urllib3.exceptions.HTTPError as error:
+            self.debug('{0}: {1}'.format(self.ready_url, error))
+            return False
+        return response.status == 200
+
+    @staticmethod
+    def _check_raw_data(data):
+        # read until the keeper closes the connection
+        return False
@@ -0,0 +1,204 @@
+# -*- coding: utf-8 -*-
+# Description: cri netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import os
+from copy import deepcopy
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import find_binary
+
+update_every = 10
+
+CRICTL = 'crictl'
+SUDO = 'sudo'
+
+POD_STATES = (
+    ('SANDBOX_READY', 'ready'),
+    ('SANDBOX_NOTREADY', 'notready'),
+)
+
+CONTAINER_STATES = (
+    ('CONTAINER_RUNNING', 'running'),
+    ('CONTAINER_CREATED', 'created'),
+    ('CONTAINER_EXITED', 'exited'),
+    ('CONTAINER_UNKNOWN', 'unknown'),
+)
+
+LABEL_NAMESPACE = 'io.kubernetes.pod.namespace'
+LABEL_POD = 'io.kubernetes.pod.name'
+LABEL_CONTAINER = 'io.kubernetes.container.name'
+
+ORDER = [
+    'pods',
+    'containers',
+]
+
+CHARTS = {
+    'pods': {
+        'options': [None, 'Pod Sandboxes', 'pods', 'overview', 'cri.pods', 'stacked'],
+        'lines': [['pods_' + s, s] for _, s in POD_STATES]
+    },
+    'containers': {
+        'options': [None, 'Containers', 'containers', 'overview', 'cri.containers', 'stacked'],
+        'lines': [['containers_' + s, s] for _, s in CONTAINER_STATES]
+    },
+}
+
+
+def container_charts(container_id, container):
+    order = [
+        'container_{0}_cpu'.format(container_id),
+        'container_{0}_memory'.format(container_id),
+        'container_{0}_writable_layer'.format(container_id),
+    ]
+    family = container
+    charts = {
+        order[0]: {
+            'options': [None, 'CPU Usage', 'percentage', family, 'cri.container_cpu', 'line'],
+            'lines': [
+                ['container_{0}_cpu'.format(container_id), 'used', 'incremental', 1, 10000000],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Memory Working Set', 'MiB', family, 'cri.container_memory', 'area'],
+            'lines': [
+                ['container_{0}_memory'.format(container_id), 'working_set', 'absolute', 1, 1 << 20],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Writable Layer Usage', 'MiB', family, 'cri.container_writable_layer', 'area'],
+            'lines': [
+                ['container_{0}_writable_layer'.format(container_id), 'used', 'absolute', 1, 1 << 20],
+            ]
+        },
+    }
+    return order, charts
+
+
+def value_of(stats, *path):
+    # the CRI uint64 values are strings in the JSON output of crictl
+    for key in path:
+        stats = (stats or dict()).get(key)
+    try:
+        return int(stats)
+    except (TypeError, ValueError):
+        return None
+
+
+def container_name(attributes):
+    labels = attributes.get('labels') or dict()
+    name = labels.get(LABEL_CONTAINER) or (attributes.get('metadata') or dict()).get('name', '')
+    if LABEL_POD not in labels:
+        return name
+    return '{0}/{1}/{2}'.format(labels.get(LABEL_NAMESPACE, ''), labels[LABEL_POD], name)
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.crictl = self.configuration.get('crictl_path')
+        self.use_sudo = self.configuration.get('use_sudo', True)
+        self.runtime_endpoint = self.configuration.get('runtime_endpoint')
+        self.do_containers = self.configuration.get('container_charts', True)
+        self.cmd = list()
+        # container id -> <list> of its charts
+        self.collected_containers = dict()
+
+    def check(self):
+        self.crictl = self.crictl or find_binary(CRICTL)
+        if not self.crictl:
+            self.error('can\'t locate "{0}" binary'.format(CRICTL))
+            return False
+
+        if self.use_sudo:
+            sudo = find_binary(SUDO)
+            if not sudo:
+                self.error('can\'t locate "{0}" binary'.format(SUDO))
+                return False
+
+            allowed = self._get_raw_data(command=[sudo, '-n', '-l', self.crictl])
+            if not allowed or allowed[0].strip() != os.path.realpath(self.crictl):
+                self.error('not allowed to run sudo for command {0}'.format(self.crictl))
+                return False
+
+            self.cmd = [sudo, '-n']
+        self.cmd.append(self.crictl)
+        if self.runtime_endpoint:
+            self.cmd.extend(['--runtime-endpoint', self.runtime_endpoint])
+
+        return bool(self._get_data())
+
+    def crictl_json(self, args):
+        raw = self._get_raw_data(command=self.cmd + args)
+        if not raw:
+            return None
+        try:
+            return json.loads(''.join(raw))
+        except ValueError as error:
+            self.error('{0}: {1}'.format(' '.join(args), error))
+            return None
+
+    def _get_data(self):
+        pods = self.crictl_json(['pods', '-o', 'json'])
+        if pods is None:
+            return None
+
+        data = dict(('pods_' + s, 0) for _, s in POD_STATES)
+        for pod in pods.get('items') or list():
+            for state, key in POD_STATES:
+                if pod.get('state') == state:
+                    data['pods_' + key] += 1
+
+        containers = self.crictl_json(['ps', '-a', '-o', 'json'])
+        if containers is not None:
+            data.update(('containers_' + s, 0) for _, s in CONTAINER_STATES)
+            for container in containers.get('containers') or list():
+                for state, key in CONTAINER_STATES:
+                    if container.get('state') == state:
+                        data['containers_' + key] += 1
+
+        if self.do_containers:
+            stats = self.crictl_json(['stats', '-o', 'json'])
+            if stats is not None:
+                data.update(self.containers_data(stats.get('stats') or list()))
+
+        return data
+
+    def containers_data(self, stats):
+        data = dict()
+        seen = set()
+        for container in stats:
+            attributes = container.get('attributes') or dict()
+            if not attributes.get('id'):
+                continue
+            container_id = attributes['id'][:12]
+            seen.add(container_id)
+            if container_id not in self.collected_containers:
+                self.add_container_charts(container_id, container_name(attributes))
+
+            metrics = (
+                ('cpu', ('cpu', 'usageCoreNanoSeconds', 'value')),
+                ('memory', ('memory', 'workingSetBytes', 'value')),
+                ('writable_layer', ('writableLayer', 'usedBytes', 'value')),
+            )
+            for key, path in metrics:
+                value = value_of(container, *path)
+                if value is not None:
+                    data['container_{0}_{1}'.format(container_id, key)] = value
+
+        # removed containers
+        for container_id in set(self.collected_containers) - seen:
+            self.remove_container_charts(container_id)
+        return data
+
+    def add_container_charts(self, container_id, container):
+        order, charts = container_charts(container_id, container)
+        self.collected_containers[container_id] = order
+        self.add_charts(order, charts)
+
+    def remove_container_charts(self, container_id):
+        self.remove_charts(self.collected_containers.pop(container_id))
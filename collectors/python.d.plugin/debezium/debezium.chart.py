@@ -0,0 +1,199 @@
+# -*- coding: utf-8 -*-
+# Description: debezium netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+from json import loads
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+# every connector registers one mbean per context (snapshot, streaming),
+# e.g. 'debezium.postgres:type=connector-metrics,context=streaming,server=inventory'
+API_CONNECTOR_METRICS = 'read/debezium.*:type=connector-metrics,*'
+
+CONTEXT_SNAPSHOT = 'snapshot'
+CONTEXT_STREAMING = 'streaming'
+
+SNAPSHOT_STATES = (
+    ('SnapshotRunning', 'running'),
+    ('SnapshotCompleted', 'completed'),
+    ('SnapshotAborted', 'aborted'),
+)
+
+ORDER = [
+    'connectors',
+]
+
+CHARTS = {
+    'connectors': {
+        'options': [None, 'Connectors', 'connectors', 'connectors', 'debezium.connectors', 'stacked'],
+        'lines': [
+            ['connectors_snapshotting', 'snapshotting'],
+            ['connectors_streaming', 'streaming'],
+            ['connectors_disconnected', 'disconnected'],
+        ]
+    },
+}
+
+
+def connector_charts(connector_id, connector):
+    order = [
+        'connector_{0}_lag'.format(connector_id),
+        'connector_{0}_events'.format(connector_id),
+        'connector_{0}_errors'.format(connector_id),
+        'connector_{0}_queue'.format(connector_id),
+        'connector_{0}_snapshot_state'.format(connector_id),
+        'connector_{0}_snapshot_tables'.format(connector_id),
+        'connector_{0}_snapshot_duration'.format(connector_id),
+    ]
+    family = connector
+    charts = {
+        order[0]: {
+            'options': [None, 'Streaming Lag Behind Source', 'milliseconds', family, 'debezium.streaming_lag',
+                        'line'],
+            'lines': [
+                ['connector_{0}_milliseconds_behind_source'.format(connector_id), 'lag'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Events', 'events/s', family, 'debezium.events', 'stacked'],
+            'lines': [
+                ['connector_{0}_snapshot_events'.format(connector_id), 'snapshot', 'incremental'],
+                ['connector_{0}_streaming_events'.format(connector_id), 'streaming', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Erroneous And Filtered Events', 'events/s', family, 'debezium.event_errors', 'line'],
+            'lines': [
+                ['connector_{0}_erroneous_events'.format(connector_id), 'erroneous', 'incremental'],
+                ['connector_{0}_filtered_events'.format(connector_id), 'filtered', 'incremental'],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Queue Usage', 'percentage', family, 'debezium.queue', 'line'],
+            'lines': [
+                ['connector_{0}_queue_used'.format(connector_id), 'used', 'absolute', 1, 100],
+            ]
+        },
+        order[4]: {
+            'options': [None, 'Snapshot State', 'state', family, 'debezium.snapshot_state', 'line'],
+            'lines': [['connector_{0}_snapshot_{1}'.format(connector_id, s), s] for _, s in SNAPSHOT_STATES]
+        },
+        order[5]: {
+            'options': [None, 'Snapshot Tables', 'tables', family, 'debezium.snapshot_tables', 'line'],
+            'lines': [
+                ['connector_{0}_snapshot_remaining_tables'.format(connector_id), 'remaining'],
+                ['connector_{0}_snapshot_total_tables'.format(connector_id), 'total'],
+            ]
+        },
+        order[6]: {
+            'options': [None, 'Snapshot Duration', 'seconds', family, 'debezium.snapshot_duration', 'line'],
+            'lines': [
+                ['connector_{0}_snapshot_duration'.format(connector_id), 'duration'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def parse_mbean(name):
+    """
+    :param name: <str> 'domain:key=value,key=value'
+    :return: <dict> of the key properties
+    """
+    _, _, properties = name.partition(':')
+    return dict(p.split('=', 1) for p in properties.split(',') if '=' in p)
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8778/jolokia').rstrip('/')
+        self.collected_connectors = set()
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_CONNECTOR_METRICS))
+        if not raw:
+            return None
+
+        try:
+            response = loads(raw)
+        except ValueError as error:
+            self.error('invalid jolokia response: {0}'.format(error))
+            return None
+
+        # jolokia answers 404 when no mbean matches, no connector is deployed (yet)
+        if response.get('status') not in (200, 404):
+            self.error('jolokia: {0}'.format(response.get('error')))
+            return None
+
+        data = {
+            'connectors_snapshotting': 0,
+            'connectors_streaming': 0,
+            'connectors_disconnected': 0,
+        }
+        if response['status'] == 404:
+            return data
+
+        for mbean, attributes in response.get('value', dict()).items():
+            properties = parse_mbean(mbean)
+            # multi task connectors (e.g. sql server, mongodb) register one mbean per task and database
+            connector = '.'.join(properties[k] for k in ('server', 'task', 'database') if k in properties)
+            if not connector:
+                continue
+            connector_id = clean_name(connector)
+            if connector_id not in self.collected_connectors:
+                self.collected_connectors.add(connector_id)
+                self.add_connector_charts(connector_id, connector)
+
+            prefix = 'connector_{0}_'.format(connector_id)
+            context = properties.get('context')
+            if context == CONTEXT_SNAPSHOT:
+                data.update(self.snapshot_data(prefix, attributes))
+                if attributes.get('SnapshotRunning'):
+                    data['connectors_snapshotting'] += 1
+            elif context == CONTEXT_STREAMING:
+                data.update(self.streaming_data(prefix, attributes))
+                if attributes.get('Connected'):
+                    data['connectors_streaming'] += 1
+                else:
+                    data['connectors_disconnected'] += 1
+
+        return data
+
+    @staticmethod
+    def snapshot_data(prefix, attributes):
+        data = dict()
+        for attribute, state in SNAPSHOT_STATES:
+            data[prefix + 'snapshot_' + state] = int(bool(attributes.get(attribute)))
+        data[prefix + 'snapshot_events'] = attributes.get('TotalNumberOfEventsSeen', 0)
+        data[prefix + 'snapshot_remaining_tables'] = attributes.get('RemainingTableCount', 0)
+        data[prefix + 'snapshot_total_tables'] = attributes.get('TotalTableCount', 0)
+        data[prefix + 'snapshot_duration'] = attributes.get('SnapshotDurationInSeconds', 0)
+        return data
+
+    @staticmethod
+    def streaming_data(prefix, attributes):
+        data = {
+            prefix + 'streaming_events': attributes.get('TotalNumberOfEventsSeen', 0),
+            prefix + 'erroneous_events': attributes.get('NumberOfErroneousEvents', 0),
+            prefix + 'filtered_events': attributes.get('NumberOfEventsFiltered', 0),
+        }
+        # -1 until the first event is processed
+        lag = attributes.get('MilliSecondsBehindSource')
+        if isinstance(lag, int) and lag >= 0:
+            data[prefix + 'milliseconds_behind_source'] = lag
+
+        total, remaining = attributes.get('QueueTotalCapacity'), attributes.get('QueueRemainingCapacity')
+        if total and remaining is not None:
+            data[prefix + 'queue_used'] = int((total - remaining) * 100 * 100 / total)
+        return data
+
+    def add_connector_charts(self, connector_id, connector):
+        order, charts = connector_charts(connector_id, connector)
+        self.add_charts(order, charts)
@@ -9,9 +9,13 @@ try:
 except ImportError:
     HAS_DOCKER = False
 
+import re
+import time
+from copy import deepcopy
 from distutils.version import StrictVersion
 
 from bases.FrameworkServices.SimpleService import SimpleService
+from bases.collection import clean_name
 
 # charts order (can be overridden if you want less charts, or different order)
 ORDER = [
@@ -46,13 +50,88 @@ CHARTS = {
 
 MIN_REQUIRED_VERSION = '3.2.0'
 
+HEALTH_STATUSES = (
+    'healthy',
+    'unhealthy',
+    'starting',
+)
+
+# 'Up 2 minutes (healthy)', 'Up 5 seconds (health: starting)'
+RE_HEALTH = re.compile(r'\((?:health: )?(healthy|unhealthy|starting)\)')
+
+# the events of the containers to inspect again, their restart count changed
+INSPECT_EVENTS = ('create', 'start', 'restart')
+
+DEFAULT_INSPECT_EVERY = 60
+
+
+def container_charts(container_id, name, has_healthcheck):
+    order = [
+        'container_{0}_restarts'.format(container_id),
+        'container_{0}_oom_kills'.format(container_id),
+    ]
+    family = 'container ' + name
+    charts = {
+        order[0]: {
+            'options': [None, 'Container Restarts', 'restarts', family, 'docker.container_restarts', 'line'],
+            'lines': [
+                ['container_{0}_restarts'.format(container_id), 'restarts'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Container OOM Kills', 'kills', family, 'docker.container_oom_kills', 'line'],
+            'lines': [
+                ['container_{0}_oom_kills'.format(container_id), 'oom_kills', 'incremental'],
+            ]
+        },
+    }
+    if not has_healthcheck:
+        return order, charts
+
+    order[:0] = [
+        'container_{0}_health'.format(container_id),
+        'container_{0}_failing_streak'.format(container_id),
+    ]
+    charts.update({
+        order[0]: {
+            'options': [None, 'Container Health Status', 'status', family, 'docker.container_health', 'line'],
+            'lines': [['container_{0}_{1}'.format(container_id, s), s] for s in HEALTH_STATUSES]
+        },
+        order[1]: {
+            'options': [None, 'Container Healthcheck Failing Streak', 'checks', family,
+                        'docker.container_failing_streak', 'line'],
+            'lines': [
+                ['container_{0}_failing_streak'.format(container_id), 'failing_streak'],
+            ]
+        },
+    })
+    return order, charts
+
+
+def parse_health(status):
+    """
+    :param status: <str> status of the containers list
+    :return: <str> health status, None for the containers without a healthcheck or not running
+    """
+    match = RE_HEALTH.search(status)
+    return match.group(1) if match else None
+
 
 class Service(SimpleService):
     def __init__(self, configuration=None, name=None):
         SimpleService.__init__(self, configuration=configuration, name=name)
-        self.order = ORDER
-        self.definitions = CHARTS
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
         self.client = None
+        self.do_containers = self.configuration.get('container_charts', True)
+        self.inspect_every = self.configuration.get('inspect_every', DEFAULT_INSPECT_EVERY)
+        self.collected_containers = dict()
+        self.oom_kills = dict()
+        self.last_events = None
+        # container id -> (restarts, failing streak, has a healthcheck) of the last inspection
+        self.inspected = dict()
+        self.to_inspect = set()
+        self.last_inspect = 0
 
     def check(self):
         if not HAS_DOCKER:
@@ -83,4 +162,93 @@ class Service(SimpleService):
         data['healthy_containers'] = len(self.client.containers.list(filters={'health': 'healthy'}, sparse=True))
         data['unhealthy_containers'] = len(self.client.containers.list(filters={'health': 'unhealthy'}, sparse=True))
 
+        if self.do_containers:
+            data.update(self.get_containers_data())
+
         return data or None
+
+    def get_containers_data(self):
+        data = dict()
+        seen = set()
+
+        self.collect_events()
+
+        # inspecting is one request per container, done for the new and the restarted containers,
+        # and for all of them every inspect_every seconds to refresh the healthcheck failing streaks
+        now = time.time()
+        inspect_all = now - self.last_inspect >= self.inspect_every
+        if inspect_all:
+            self.last_inspect = now
+
+        # the stopped containers too, oom killed containers without a restart policy exit
+        for container in self.client.api.containers(all=True):
+            name = container['Names'][0].lstrip('/')
+            container_id = clean_name(name)
+            health = parse_health(container.get('Status', ''))
+            seen.add(container_id)
+
+            if inspect_all or container_id in self.to_inspect or container_id not in self.inspected:
+                self.inspect(container_id, container['Id'])
+
+            restarts, failing_streak, has_healthcheck = self.inspected.get(container_id, (0, 0, health is not None))
+            if container_id not in self.collected_containers:
+                self.add_container_charts(container_id, name, has_healthcheck)
+
+            prefix = 'container_{0}_'.format(container_id)
+            # resets when the container is recreated
+            data[prefix + 'restarts'] = restarts
+            data[prefix + 'oom_kills'] = self.oom_kills.get(container_id, 0)
+            if prefix + 'health' in self.collected_containers[container_id]:
+                for status in HEALTH_STATUSES:
+                    data[prefix + status] = int(health == status)
+                data[prefix + 'failing_streak'] = failing_streak
+
+        self.to_inspect.clear()
+        for container_id in set(self.collected_containers) - seen:
+            self.remove_container_charts(container_id)
+
+        return data
+
+    def inspect(self, container_id, docker_id):
+        try:
+            info = self.client.api.inspect_container(docker_id)
+        except docker.errors.APIError as error:
+            # removed since it was listed
+            self.debug(error)
+            return
+        health = info['State'].get('Health')
+        self.inspected[container_id] = (
+            info.get('RestartCount', 0),
+            (health or dict()).get('FailingStreak', 0),
+            health is not None,
+        )
+
+    def collect_events(self):
+        """
+        Count the oom events of every container since the last run, and note the containers to inspect again.
+        """
+        now = time.time()
+        if self.last_events is not None:
+            # the stream ends at 'until'
+            events = self.client.api.events(since=self.last_events, until=now, decode=True,
+                                            filters={'type': 'container', 'event': ['oom'] + list(INSPECT_EVENTS)})
+            for event in events:
+                name = event.get('Actor', dict()).get('Attributes', dict()).get('name')
+                if not name:
+                    continue
+                container_id = clean_name(name)
+                if event.get('status', event.get('Action')) == 'oom':
+                    self.oom_kills[container_id] = self.oom_kills.get(container_id, 0) + 1
+                else:
+                    self.to_inspect.add(container_id)
+        self.last_events = now
+
+    def add_container_charts(self, container_id, name, has_healthcheck):
+        order, charts = container_charts(container_id, name, has_healthcheck)
+        self.collected_containers[container_id] = order
+        self.add_charts(order, charts)
+
+    def remove_container_charts(self, container_id):
+        self.oom_kills.pop(container_id, None)
+        self.inspected.pop(container_id, None)
+        self.remove_charts(self.collected_containers.pop(container_id))
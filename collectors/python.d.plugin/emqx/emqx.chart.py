@@ -0,0 +1,227 @@
+# -*- coding: utf-8 -*-
+# Description: emqx netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+API_STATS = 'stats?aggregate=true'
+API_METRICS = 'metrics?aggregate=true'
+API_NODES = 'nodes'
+
+NODE_STATUSES = (
+    'running',
+    'stopped',
+)
+
+# gauges of /stats, counters of /metrics, summed over the cluster nodes
+STATS = (
+    'connections.count',
+    'live_connections.count',
+    'sessions.count',
+    'topics.count',
+    'routes.count',
+    'subscriptions.count',
+    'subscriptions.shared.count',
+    'retained.count',
+    'delayed.count',
+)
+
+METRICS = (
+    'messages.received',
+    'messages.sent',
+    'messages.delayed',
+    'messages.dropped',
+    'delivery.dropped',
+    'bytes.received',
+    'bytes.sent',
+)
+
+ORDER = [
+    'connections',
+    'sessions',
+    'topics',
+    'subscriptions',
+    'messages',
+    'traffic',
+    'dropped',
+    'delayed',
+    'stored',
+    'nodes',
+]
+
+CHARTS = {
+    'connections': {
+        'options': [None, 'Connections', 'connections', 'clients', 'emqx.connections', 'line'],
+        'lines': [
+            ['connections_count', 'connections'],
+            ['live_connections_count', 'live'],
+        ]
+    },
+    'sessions': {
+        'options': [None, 'Sessions', 'sessions', 'clients', 'emqx.sessions', 'line'],
+        'lines': [
+            ['sessions_count', 'sessions'],
+        ]
+    },
+    'topics': {
+        'options': [None, 'Topics And Routes', 'topics', 'routing', 'emqx.topics', 'line'],
+        'lines': [
+            ['topics_count', 'topics'],
+            ['routes_count', 'routes'],
+        ]
+    },
+    'subscriptions': {
+        'options': [None, 'Subscriptions', 'subscriptions', 'routing', 'emqx.subscriptions', 'line'],
+        'lines': [
+            ['subscriptions_count', 'subscriptions'],
+            ['subscriptions_shared_count', 'shared'],
+        ]
+    },
+    'messages': {
+        'options': [None, 'Messages', 'messages/s', 'messages', 'emqx.messages', 'line'],
+        'lines': [
+            ['messages_received', 'received', 'incremental'],
+            ['messages_sent', 'sent', 'incremental', -1, 1],
+        ]
+    },
+    'traffic': {
+        'options': [None, 'Traffic', 'KiB/s', 'messages', 'emqx.traffic', 'area'],
+        'lines': [
+            ['bytes_received', 'received', 'incremental', 1, 1 << 10],
+            ['bytes_sent', 'sent', 'incremental', -1, 1 << 10],
+        ]
+    },
+    'dropped': {
+        'options': [None, 'Dropped Messages', 'messages/s', 'messages', 'emqx.dropped', 'line'],
+        'lines': [
+            ['messages_dropped', 'routing', 'incremental'],
+            ['delivery_dropped', 'delivery', 'incremental'],
+        ]
+    },
+    'delayed': {
+        'options': [None, 'Delayed Publish Messages', 'messages/s', 'messages', 'emqx.delayed', 'line'],
+        'lines': [
+            ['messages_delayed', 'delayed', 'incremental'],
+        ]
+    },
+    'stored': {
+        'options': [None, 'Stored Messages', 'messages', 'messages', 'emqx.stored_messages', 'line'],
+        'lines': [
+            ['retained_count', 'retained'],
+            ['delayed_count', 'delayed'],
+        ]
+    },
+    'nodes': {
+        'options': [None, 'Cluster Nodes', 'nodes', 'cluster', 'emqx.nodes', 'stacked'],
+        'lines': [['nodes_' + s, s] for s in NODE_STATUSES]
+    },
+}
+
+
+def node_charts(node_id, node):
+    order = [
+        'node_{0}_status'.format(node_id),
+        'node_{0}_connections'.format(node_id),
+    ]
+    family = 'node ' + node
+    charts = {
+        order[0]: {
+            'options': [None, 'Node Status', 'status', family, 'emqx.node_status', 'line'],
+            'lines': [['node_{0}_{1}'.format(node_id, s), s] for s in NODE_STATUSES]
+        },
+        order[1]: {
+            'options': [None, 'Node Connections', 'connections', family, 'emqx.node_connections', 'line'],
+            'lines': [
+                ['node_{0}_connections'.format(node_id), 'connections'],
+                ['node_{0}_live_connections'.format(node_id), 'live'],
+            ]
+        },
+    }
+    return order, charts
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:18083/api/v5').rstrip('/')
+        self.do_nodes = self.configuration.get('node_charts', True)
+        # node id -> <list> of its charts
+        self.collected_nodes = dict()
+
+    def get_json(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        try:
+            return json.loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(path, error))
+            return None
+
+    def _get_data(self):
+        stats = self.get_json(API_STATS)
+        if not isinstance(stats, dict):
+            return None
+
+        data = dict()
+        for key in STATS:
+            if key in stats:
+                data[key.replace('.', '_')] = stats[key]
+
+        metrics = self.get_json(API_METRICS)
+        if isinstance(metrics, dict):
+            for key in METRICS:
+                if key in metrics:
+                    data[key.replace('.', '_')] = metrics[key]
+
+        nodes = self.get_json(API_NODES)
+        if isinstance(nodes, list):
+            data.update(self.collect_nodes(nodes))
+
+        return data
+
+    def collect_nodes(self, nodes):
+        data = dict(('nodes_' + s, 0) for s in NODE_STATUSES)
+        seen = set()
+
+        for node in nodes:
+            name, status = node.get('node'), node.get('node_status')
+            if not name or status not in NODE_STATUSES:
+                continue
+            data['nodes_' + status] += 1
+
+            if not self.do_nodes:
+                continue
+            node_id = clean_name(name)
+            seen.add(node_id)
+            if node_id not in self.collected_nodes:
+                self.add_node_charts(node_id, name)
+
+            prefix = 'node_{0}_'.format(node_id)
+            for s in NODE_STATUSES:
+                data[prefix + s] = int(status == s)
+            # a stopped node reports no connections
+            data[prefix + 'connections'] = node.get('connections', 0)
+            data[prefix + 'live_connections'] = node.get('live_connections', 0)
+
+        for node_id in set(self.collected_nodes) - seen:
+            self.remove_node_charts(node_id)
+
+        return data
+
+    def add_node_charts(self, node_id, node):
+        order, charts = node_charts(node_id, node)
+        self.collected_nodes[node_id] = order
+        self.add_charts(order, charts)
+
+    def remove_node_charts(self, node_id):
+        # nodes that left the cluster
+        self.remove_charts(self.collected_nodes.pop(node_id))
@@ -0,0 +1,281 @@
+# -*- coding: utf-8 -*-
+# Description: gpu netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import subprocess
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import find_binary
+from bases.prometheus import parse
+
+update_every = 5
+
+NVIDIA_SMI = 'nvidia-smi'
+
+# (nvidia-smi field, key), the values are '[N/A]' or '[Not Supported]' when unavailable
+QUERY_GPU = (
+    ('index', 'index'),
+    ('uuid', 'uuid'),
+    ('name', 'name'),
+    ('utilization.gpu', 'utilization'),
+    ('memory.used', 'memory_used'),
+    ('memory.free', 'memory_free'),
+    ('temperature.gpu', 'temperature'),
+    ('power.draw', 'power_draw'),
+    ('clocks.sm', 'sm_clock'),
+    ('clocks.mem', 'mem_clock'),
+    ('ecc.errors.corrected.aggregate.total', 'ecc_corrected'),
+    ('ecc.errors.uncorrected.aggregate.total', 'ecc_uncorrected'),
+)
+
+QUERY_APPS = ('gpu_uuid', 'pid', 'process_name', 'used_memory')
+
+# dcgm-exporter field -> key, the ECC counters are not in its default counters file
+DCGM_METRICS = {
+    'DCGM_FI_DEV_GPU_UTIL': 'utilization',
+    'DCGM_FI_DEV_FB_USED': 'memory_used',
+    'DCGM_FI_DEV_FB_FREE': 'memory_free',
+    'DCGM_FI_DEV_GPU_TEMP': 'temperature',
+    'DCGM_FI_DEV_POWER_USAGE': 'power_draw',
+    'DCGM_FI_DEV_SM_CLOCK': 'sm_clock',
+    'DCGM_FI_DEV_MEM_CLOCK': 'mem_clock',
+    'DCGM_FI_DEV_ECC_SBE_AGG_TOTAL': 'ecc_corrected',
+    'DCGM_FI_DEV_ECC_DBE_AGG_TOTAL': 'ecc_uncorrected',
+}
+
+ORDER = [
+    'gpus',
+]
+
+CHARTS = {
+    'gpus': {
+        'options': [None, 'GPUs', 'gpus', 'overview', 'gpu.gpus', 'line'],
+        'lines': [
+            ['gpus', 'gpus'],
+        ]
+    },
+}
+
+
+def gpu_charts(gpu_id, gpu, ecc, processes):
+    order = [
+        'gpu_{0}_utilization'.format(gpu_id),
+        'gpu_{0}_memory'.format(gpu_id),
+        'gpu_{0}_temperature'.format(gpu_id),
+        'gpu_{0}_power'.format(gpu_id),
+        'gpu_{0}_clocks'.format(gpu_id),
+    ]
+    family = gpu
+    charts = {
+        order[0]: {
+            'options': [None, 'GPU Utilization', 'percentage', family, 'gpu.utilization', 'line'],
+            'lines': [
+                ['gpu_{0}_utilization'.format(gpu_id), 'utilization'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Memory Usage', 'MiB', family, 'gpu.memory', 'stacked'],
+            'lines': [
+                ['gpu_{0}_memory_free'.format(gpu_id), 'free'],
+                ['gpu_{0}_memory_used'.format(gpu_id), 'used'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Temperature', 'celsius', family, 'gpu.temperature', 'line'],
+            'lines': [
+                ['gpu_{0}_temperature'.format(gpu_id), 'temperature'],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Power Draw', 'Watts', family, 'gpu.power', 'line'],
+            'lines': [
+                ['gpu_{0}_power_draw'.format(gpu_id), 'power', 'absolute', 1, 1000],
+            ]
+        },
+        order[4]: {
+            'options': [None, 'Clock Frequencies', 'MHz', family, 'gpu.clocks', 'line'],
+            'lines': [
+                ['gpu_{0}_sm_clock'.format(gpu_id), 'sm'],
+                ['gpu_{0}_mem_clock'.format(gpu_id), 'mem'],
+            ]
+        },
+    }
+
+    if ecc:
+        order.append('gpu_{0}_ecc_errors'.format(gpu_id))
+        charts[order[-1]] = {
+            'options': [None, 'ECC Errors', 'errors', family, 'gpu.ecc_errors', 'line'],
+            'lines': [
+                ['gpu_{0}_ecc_corrected'.format(gpu_id), 'corrected'],
+                ['gpu_{0}_ecc_uncorrected'.format(gpu_id), 'uncorrected'],
+            ]
+        }
+    if processes:
+        order.append('gpu_{0}_processes_memory'.format(gpu_id))
+        charts[order[-1]] = {
+            'options': [None, 'Memory Used By Processes', 'MiB', family, 'gpu.processes_memory', 'stacked'],
+            'lines': []
+        }
+
+    return order, charts
+
+
+def to_number(value):
+    try:
+        return float(value)
+    except (TypeError, ValueError):
+        return None
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        # dcgm-exporter if the job has an url, nvidia-smi otherwise
+        self.nvidia_smi = self.configuration.get('nvidia_smi_path')
+        self.do_processes = self.configuration.get('processes_charts', True)
+        # gpu id -> <list> of its charts
+        self.collected_gpus = dict()
+        # gpu id -> <set> of the pids in its processes chart
+        self.collected_processes = dict()
+
+    def check(self):
+        if self.url:
+            return UrlService.check(self)
+
+        self.nvidia_smi = self.nvidia_smi or find_binary(NVIDIA_SMI)
+        if not self.nvidia_smi:
+            self.error('can\'t locate "{0}" binary'.format(NVIDIA_SMI))
+            return False
+        return bool(self._get_data())
+
+    def _get_data(self):
+        if self.url:
+            gpus, processes = self.dcgm_gpus(), None
+        else:
+            gpus = self.smi_gpus()
+            processes = self.smi_processes() if self.do_processes and gpus else None
+        if not gpus:
+            return None
+
+        data = {'gpus': len(gpus)}
+        for gpu_id, gpu in gpus.items():
+            if gpu_id not in self.collected_gpus:
+                ecc = gpu.get('ecc_corrected') is not None
+                self.add_gpu_charts(gpu_id, gpu['name'], ecc, processes is not None)
+
+            for key, value in gpu.items():
+                if key in ('name', 'uuid') or value is None:
+                    continue
+                if key == 'power_draw':
+                    value *= 1000
+                data['gpu_{0}_{1}'.format(gpu_id, key)] = int(value)
+
+            if processes is not None:
+                data.update(self.processes_data(gpu_id, processes.get(gpu['uuid']) or list()))
+
+        # the GPUs that fell off the bus
+        for gpu_id in set(self.collected_gpus) - set(gpus):
+            self.remove_gpu_charts(gpu_id)
+
+        return data
+
+    def run_nvidia_smi(self, args):
+        try:
+            output = subprocess.check_output([self.nvidia_smi] + args, stderr=subprocess.PIPE)
+        except (OSError, subprocess.CalledProcessError) as error:
+            self.error('{0}: {1}'.format(' '.join(args), error))
+            return None
+        return output.decode('utf-8', 'replace').splitlines()
+
+    def smi_gpus(self):
+        args = [
+            '--query-gpu=' + ','.join(f[0] for f in QUERY_GPU),
+            '--format=csv,noheader,nounits',
+        ]
+        lines = self.run_nvidia_smi(args)
+        if not lines:
+            return None
+
+        gpus = dict()
+        for line in lines:
+            fields = [f.strip() for f in line.split(',')]
+            if len(fields) != len(QUERY_GPU):
+                continue
+            gpu = dict((key, to_number(value)) for (_, key), value in zip(QUERY_GPU, fields))
+            gpu['name'] = 'gpu{0} {1}'.format(fields[0], fields[2])
+            gpu['uuid'] = fields[1]
+            del gpu['index']
+            gpus[fields[0]] = gpu
+        return gpus
+
+    def smi_processes(self):
+        args = [
+            '--query-compute-apps=' + ','.join(QUERY_APPS),
+            '--format=csv,noheader,nounits',
+        ]
+        lines = self.run_nvidia_smi(args)
+        if lines is None:
+            return None
+
+        processes = dict()
+        for line in lines:
+            # the process name can have commas
+            fields = [f.strip() for f in line.split(',')]
+            if len(fields) < len(QUERY_APPS):
+                continue
+            uuid, pid, name, used_memory = fields[0], fields[1], ','.join(fields[2:-1]), to_number(fields[-1])
+            processes.setdefault(uuid, list()).append((pid, name.split('/')[-1], used_memory or 0))
+        return processes
+
+    def dcgm_gpus(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        gpus = dict()
+        for name, labels, value in parse(raw, DCGM_METRICS):
+            gpu_id = labels.get('gpu')
+            if gpu_id is None:
+                continue
+            if gpu_id not in gpus:
+                gpus[gpu_id] = {
+                    'name': 'gpu{0} {1}'.format(gpu_id, labels.get('modelName', '')).strip(),
+                    'uuid': labels.get('UUID'),
+                }
+            gpus[gpu_id][DCGM_METRICS[name]] = value
+        return gpus
+
+    def processes_data(self, gpu_id, processes):
+        chart_name = 'gpu_{0}_processes_memory'.format(gpu_id)
+        collected = self.collected_processes.setdefault(gpu_id, set())
+
+        data = dict()
+        pids = set()
+        for pid, name, used_memory in processes:
+            dim_id = 'gpu_{0}_process_{1}'.format(gpu_id, pid)
+            pids.add(pid)
+            if pid not in collected:
+                collected.add(pid)
+                dimension = [dim_id, '{0} {1}'.format(pid, name)]
+                self.add_dimension(chart_name, dimension)
+            data[dim_id] = int(used_memory)
+
+        # exited processes
+        for pid in collected - pids:
+            if not self.charts:
+                continue
+            collected.remove(pid)
+            self.charts[chart_name].del_dimension('gpu_{0}_process_{1}'.format(gpu_id, pid), hide=False)
+        return data
+
+    def add_gpu_charts(self, gpu_id, gpu, ecc, processes):
+        order, charts = gpu_charts(gpu_id, gpu, ecc, processes)
+        self.collected_gpus[gpu_id] = order
+        self.add_charts(order, charts)
+
+    def remove_gpu_charts(self, gpu_id):
+        self.collected_processes.pop(gpu_id, None)
+        self.remove_charts(self.collected_gpus.pop(gpu_id))
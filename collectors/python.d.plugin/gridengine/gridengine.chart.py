@@ -0,0 +1,204 @@
+# -*- coding: utf-8 -*-
+# Description: grid engine netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import clean_name, find_binary
+
+update_every = 30
+
+QSTAT = 'qstat'
+
+# cluster queue summary
+QUEUES_ARGS = ['-g', 'c']
+# jobs of all the users, the pending array jobs are a single line
+JOBS_ARGS = ['-u', '*']
+
+JOB_STATES = (
+    'pending',
+    'running',
+    'transferring',
+    'suspended',
+    'held',
+    'error',
+    'deleting',
+    'other',
+)
+
+ORDER = [
+    'slots',
+    'jobs',
+]
+
+CHARTS = {
+    'slots': {
+        'options': [None, 'Slots', 'slots', 'slots', 'gridengine.slots', 'stacked'],
+        'lines': [
+            ['slots_used', 'used'],
+            ['slots_reserved', 'reserved'],
+            ['slots_available', 'available'],
+            ['slots_unavailable', 'unavailable'],
+        ]
+    },
+    'jobs': {
+        'options': [None, 'Jobs', 'jobs', 'jobs', 'gridengine.jobs', 'stacked'],
+        'lines': [['jobs_' + s, s] for s in JOB_STATES]
+    },
+}
+
+
+def queue_charts(queue_id, queue):
+    order = [
+        'queue_{0}_slots'.format(queue_id),
+        'queue_{0}_load'.format(queue_id),
+    ]
+    family = 'queue ' + queue
+    charts = {
+        order[0]: {
+            'options': [None, 'Slots', 'slots', family, 'gridengine.queue_slots', 'stacked'],
+            'lines': [['queue_{0}_{1}'.format(queue_id, s), s] for s in ('used', 'reserved', 'available',
+                                                                          'unavailable')]
+        },
+        order[1]: {
+            'options': [None, 'Normalized Load', 'load', family, 'gridengine.queue_load', 'line'],
+            'lines': [
+                ['queue_{0}_load'.format(queue_id), 'load', 'absolute', 1, 100],
+            ]
+        },
+    }
+    return order, charts
+
+
+def job_state(state):
+    # 'r', 'qw', 'hqw', 'Eqw', 'Rr', 'dr', 'S'...
+    if 'E' in state:
+        return 'error'
+    if 'd' in state:
+        return 'deleting'
+    if 'h' in state:
+        return 'held'
+    if set(state) & set('sST'):
+        return 'suspended'
+    if 'r' in state:
+        return 'running'
+    if 't' in state:
+        return 'transferring'
+    if 'qw' in state:
+        return 'pending'
+    return 'other'
+
+
+def tasks_count(tasks):
+    """
+    Number of tasks of an array job task range, '1-100:1', '1,3,5' or '1-10:2,20'.
+    """
+    count = 0
+    for task_range in tasks.split(','):
+        task_range, _, step = task_range.partition(':')
+        first, _, last = task_range.partition('-')
+        try:
+            count += (int(last) - int(first)) // int(step or 1) + 1 if last else 1
+        except ValueError:
+            count += 1
+    return count
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.qstat = self.configuration.get('qstat_path')
+        self.do_queues = self.configuration.get('queue_charts', True)
+        self.collected_queues = set()
+
+    def check(self):
+        self.qstat = self.qstat or find_binary(QSTAT)
+        if not self.qstat:
+            self.error('can\'t locate "{0}" binary'.format(QSTAT))
+            return False
+
+        data = self._get_data()
+        if not data:
+            self.error('no data from {0}, is SGE_ROOT set in the netdata environment?'.format(self.qstat))
+            return False
+        return True
+
+    def _get_data(self):
+        queues = self._get_raw_data(command=[self.qstat] + QUEUES_ARGS)
+        jobs = self._get_raw_data(command=[self.qstat] + JOBS_ARGS)
+        if not queues or jobs is None:
+            return None
+
+        data = dict()
+        data.update(self.queues_data(queues))
+        data.update(self.jobs_data(jobs))
+        return data
+
+    def queues_data(self, raw):
+        data = {
+            'slots_used': 0,
+            'slots_reserved': 0,
+            'slots_available': 0,
+            'slots_unavailable': 0,
+        }
+
+        # CLUSTER QUEUE  CQLOAD  USED  RES  AVAIL  TOTAL  aoACDS  cdsuE
+        for line in raw:
+            parts = line.split()
+            if len(parts) != 8 or not all(p.isdigit() for p in parts[2:]):
+                continue
+            queue, load = parts[0], parts[1]
+            used, reserved, available, total = (int(p) for p in parts[2:6])
+            # the slots of queue instances in alarm, error, disabled, suspended... states
+            unavailable = max(total - used - reserved - available, 0)
+
+            data['slots_used'] += used
+            data['slots_reserved'] += reserved
+            data['slots_available'] += available
+            data['slots_unavailable'] += unavailable
+
+            if not self.do_queues:
+                continue
+
+            queue_id = clean_name(queue)
+            if queue_id not in self.collected_queues:
+                self.collected_queues.add(queue_id)
+                self.add_queue_charts(queue_id, queue)
+
+            prefix = 'queue_{0}_'.format(queue_id)
+            data[prefix + 'used'] = used
+            data[prefix + 'reserved'] = reserved
+            data[prefix + 'available'] = available
+            data[prefix + 'unavailable'] = unavailable
+            try:
+                data[prefix + 'load'] = int(float(load) * 100)
+            except ValueError:
+                # '-NA-' without load reports
+                pass
+
+        return data
+
+    @staticmethod
+    def jobs_data(raw):
+        data = dict(('jobs_' + s, 0) for s in JOB_STATES)
+
+        # job-ID  prior  name  user  state  submit/start at  queue  slots  ja-task-ID
+        for line in raw:
+            parts = line.split()
+            if len(parts) < 8 or not parts[0].isdigit():
+                continue
+            state, rest = parts[4], parts[7:]
+            # the pending jobs have no queue
+            if not rest[0].isdigit():
+                rest = rest[1:]
+            count = tasks_count(rest[1]) if len(rest) > 1 else 1
+            data['jobs_' + job_state(state)] += count
+
+        return data
+
+    def add_queue_charts(self, queue_id, queue):
+        order, charts = queue_charts(queue_id, queue)
+        self.add_charts(order, charts)
@@ -0,0 +1,160 @@
+# -*- coding: utf-8 -*-
+# Description: grpc health checks netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import time
+
+try:
+    import grpc
+
+    HAS_GRPC = True
+except ImportError:
+    HAS_GRPC = False
+
+from bases.FrameworkServices.SimpleService import SimpleService
+
+update_every = 5
+
+# grpc.health.v1.Health, the messages are encoded by the module, it needs no generated code nor protobuf
+METHOD_CHECK = '/grpc.health.v1.Health/Check'
+
+# HealthCheckResponse.ServingStatus
+STATUSES = {
+    0: 'unknown',
+    1: 'serving',
+    2: 'not_serving',
+    3: 'service_unknown',
+}
+
+# the probe failed: no connection, timeout, no health service on the server...
+FAILED = 'failed'
+
+DEFAULT_ADDRESS = '127.0.0.1:50051'
+DEFAULT_TIMEOUT = 1
+
+ORDER = [
+    'status',
+    'latency',
+]
+
+CHARTS = {
+    'status': {
+        'options': [None, 'Health Status', 'status', 'health', 'grpccheck.status', 'line'],
+        'lines': [
+            ['serving', 'serving'],
+            ['not_serving', 'not_serving'],
+            ['service_unknown', 'service_unknown'],
+            ['unknown', 'unknown'],
+            [FAILED, FAILED],
+        ]
+    },
+    'latency': {
+        'options': [None, 'Health Check Latency', 'milliseconds', 'health', 'grpccheck.latency', 'line'],
+        'lines': [
+            ['latency', 'latency', 'absolute', 1, 1000],
+        ]
+    },
+}
+
+
+def encode_request(service):
+    """
+    :param service: <str> the service name, '' for the whole server
+    :return: <bytes> HealthCheckRequest, 'string service = 1'
+    """
+    service = service.encode('utf-8')
+    if not service:
+        return b''
+    length, size = bytearray(), len(service)
+    while True:
+        byte, size = size & 0x7f, size >> 7
+        if size:
+            length.append(byte | 0x80)
+            continue
+        length.append(byte)
+        break
+    return b'\x0a' + bytes(length) + service
+
+
+def decode_response(message):
+    """
+    :param message: <bytes> HealthCheckResponse, 'ServingStatus status = 1'
+    :return: <int> the status, 0 (unknown) when not set
+    """
+    message = bytearray(message or b'')
+    # the status values are small, their varint is one byte
+    if len(message) >= 2 and message[0] == 0x08:
+        return message[1]
+    return 0
+
+
+class Service(SimpleService):
+    def __init__(self, configuration=None, name=None):
+        SimpleService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.address = self.configuration.get('address', DEFAULT_ADDRESS)
+        self.service = self.configuration.get('service', '')
+        self.timeout = self.configuration.get('timeout', DEFAULT_TIMEOUT)
+        self.tls = self.configuration.get('tls', False)
+        self.channel = None
+        self.check_method = None
+
+    def check(self):
+        if not HAS_GRPC:
+            self.error("'grpcio' package is needed to use grpccheck module")
+            return False
+
+        try:
+            self.channel = self.create_channel()
+        except (IOError, ValueError) as error:
+            self.error('{0}: {1}'.format(self.address, error))
+            return False
+
+        # the response is not deserialized by grpc, bytes in and out
+        self.check_method = self.channel.unary_unary(METHOD_CHECK)
+
+        # a server down or unhealthy is what the module reports, not a reason to disable the job
+        data = self.get_data()
+        if data and data[FAILED]:
+            self.warning('{0}: health check failed, the server may not be up yet'.format(self.address))
+        return bool(data)
+
+    def create_channel(self):
+        if not self.tls:
+            return grpc.insecure_channel(self.address)
+
+        credentials = grpc.ssl_channel_credentials(
+            root_certificates=read_file(self.configuration.get('tls_ca')),
+            private_key=read_file(self.configuration.get('tls_key')),
+            certificate_chain=read_file(self.configuration.get('tls_cert')),
+        )
+        options = list()
+        if self.configuration.get('tls_server_name'):
+            options.append(('grpc.ssl_target_name_override', self.configuration['tls_server_name']))
+        return grpc.secure_channel(self.address, credentials, options=options)
+
+    def _get_data(self):
+        data = dict((dim, 0) for dim in list(STATUSES.values()) + [FAILED])
+        start = time.time()
+        try:
+            response = self.check_method(encode_request(self.service), timeout=self.timeout)
+        except grpc.RpcError as error:
+            # the servers answer NOT_FOUND for the services they do not know
+            if error.code() == grpc.StatusCode.NOT_FOUND:
+                data['service_unknown'] = 1
+            else:
+                self.debug('{0} "{1}": {2}'.format(self.address, self.service, error.code()))
+                data[FAILED] = 1
+            return data
+
+        data['latency'] = int((time.time() - start) * 1000000)
+        data[STATUSES.get(decode_response(response), 'unknown')] = 1
+        return data
+
+
+def read_file(path):
+    if not path:
+        return None
+    with open(path, 'rb') as f:
+        return f.read()
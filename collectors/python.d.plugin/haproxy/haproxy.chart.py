@@ -206,7 +206,8 @@ REGEX = dict(url=re_compile(r'idle = (?P<idle>[0-9]+)'),
 # TODO: the code is unreadable
 class Service(UrlService, SocketService):
     def __init__(self, configuration=None, name=None):
-        if 'socket' in configuration:
+        # the runtime API listens on a unix socket, or on a TCP socket ('stats socket ipv4@<ip>:<port>')
+        if 'socket' in configuration or 'port' in configuration:
             SocketService.__init__(self, configuration=configuration, name=name)
             self.poll = SocketService
             self.options_ = dict(regex=REGEX['socket'],
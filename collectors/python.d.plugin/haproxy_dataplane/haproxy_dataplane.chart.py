@@ -0,0 +1,269 @@
+# -*- coding: utf-8 -*-
+# Description: haproxy_dataplane netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import os
+from copy import deepcopy
+from json import loads
+
+try:
+    from urllib.parse import quote
+except ImportError:
+    from urllib import quote
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+API_RELOADS = 'v2/services/haproxy/reloads'
+API_STICK_TABLES = 'v2/services/haproxy/runtime/stick_tables'
+API_MAPS = 'v2/services/haproxy/runtime/maps'
+API_MAP_ENTRIES = 'v2/services/haproxy/runtime/maps_entries?map={0}'
+API_ACLS = 'v2/services/haproxy/runtime/acls'
+API_ACL_ENTRIES = 'v2/services/haproxy/runtime/acls/{0}/entries'
+
+update_every = 5
+
+RELOAD_STATUSES = ('succeeded', 'failed')
+
+ORDER = [
+    'reloads',
+    'reloads_in_progress',
+    'workers',
+    'draining_age',
+    'stick_tables_usage',
+    'stick_tables_entries',
+    'maps_entries',
+    'acls_entries',
+]
+
+CHARTS = {
+    'reloads': {
+        'options': [None, 'Reloads', 'reloads/s', 'reloads', 'haproxy_dataplane.reloads', 'stacked'],
+        'lines': [
+            ['reloads_succeeded', 'succeeded', 'incremental'],
+            ['reloads_failed', 'failed', 'incremental'],
+        ]
+    },
+    'reloads_in_progress': {
+        'options': [None, 'Reloads In Progress', 'reloads', 'reloads', 'haproxy_dataplane.reloads_in_progress',
+                    'line'],
+        'lines': [
+            ['reloads_in_progress', 'in progress'],
+        ]
+    },
+    'workers': {
+        'options': [None, 'Worker Processes', 'processes', 'reloads', 'haproxy_dataplane.workers', 'stacked'],
+        'lines': [
+            ['workers_current', 'current'],
+            ['workers_draining', 'draining'],
+        ]
+    },
+    'draining_age': {
+        'options': [None, 'Oldest Draining Worker Age', 'seconds', 'reloads', 'haproxy_dataplane.draining_age',
+                    'line'],
+        'lines': [
+            ['workers_draining_age', 'age'],
+        ]
+    },
+    'stick_tables_usage': {
+        'options': [None, 'Stick Tables Usage', 'percentage', 'stick tables', 'haproxy_dataplane.stick_tables_usage',
+                    'line'],
+        'lines': []
+    },
+    'stick_tables_entries': {
+        'options': [None, 'Stick Tables Entries', 'entries', 'stick tables',
+                    'haproxy_dataplane.stick_tables_entries', 'line'],
+        'lines': []
+    },
+    'maps_entries': {
+        'options': [None, 'Runtime Map Entries', 'entries', 'maps', 'haproxy_dataplane.maps_entries', 'line'],
+        'lines': []
+    },
+    'acls_entries': {
+        'options': [None, 'Runtime ACL Entries', 'entries', 'acls', 'haproxy_dataplane.acls_entries', 'line'],
+        'lines': []
+    },
+}
+
+
+def read_proc_stat(pid):
+    """
+    Returns (comm, ppid, starttime in clock ticks since boot) of a process.
+    """
+    with open('/proc/{0}/stat'.format(pid)) as f:
+        raw = f.read()
+    # comm may contain spaces, it is enclosed in the first '(' and the last ')'
+    comm = raw[raw.index('(') + 1:raw.rindex(')')]
+    fields = raw[raw.rindex(')') + 2:].split()
+    return comm, int(fields[1]), int(fields[19])
+
+
+def find_workers(process_name):
+    """
+    Returns the start times of the worker processes of the haproxy master,
+    the newest one is the current worker, the others are draining after a reload.
+    """
+    processes = dict()
+    for pid in os.listdir('/proc'):
+        if not pid.isdigit():
+            continue
+        try:
+            comm, ppid, start = read_proc_stat(pid)
+        except (OSError, IOError, ValueError, IndexError):
+            continue
+        if comm == process_name:
+            processes[int(pid)] = (ppid, start)
+
+    # workers are the processes whose parent is a haproxy process (the master)
+    return sorted(start for ppid, start in processes.values() if ppid in processes)
+
+
+def system_uptime():
+    with open('/proc/uptime') as f:
+        return float(f.read().split()[0])
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:5555').rstrip('/')
+        self.collect_entries = self.configuration.get('collect_entries', True)
+        self.process_name = self.configuration.get('process_name', 'haproxy')
+        self.collect_workers = self.configuration.get('collect_workers', True)
+        self.clock_ticks = os.sysconf('SC_CLK_TCK')
+        self.seen_reloads = dict((status, set()) for status in RELOAD_STATUSES)
+        self.reloads = dict((status, 0) for status in RELOAD_STATUSES)
+        self.collected_dims = dict((chart, set()) for chart in ORDER)
+
+    def check(self):
+        if not self.user or not self.password:
+            self.error('the Data Plane API needs "user" and "pass" to be set')
+            return False
+
+        if self.collect_workers and not os.path.isdir('/proc'):
+            self.collect_workers = False
+
+        return UrlService.check(self)
+
+    def get_json(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        try:
+            return loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(path, error))
+            return None
+
+    def _get_data(self):
+        data = dict()
+
+        reloads = self.get_json(API_RELOADS)
+        if reloads is None:
+            return None
+        data.update(self.get_reloads_stats(reloads))
+
+        if self.collect_workers:
+            data.update(self.get_workers_stats())
+
+        tables = self.get_json(API_STICK_TABLES)
+        if tables:
+            data.update(self.get_stick_tables_stats(tables))
+
+        if self.collect_entries:
+            maps = self.get_json(API_MAPS)
+            if maps:
+                data.update(self.get_maps_stats(maps))
+
+            acls = self.get_json(API_ACLS)
+            if acls:
+                data.update(self.get_acls_stats(acls))
+
+        return data
+
+    def get_reloads_stats(self, reloads):
+        in_progress = 0
+        for item in reloads:
+            status = item.get('status')
+            if status == 'in_progress':
+                in_progress += 1
+            elif status in RELOAD_STATUSES and item.get('id') not in self.seen_reloads[status]:
+                self.seen_reloads[status].add(item.get('id'))
+                self.reloads[status] += 1
+
+        # the reloads listed by the API are the history since the Data Plane API started
+        stats = dict(('reloads_' + status, count) for status, count in self.reloads.items())
+        stats['reloads_in_progress'] = in_progress
+        return stats
+
+    def get_workers_stats(self):
+        try:
+            workers = find_workers(self.process_name)
+            uptime = system_uptime()
+        except (OSError, IOError, ValueError) as error:
+            self.error('can\'t read processes: {0}'.format(error))
+            return dict()
+
+        draining = workers[:-1]
+        stats = {
+            'workers_current': int(bool(workers)),
+            'workers_draining': len(draining),
+            'workers_draining_age': 0,
+        }
+        if draining:
+            stats['workers_draining_age'] = int(uptime - float(draining[0]) / self.clock_ticks)
+        return stats
+
+    def get_stick_tables_stats(self, tables):
+        stats = dict()
+        for table in tables:
+            name, size, used = table.get('name'), table.get('size'), table.get('used')
+            if not name or size is None or used is None:
+                continue
+
+            dim_id = 'stick_table_' + clean_name(name)
+            self.add_dimension('stick_tables_usage', [dim_id + '_usage', name, 'absolute', 1, 1000])
+            self.add_dimension('stick_tables_entries', [dim_id + '_used', name])
+
+            stats[dim_id + '_used'] = used
+            stats[dim_id + '_usage'] = used * 100 * 1000 // size if size else 0
+        return stats
+
+    def get_maps_stats(self, maps):
+        stats = dict()
+        for runtime_map in maps:
+            name = runtime_map.get('file')
+            if not name:
+                continue
+            entries = self.get_json(API_MAP_ENTRIES.format(quote(name, safe='')))
+            if entries is None:
+                continue
+
+            dim_id = 'map_' + clean_name(name)
+            self.add_dimension('maps_entries', [dim_id, os.path.basename(name)])
+            stats[dim_id] = len(entries)
+        return stats
+
+    def get_acls_stats(self, acls):
+        stats = dict()
+        for acl in acls:
+            acl_id = acl.get('id')
+            if acl_id is None:
+                continue
+            entries = self.get_json(API_ACL_ENTRIES.format(acl_id))
+            if entries is None:
+                continue
+
+            name = acl.get('storage_name') or acl.get('description') or acl_id
+            dim_id = 'acl_' + clean_name(acl_id)
+            self.add_dimension('acls_entries', [dim_id, str(name)])
+            stats[dim_id] = len(entries)
+        return stats
+
+    def add_dimension(self, chart, dimension):
+        if dimension[0] in self.collected_dims[chart]:
+            return
+        self.collected_dims[chart].add(dimension[0])
+        UrlService.add_dimension(self, chart, dimension)
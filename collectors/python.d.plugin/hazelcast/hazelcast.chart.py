@@ -0,0 +1,94 @@
+# -*- coding: utf-8 -*-
+# Description: hazelcast netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from json import loads
+
+from bases.FrameworkServices.UrlService import UrlService
+
+update_every = 5
+
+API_HEALTH = 'hazelcast/health'
+
+NODE_STATES = (
+    'ACTIVE',
+    'PASSIVE',
+    'SHUT_DOWN',
+)
+
+CLUSTER_STATES = (
+    'ACTIVE',
+    'NO_MIGRATION',
+    'FROZEN',
+    'PASSIVE',
+    'IN_TRANSITION',
+)
+
+ORDER = [
+    'cluster_size',
+    'migration_queue',
+    'cluster_safe',
+    'node_state',
+    'cluster_state',
+]
+
+CHARTS = {
+    'cluster_size': {
+        'options': [None, 'Cluster Members', 'members', 'cluster', 'hazelcast.cluster_size', 'line'],
+        'lines': [
+            ['cluster_size', 'members'],
+        ]
+    },
+    'migration_queue': {
+        'options': [None, 'Partition Migrations Queued', 'migrations', 'partitions', 'hazelcast.migration_queue',
+                    'line'],
+        'lines': [
+            ['migration_queue_size', 'queued'],
+        ]
+    },
+    'cluster_safe': {
+        'options': [None, 'Cluster Safe', 'status', 'partitions', 'hazelcast.cluster_safe', 'line'],
+        'lines': [
+            ['cluster_safe', 'safe'],
+        ]
+    },
+    'node_state': {
+        'options': [None, 'Member State', 'state', 'cluster', 'hazelcast.node_state', 'line'],
+        'lines': [['node_state_' + s.lower(), s.lower()] for s in NODE_STATES]
+    },
+    'cluster_state': {
+        'options': [None, 'Cluster State', 'state', 'cluster', 'hazelcast.cluster_state', 'line'],
+        'lines': [['cluster_state_' + s.lower(), s.lower()] for s in CLUSTER_STATES]
+    },
+}
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.url = self.configuration.get('url', 'http://127.0.0.1:5701').rstrip('/')
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_HEALTH))
+        if not raw:
+            return None
+
+        try:
+            health = loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(API_HEALTH, error))
+            return None
+
+        data = {
+            'cluster_size': health.get('clusterSize', 0),
+            'migration_queue_size': health.get('migrationQueueSize', 0),
+            'cluster_safe': int(bool(health.get('clusterSafe'))),
+        }
+        for state in NODE_STATES:
+            data['node_state_' + state.lower()] = int(health.get('nodeState') == state)
+        for state in CLUSTER_STATES:
+            data['cluster_state_' + state.lower()] = int(health.get('clusterState') == state)
+
+        return data
@@ -0,0 +1,184 @@
+# -*- coding: utf-8 -*-
+# Description: htcondor netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import find_binary
+
+update_every = 30
+
+CONDOR_STATUS = 'condor_status'
+CONDOR_Q = 'condor_q'
+
+# one line per slot, partitionable slots keep their unclaimed cpus
+SLOTS_ARGS = ['-af', 'State', 'Activity', 'Cpus']
+# one line per job of every schedd
+JOBS_ARGS = ['-allusers', '-global', '-af', 'JobStatus']
+
+# of the last cycle, the durations in seconds
+NEGOTIATOR_ATTRIBUTES = (
+    ('LastNegotiationCycleDuration0', 'cycle_duration', 1000),
+    ('LastNegotiationCyclePeriod0', 'cycle_period', 1000),
+    ('LastNegotiationCycleMatches0', 'cycle_matches', 1),
+    ('LastNegotiationCycleRejections0', 'cycle_rejections', 1),
+)
+NEGOTIATOR_ARGS = ['-negotiator', '-af'] + [a for a, _, _ in NEGOTIATOR_ATTRIBUTES]
+
+SLOT_STATES = (
+    'claimed',
+    'unclaimed',
+    'owner',
+    'matched',
+    'preempting',
+    'drained',
+    'backfill',
+    'other',
+)
+
+CLAIMED_ACTIVITIES = (
+    'busy',
+    'idle',
+    'suspended',
+    'retiring',
+    'other',
+)
+
+# JobStatus
+JOB_STATUSES = {
+    '1': 'idle',
+    '2': 'running',
+    '3': 'removed',
+    '4': 'completed',
+    '5': 'held',
+    '6': 'transferring_output',
+    '7': 'suspended',
+}
+
+ORDER = [
+    'slots',
+    'cpus',
+    'claimed_activity',
+    'jobs',
+    'negotiation_cycle',
+    'negotiation_matches',
+]
+
+CHARTS = {
+    'slots': {
+        'options': [None, 'Slots', 'slots', 'slots', 'htcondor.slots', 'stacked'],
+        'lines': [['slots_' + s, s] for s in SLOT_STATES]
+    },
+    'cpus': {
+        'options': [None, 'Slots CPUs', 'cpus', 'slots', 'htcondor.cpus', 'stacked'],
+        'lines': [['cpus_' + s, s] for s in SLOT_STATES]
+    },
+    'claimed_activity': {
+        'options': [None, 'Claimed Slots By Activity', 'slots', 'slots', 'htcondor.claimed_activity', 'stacked'],
+        'lines': [['claimed_' + a, a] for a in CLAIMED_ACTIVITIES]
+    },
+    'jobs': {
+        'options': [None, 'Jobs', 'jobs', 'jobs', 'htcondor.jobs', 'stacked'],
+        'lines': [['jobs_' + JOB_STATUSES[k], JOB_STATUSES[k]] for k in sorted(JOB_STATUSES)]
+    },
+    'negotiation_cycle': {
+        'options': [None, 'Last Negotiation Cycle', 'seconds', 'negotiator', 'htcondor.negotiation_cycle', 'line'],
+        'lines': [
+            ['cycle_duration', 'duration', 'absolute', 1, 1000],
+            ['cycle_period', 'period', 'absolute', 1, 1000],
+        ]
+    },
+    'negotiation_matches': {
+        'options': [None, 'Last Negotiation Cycle Matches', 'matches', 'negotiator', 'htcondor.negotiation_matches',
+                    'line'],
+        'lines': [
+            ['cycle_matches', 'matches'],
+            ['cycle_rejections', 'rejections'],
+        ]
+    },
+}
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.condor_status = self.configuration.get('condor_status_path')
+        self.condor_q = self.configuration.get('condor_q_path')
+
+    def check(self):
+        self.condor_status = self.condor_status or find_binary(CONDOR_STATUS)
+        self.condor_q = self.condor_q or find_binary(CONDOR_Q)
+        for name, binary in ((CONDOR_STATUS, self.condor_status), (CONDOR_Q, self.condor_q)):
+            if not binary:
+                self.error('can\'t locate "{0}" binary'.format(name))
+                return False
+
+        data = self._get_data()
+        if not data:
+            self.error('no data from {0} and {1}, is the collector reachable?'.format(self.condor_status,
+                                                                                       self.condor_q))
+            return False
+        return True
+
+    def _get_data(self):
+        slots = self._get_raw_data(command=[self.condor_status] + SLOTS_ARGS)
+        jobs = self._get_raw_data(command=[self.condor_q] + JOBS_ARGS)
+        if not slots or jobs is None:
+            return None
+
+        data = dict()
+        data.update(self.slots_data(slots))
+        data.update(self.jobs_data(jobs))
+        negotiator = self._get_raw_data(command=[self.condor_status] + NEGOTIATOR_ARGS)
+        if negotiator:
+            data.update(self.negotiator_data(negotiator))
+        return data
+
+    @staticmethod
+    def slots_data(raw):
+        data = dict()
+        data.update(('slots_' + s, 0) for s in SLOT_STATES)
+        data.update(('cpus_' + s, 0) for s in SLOT_STATES)
+        data.update(('claimed_' + a, 0) for a in CLAIMED_ACTIVITIES)
+
+        for line in raw:
+            parts = line.split()
+            if len(parts) != 3:
+                continue
+            state, activity, cpus = parts[0].lower(), parts[1].lower(), parts[2]
+            if state not in SLOT_STATES:
+                state = 'other'
+
+            data['slots_' + state] += 1
+            if cpus.isdigit():
+                data['cpus_' + state] += int(cpus)
+            if state == 'claimed':
+                data['claimed_' + (activity if activity in CLAIMED_ACTIVITIES else 'other')] += 1
+
+        return data
+
+    @staticmethod
+    def jobs_data(raw):
+        data = dict(('jobs_' + s, 0) for s in JOB_STATUSES.values())
+        # 'All queues are empty' when there are no jobs
+        for line in raw:
+            status = JOB_STATUSES.get(line.strip())
+            if status:
+                data['jobs_' + status] += 1
+        return data
+
+    @staticmethod
+    def negotiator_data(raw):
+        # the first negotiator, the attributes are 'undefined' before the first cycle
+        values = raw[0].split()
+        if len(values) != len(NEGOTIATOR_ATTRIBUTES):
+            return dict()
+
+        data = dict()
+        for (_, key, mult), value in zip(NEGOTIATOR_ATTRIBUTES, values):
+            try:
+                data[key] = int(float(value) * mult)
+            except ValueError:
+                continue
+        return data
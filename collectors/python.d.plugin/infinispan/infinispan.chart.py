@@ -0,0 +1,196 @@
+# -*- coding: utf-8 -*-
+# Description: infinispan netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+from json import loads
+
+try:
+    from urllib.parse import quote
+except ImportError:
+    from urllib import quote
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+API_HEALTH = 'rest/v2/cache-managers/{0}/health'
+API_CACHE_STATS = 'rest/v2/caches/{0}?action=stats'
+
+# caches used by the server itself, their names start with '___'
+INTERNAL_CACHE_PREFIX = '___'
+
+HEALTH_STATUSES = (
+    'HEALTHY',
+    'HEALTHY_REBALANCING',
+    'DEGRADED',
+    'FAILED',
+)
+
+ORDER = [
+    'cluster_members',
+    'cluster_health',
+    'caches_health',
+]
+
+CHARTS = {
+    'cluster_members': {
+        'options': [None, 'Cluster Members', 'members', 'cluster', 'infinispan.cluster_members', 'line'],
+        'lines': [
+            ['number_of_nodes', 'members'],
+        ]
+    },
+    'cluster_health': {
+        'options': [None, 'Cluster Health', 'status', 'cluster', 'infinispan.cluster_health', 'line'],
+        'lines': [['cluster_' + s.lower(), s.lower()] for s in HEALTH_STATUSES]
+    },
+    'caches_health': {
+        'options': [None, 'Caches By Health', 'caches', 'cluster', 'infinispan.caches_health', 'stacked'],
+        'lines': [['caches_' + s.lower(), s.lower()] for s in HEALTH_STATUSES]
+    },
+}
+
+
+def cache_charts(cache_id, cache_name):
+    order = [
+        'cache_{0}_entries'.format(cache_id),
+        'cache_{0}_operations'.format(cache_id),
+        'cache_{0}_hit_ratio'.format(cache_id),
+        'cache_{0}_evictions'.format(cache_id),
+        'cache_{0}_latency'.format(cache_id),
+        'cache_{0}_memory'.format(cache_id),
+    ]
+    family = 'cache ' + cache_name
+    charts = {
+        order[0]: {
+            'options': [None, 'Entries', 'entries', family, 'infinispan.cache_entries', 'line'],
+            'lines': [
+                ['cache_{0}_current_number_of_entries'.format(cache_id), 'entries'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Operations', 'operations/s', family, 'infinispan.cache_operations', 'stacked'],
+            'lines': [
+                ['cache_{0}_retrievals'.format(cache_id), 'retrievals', 'incremental'],
+                ['cache_{0}_stores'.format(cache_id), 'stores', 'incremental'],
+                ['cache_{0}_removes'.format(cache_id), 'removes', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Read Hits', 'percentage', family, 'infinispan.cache_hit_ratio', 'stacked'],
+            'lines': [
+                ['cache_{0}_hits'.format(cache_id), 'hits', 'percentage-of-incremental-row'],
+                ['cache_{0}_misses'.format(cache_id), 'misses', 'percentage-of-incremental-row'],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Evictions', 'evictions/s', family, 'infinispan.cache_evictions', 'line'],
+            'lines': [
+                ['cache_{0}_evictions'.format(cache_id), 'evictions', 'incremental'],
+            ]
+        },
+        order[4]: {
+            'options': [None, 'Average Operation Time', 'milliseconds', family, 'infinispan.cache_latency', 'line'],
+            'lines': [
+                ['cache_{0}_average_read_time'.format(cache_id), 'read'],
+                ['cache_{0}_average_write_time'.format(cache_id), 'write'],
+                ['cache_{0}_average_remove_time'.format(cache_id), 'remove'],
+            ]
+        },
+        order[5]: {
+            'options': [None, 'Data Memory', 'MiB', family, 'infinispan.cache_memory', 'stacked'],
+            'lines': [
+                ['cache_{0}_data_memory_used'.format(cache_id), 'heap', 'absolute', 1, 1 << 20],
+                ['cache_{0}_off_heap_memory_used'.format(cache_id), 'off heap', 'absolute', 1, 1 << 20],
+            ]
+        },
+    }
+    return order, charts
+
+
+CACHE_STATS = [
+    'current_number_of_entries',
+    'retrievals',
+    'stores',
+    'hits',
+    'misses',
+    'evictions',
+    'average_read_time',
+    'average_write_time',
+    'average_remove_time',
+    'data_memory_used',
+    'off_heap_memory_used',
+]
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:11222').rstrip('/')
+        self.cache_manager = self.configuration.get('cache_manager', 'default')
+        self.caches = self.configuration.get('caches') or list()
+        self.collected_caches = set()
+
+    def get_json(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        try:
+            return loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(path, error))
+            return None
+
+    def _get_data(self):
+        health = self.get_json(API_HEALTH.format(quote(self.cache_manager, safe='')))
+        if not health or 'cluster_health' not in health:
+            return None
+
+        cluster = health['cluster_health']
+        data = {
+            'number_of_nodes': cluster.get('number_of_nodes', 0),
+        }
+        for status in HEALTH_STATUSES:
+            data['cluster_' + status.lower()] = int(cluster.get('health_status') == status)
+            data['caches_' + status.lower()] = 0
+
+        for cache in health.get('cache_health') or list():
+            name, status = cache.get('cache_name'), cache.get('status')
+            if status in HEALTH_STATUSES:
+                data['caches_' + status.lower()] += 1
+
+            if not name or name.startswith(INTERNAL_CACHE_PREFIX) or (self.caches and name not in self.caches):
+                continue
+            data.update(self.get_cache_stats(name))
+
+        return data
+
+    def get_cache_stats(self, name):
+        stats = self.get_json(API_CACHE_STATS.format(quote(name, safe='')))
+        if not stats:
+            return dict()
+
+        cache_id = clean_name(name)
+        if cache_id not in self.collected_caches:
+            self.collected_caches.add(cache_id)
+            self.add_cache_charts(cache_id, name)
+
+        data = dict()
+        for stat in CACHE_STATS:
+            value = stats.get(stat)
+            # -1 means the statistic is not available (e.g. memory of caches without eviction)
+            if isinstance(value, (int, float)) and value >= 0:
+                data['cache_{0}_{1}'.format(cache_id, stat)] = value
+
+        removes = [stats.get(s) for s in ('remove_hits', 'remove_misses')]
+        if all(isinstance(v, int) and v >= 0 for v in removes):
+            data['cache_{0}_removes'.format(cache_id)] = sum(removes)
+
+        return data
+
+    def add_cache_charts(self, cache_id, cache_name):
+        order, charts = cache_charts(cache_id, cache_name)
+        self.add_charts(order, charts)
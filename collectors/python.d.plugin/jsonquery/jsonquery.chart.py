@@ -0,0 +1,207 @@
+# -*- coding: utf-8 -*-
+# Description: jsonquery netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import re
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+WILDCARD = object()
+
+# '.key', '[0]', '[*]', '.*', "['key']" and '["key"]'
+RE_STEP = re.compile(r'''\.([^.\[\]]+)|\[(\d+|\*)\]|\[(?:'([^']*)'|"([^"]*)")\]''')
+
+VALUE_TYPES = ('int', 'float')
+
+
+def parse_path(path):
+    """
+    Parse a JSONPath subset: '$.status.queues[0].depth', '$.workers[*].busy', "$['my.key'].value".
+    The leading '$' is optional.
+    :return: <list> of steps, <str> keys, <int> indexes and WILDCARD. None if the path is invalid
+    """
+    path = path.strip()
+    if path.startswith('$'):
+        path = path[1:]
+    if path and path[0] not in '.[':
+        path = '.' + path
+
+    steps, pos = list(), 0
+    while pos < len(path):
+        match = RE_STEP.match(path, pos)
+        if not match:
+            return None
+        key, index, quoted, double_quoted = match.groups()
+        if key == '*' or index == '*':
+            steps.append(WILDCARD)
+        elif key is not None:
+            steps.append(key)
+        elif index is not None:
+            steps.append(int(index))
+        else:
+            steps.append(quoted if quoted is not None else double_quoted)
+        pos = match.end()
+    return steps
+
+
+def evaluate(document, steps):
+    """
+    :return: <list> of (<list> keys matched by the wildcards, value)
+    """
+    matches = [(list(), document)]
+    for step in steps:
+        found = list()
+        for keys, value in matches:
+            if step is WILDCARD:
+                if isinstance(value, dict):
+                    found.extend((keys + [str(k)], v) for k, v in value.items())
+                elif isinstance(value, list):
+                    found.extend((keys + [str(i)], v) for i, v in enumerate(value))
+            elif isinstance(step, int):
+                if isinstance(value, list) and step < len(value):
+                    found.append((keys, value[step]))
+            elif isinstance(value, dict) and step in value:
+                found.append((keys, value[step]))
+        matches = found
+    return matches
+
+
+def to_number(value):
+    # true/false are 1/0, numbers in strings are parsed
+    if isinstance(value, bool):
+        return int(value)
+    if isinstance(value, (int, float)):
+        return value
+    try:
+        return float(value)
+    except (TypeError, ValueError):
+        return None
+
+
+class Line:
+    def __init__(self, chart, conf, steps):
+        self.chart = chart
+        # the ids of the lines only need to be unique within their chart
+        self.id = '{0}_{1}'.format(chart, conf['id'])
+        self.name = conf.get('name') or conf['id']
+        self.path = conf['path']
+        self.steps = steps
+        self.type = conf.get('type', 'int')
+        self.algorithm = conf.get('algorithm', 'absolute')
+        self.multiplier = conf.get('multiplier', 1)
+        self.divisor = conf.get('divisor', 100 if self.type == 'float' else 1)
+        # the paths with a wildcard create one dimension per match
+        self.dynamic = WILDCARD in steps
+
+    def dimension(self, dim_id, name):
+        return [dim_id, name, self.algorithm, self.multiplier, self.divisor]
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list()
+        self.definitions = dict()
+        self.lines = list()
+        self.collected_dims = set()
+
+    def check(self):
+        if not self.url:
+            self.error('no url, the module has no default one')
+            return False
+        if not self.parse_charts(self.configuration.get('charts')):
+            return False
+        return UrlService.check(self)
+
+    def parse_charts(self, charts):
+        if not charts:
+            self.error('no charts defined')
+            return False
+
+        for chart in charts:
+            chart_id = chart.get('id') if isinstance(chart, dict) else None
+            if not chart_id or not chart.get('lines'):
+                self.error('chart {0} has no id or no lines, skipping'.format(chart))
+                continue
+
+            chart_id = clean_name(str(chart_id))
+            options = chart.get('options') or dict()
+            definition = {
+                'options': [
+                    None,
+                    options.get('title', chart_id),
+                    options.get('units', ''),
+                    options.get('family', chart_id),
+                    options.get('context', 'jsonquery.' + chart_id),
+                    options.get('chart_type', 'line'),
+                ],
+                'lines': list(),
+            }
+
+            chart_lines = list()
+            for conf in chart['lines']:
+                if not isinstance(conf, dict) or not conf.get('id') or not conf.get('path'):
+                    self.error('line {0} of chart {1} has no id or no path, skipping'.format(conf, chart_id))
+                    continue
+                steps = parse_path(str(conf['path']))
+                if not steps:
+                    self.error('line {0} of chart {1}: invalid path "{2}"'.format(conf['id'], chart_id, conf['path']))
+                    continue
+                if conf.get('type', 'int') not in VALUE_TYPES:
+                    self.error('line {0} of chart {1}: unsupported type "{2}", must be one of {3}'.format(
+                        conf['id'], chart_id, conf.get('type'), VALUE_TYPES))
+                    continue
+
+                line = Line(chart_id, dict(conf, id=clean_name(str(conf['id']))), steps)
+                chart_lines.append(line)
+                if not line.dynamic:
+                    definition['lines'].append(line.dimension(line.id, line.name))
+                    self.collected_dims.add(line.id)
+
+            if not chart_lines:
+                continue
+            self.lines.extend(chart_lines)
+            self.order.append(chart_id)
+            self.definitions[chart_id] = definition
+
+        if not self.lines:
+            self.error('no valid lines in the charts')
+            return False
+        return True
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        try:
+            document = json.loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(self.url, error))
+            return None
+
+        data = dict()
+        for line in self.lines:
+            for keys, value in evaluate(document, line.steps):
+                value = to_number(value)
+                if value is None:
+                    continue
+                dim_id = line.id
+                if line.dynamic:
+                    dim_id = self.dynamic_dim(line, keys)
+                data[dim_id] = int(value * 100) if line.type == 'float' else int(value)
+
+        return data or None
+
+    def dynamic_dim(self, line, keys):
+        match = '_'.join(keys)
+        dim_id = '{0}_{1}'.format(line.id, clean_name(match))
+        if dim_id not in self.collected_dims:
+            self.collected_dims.add(dim_id)
+            dimension = line.dimension(dim_id, '{0}_{1}'.format(line.name, match))
+            self.add_dimension(line.chart, dimension)
+        return dim_id
@@ -0,0 +1,227 @@
+# -*- coding: utf-8 -*-
+# Description: jupyterhub netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.prometheus import parse, sum_by
+
+update_every = 5
+
+METRIC_TOTAL_USERS = 'jupyterhub_total_users'
+METRIC_ACTIVE_USERS = 'jupyterhub_active_users'
+METRIC_RUNNING_SERVERS = 'jupyterhub_running_servers'
+# histograms, in seconds
+METRIC_SPAWN = 'jupyterhub_server_spawn_duration_seconds'
+METRIC_STOP = 'jupyterhub_server_stop_seconds'
+METRIC_REQUEST = 'jupyterhub_request_duration_seconds'
+
+ACTIVE_PERIODS = (
+    '24h',
+    '7d',
+    '30d',
+)
+
+# status label of the spawn histogram
+SPAWN_STATUSES = (
+    ('success', 'success'),
+    ('failure', 'failure'),
+    ('already-pending', 'already_pending'),
+    ('throttled', 'throttled'),
+    ('too-many-users', 'too_many_users'),
+)
+
+# routes of the user servers in the proxy, all of them have a status label, success or failure
+PROXY_OPERATIONS = (
+    ('jupyterhub_proxy_add_duration_seconds', 'add'),
+    ('jupyterhub_proxy_delete_duration_seconds', 'delete'),
+    ('jupyterhub_proxy_poll_duration_seconds', 'poll'),
+)
+
+REQUEST_CLASSES = (
+    '1xx',
+    '2xx',
+    '3xx',
+    '4xx',
+    '5xx',
+)
+
+METRICS = (
+    METRIC_TOTAL_USERS,
+    METRIC_ACTIVE_USERS,
+    METRIC_RUNNING_SERVERS,
+) + tuple(m + suffix for m in (METRIC_SPAWN, METRIC_STOP, METRIC_REQUEST) + tuple(m for m, _ in PROXY_OPERATIONS)
+          for suffix in ('_sum', '_count'))
+
+ORDER = [
+    'users',
+    'servers',
+    'spawns',
+    'spawn_duration',
+    'stops',
+    'requests',
+    'request_latency',
+    'proxy_operations',
+    'proxy_failures',
+    'proxy_latency',
+]
+
+CHARTS = {
+    'users': {
+        'options': [None, 'Users', 'users', 'users', 'jupyterhub.users', 'line'],
+        'lines': [['users_active_' + p, 'active_' + p] for p in ACTIVE_PERIODS] + [
+            ['users_total', 'total'],
+        ]
+    },
+    'servers': {
+        'options': [None, 'Running Servers', 'servers', 'servers', 'jupyterhub.servers', 'line'],
+        'lines': [
+            ['servers_running', 'running'],
+        ]
+    },
+    'spawns': {
+        'options': [None, 'Server Spawns', 'spawns/s', 'servers', 'jupyterhub.spawns', 'stacked'],
+        'lines': [['spawns_' + s, s, 'incremental'] for _, s in SPAWN_STATUSES]
+    },
+    'spawn_duration': {
+        'options': [None, 'Server Spawns Average Duration', 'seconds', 'servers', 'jupyterhub.spawn_duration',
+                    'line'],
+        'lines': [
+            ['spawn_duration_success', 'success', 'absolute', 1, 1000],
+            ['spawn_duration_failure', 'failure', 'absolute', 1, 1000],
+        ]
+    },
+    'stops': {
+        'options': [None, 'Server Stops', 'stops/s', 'servers', 'jupyterhub.stops', 'stacked'],
+        'lines': [
+            ['stops_success', 'success', 'incremental'],
+            ['stops_failure', 'failure', 'incremental'],
+        ]
+    },
+    'requests': {
+        'options': [None, 'Hub Requests', 'requests/s', 'requests', 'jupyterhub.requests', 'stacked'],
+        'lines': [['requests_' + c, c, 'incremental'] for c in REQUEST_CLASSES]
+    },
+    'request_latency': {
+        'options': [None, 'Hub Requests Average Latency', 'milliseconds', 'requests', 'jupyterhub.request_latency',
+                    'line'],
+        'lines': [
+            ['request_latency', 'latency', 'absolute', 1, 1000],
+        ]
+    },
+    'proxy_operations': {
+        'options': [None, 'Proxy Route Operations', 'operations/s', 'proxy', 'jupyterhub.proxy_operations', 'line'],
+        'lines': [['proxy_{0}_success'.format(o), o, 'incremental'] for _, o in PROXY_OPERATIONS]
+    },
+    'proxy_failures': {
+        'options': [None, 'Failed Proxy Route Operations', 'operations/s', 'proxy', 'jupyterhub.proxy_failures',
+                    'line'],
+        'lines': [['proxy_{0}_failure'.format(o), o, 'incremental'] for _, o in PROXY_OPERATIONS]
+    },
+    'proxy_latency': {
+        'options': [None, 'Proxy Route Operations Average Latency', 'milliseconds', 'proxy',
+                    'jupyterhub.proxy_latency', 'line'],
+        'lines': [['proxy_{0}_latency'.format(o), o, 'absolute', 1, 1000] for _, o in PROXY_OPERATIONS]
+    },
+}
+
+
+def delta_average(previous, key, total, count, mult=1):
+    """
+    Average of the events counted since the last run, 0 when there are none.
+    :param previous: <dict> key -> (total, count) of the last run, updated
+    """
+    prev_total, prev_count = previous.get(key, (total, count))
+    previous[key] = (total, count)
+    if count > prev_count:
+        return int((total - prev_total) * mult / (count - prev_count))
+    return 0
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8000/hub/metrics')
+        api_token = self.configuration.get('api_token')
+        if api_token:
+            # the metrics require authentication unless the hub runs with authenticate_prometheus = False
+            self.header = dict(self.header or dict(), Authorization='token {0}'.format(api_token))
+        self.previous = dict()
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        samples = parse(raw, names=METRICS)
+        if not samples:
+            self.error('no jupyterhub metrics found at {0}, is the api_token set?'.format(self.url))
+            return None
+
+        data = dict()
+        data.update(self.users_data(samples))
+        data.update(self.servers_data(samples))
+        data.update(self.requests_data(samples))
+        data.update(self.proxy_data(samples))
+        return data
+
+    @staticmethod
+    def users_data(samples):
+        data = dict()
+        total = sum_by(samples, METRIC_TOTAL_USERS)
+        if total:
+            data['users_total'] = int(total[None])
+        # not exported by older versions
+        active = sum_by(samples, METRIC_ACTIVE_USERS, 'period')
+        for period in ACTIVE_PERIODS:
+            if period in active:
+                data['users_active_' + period] = int(active[period])
+        return data
+
+    def servers_data(self, samples):
+        data = dict()
+        running = sum_by(samples, METRIC_RUNNING_SERVERS)
+        if running:
+            data['servers_running'] = int(running[None])
+
+        # the histograms are exported after the first observation only
+        spawns = sum_by(samples, METRIC_SPAWN + '_count', 'status')
+        spawns_total = sum_by(samples, METRIC_SPAWN + '_sum', 'status')
+        for status, dimension in SPAWN_STATUSES:
+            data['spawns_' + dimension] = int(spawns.get(status, 0))
+        for status in ('success', 'failure'):
+            # milliseconds
+            data['spawn_duration_' + status] = delta_average(
+                self.previous, 'spawn_' + status, spawns_total.get(status, 0), spawns.get(status, 0), 1e3)
+
+        stops = sum_by(samples, METRIC_STOP + '_count', 'status')
+        for status in ('success', 'failure'):
+            data['stops_' + status] = int(stops.get(status, 0))
+        return data
+
+    def requests_data(self, samples):
+        requests = sum_by(samples, METRIC_REQUEST + '_count', 'code')
+        data = dict(('requests_' + c, 0) for c in REQUEST_CLASSES)
+        for code, count in requests.items():
+            key = 'requests_{0}xx'.format(code[:1])
+            if key in data:
+                data[key] += int(count)
+
+        # microseconds
+        data['request_latency'] = delta_average(
+            self.previous, 'request', sum_by(samples, METRIC_REQUEST + '_sum').get(None, 0),
+            sum(requests.values()), 1e6)
+        return data
+
+    def proxy_data(self, samples):
+        data = dict()
+        for metric, operation in PROXY_OPERATIONS:
+            counts = sum_by(samples, metric + '_count', 'status')
+            for status in ('success', 'failure'):
+                data['proxy_{0}_{1}'.format(operation, status)] = int(counts.get(status, 0))
+            # microseconds
+            data['proxy_{0}_latency'.format(operation)] = delta_average(
+                self.previous, 'proxy_' + operation, sum_by(samples, metric + '_sum').get(None, 0),
+                sum(counts.values()), 1e6)
+        return data
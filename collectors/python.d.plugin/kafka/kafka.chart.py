@@ -0,0 +1,333 @@
+# -*- coding: utf-8 -*-
+# Description: kafka broker netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+from copy import deepcopy
+from fnmatch import fnmatch
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+MBEAN_REPLICA_MANAGER = 'kafka.server:type=ReplicaManager,*'
+MBEAN_CONTROLLER = 'kafka.controller:type=KafkaController,*'
+MBEAN_REQUEST_HANDLERS = 'kafka.server:type=KafkaRequestHandlerPool,name=RequestHandlerAvgIdlePercent'
+MBEAN_NETWORK_PROCESSORS = 'kafka.network:type=SocketServer,name=NetworkProcessorAvgIdlePercent'
+MBEAN_REQUESTS = 'kafka.network:type=RequestMetrics,name=RequestsPerSec,*'
+MBEAN_REQUESTS_TIME = 'kafka.network:type=RequestMetrics,name=TotalTimeMs,*'
+MBEAN_TOPICS = 'kafka.server:type=BrokerTopicMetrics,*'
+
+# the attributes missing on some mbeans of the patterns are ignored
+READ_CONFIG = {'ignoreErrors': True}
+
+# a single jolokia bulk request
+READ_REQUESTS = [
+    {'type': 'read', 'mbean': MBEAN_REPLICA_MANAGER, 'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_CONTROLLER, 'attribute': ['Value'], 'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_REQUEST_HANDLERS, 'attribute': ['OneMinuteRate'], 'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_NETWORK_PROCESSORS, 'attribute': ['Value'], 'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_REQUESTS, 'attribute': ['Count'], 'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_REQUESTS_TIME, 'attribute': ['50thPercentile', '99thPercentile'],
+     'config': READ_CONFIG},
+    {'type': 'read', 'mbean': MBEAN_TOPICS, 'attribute': ['Count'], 'config': READ_CONFIG},
+]
+
+REQUESTS = (
+    ('Produce', 'produce'),
+    ('FetchConsumer', 'fetch_consumer'),
+    ('FetchFollower', 'fetch_follower'),
+)
+
+# BrokerTopicMetrics meters, per topic and for all the topics without a topic key
+TOPIC_METRICS = (
+    ('BytesInPerSec', 'bytes_in'),
+    ('BytesOutPerSec', 'bytes_out'),
+    ('MessagesInPerSec', 'messages_in'),
+)
+
+ORDER = [
+    'partitions_health',
+    'partitions',
+    'isr',
+    'controller',
+    'idle',
+    'requests',
+    'produce_latency',
+    'fetch_consumer_latency',
+    'fetch_follower_latency',
+    'bytes',
+    'messages',
+]
+
+CHARTS = {
+    'partitions_health': {
+        'options': [None, 'Unhealthy Partitions', 'partitions', 'partitions', 'kafka.partitions_health', 'line'],
+        'lines': [
+            ['under_replicated_partitions', 'under_replicated'],
+            ['under_min_isr_partitions', 'under_min_isr'],
+            ['offline_partitions', 'offline'],
+        ]
+    },
+    'partitions': {
+        'options': [None, 'Broker Partitions', 'partitions', 'partitions', 'kafka.partitions', 'line'],
+        'lines': [
+            ['partitions', 'partitions'],
+            ['leaders', 'leaders'],
+        ]
+    },
+    'isr': {
+        'options': [None, 'In-Sync Replicas Changes', 'events/s', 'partitions', 'kafka.isr', 'line'],
+        'lines': [
+            ['isr_shrinks', 'shrinks', 'incremental'],
+            ['isr_expands', 'expands', 'incremental'],
+        ]
+    },
+    'controller': {
+        'options': [None, 'Active Controller', 'status', 'partitions', 'kafka.controller', 'line'],
+        'lines': [
+            ['active_controller', 'active'],
+        ]
+    },
+    'idle': {
+        'options': [None, 'Threads Idle', 'percentage', 'requests', 'kafka.idle', 'line'],
+        'lines': [
+            ['request_handlers_idle', 'request_handlers', 'absolute', 1, 100],
+            ['network_processors_idle', 'network_processors', 'absolute', 1, 100],
+        ]
+    },
+    'requests': {
+        'options': [None, 'Requests', 'requests/s', 'requests', 'kafka.requests', 'line'],
+        'lines': [['requests_' + r, r, 'incremental'] for _, r in REQUESTS]
+    },
+    'produce_latency': {
+        'options': [None, 'Produce Requests Total Time', 'milliseconds', 'requests', 'kafka.produce_latency',
+                    'line'],
+        'lines': [
+            ['produce_p50', 'p50', 'absolute', 1, 1000],
+            ['produce_p99', 'p99', 'absolute', 1, 1000],
+        ]
+    },
+    'fetch_consumer_latency': {
+        'options': [None, 'Consumer Fetch Requests Total Time', 'milliseconds', 'requests',
+                    'kafka.fetch_consumer_latency', 'line'],
+        'lines': [
+            ['fetch_consumer_p50', 'p50', 'absolute', 1, 1000],
+            ['fetch_consumer_p99', 'p99', 'absolute', 1, 1000],
+        ]
+    },
+    'fetch_follower_latency': {
+        'options': [None, 'Follower Fetch Requests Total Time', 'milliseconds', 'requests',
+                    'kafka.fetch_follower_latency', 'line'],
+        'lines': [
+            ['fetch_follower_p50', 'p50', 'absolute', 1, 1000],
+            ['fetch_follower_p99', 'p99', 'absolute', 1, 1000],
+        ]
+    },
+    'bytes': {
+        'options': [None, 'Broker Traffic', 'KiB/s', 'traffic', 'kafka.bytes', 'area'],
+        'lines': [
+            ['bytes_in', 'in', 'incremental', 1, 1024],
+            ['bytes_out', 'out', 'incremental', -1, 1024],
+        ]
+    },
+    'messages': {
+        'options': [None, 'Incoming Messages', 'messages/s', 'traffic', 'kafka.messages', 'line'],
+        'lines': [
+            ['messages_in', 'in', 'incremental'],
+        ]
+    },
+}
+
+
+def topic_charts(topic_id, topic):
+    order = [
+        'topic_{0}_bytes'.format(topic_id),
+        'topic_{0}_messages'.format(topic_id),
+    ]
+    family = 'topic ' + topic
+    charts = {
+        order[0]: {
+            'options': [None, 'Topic Traffic', 'KiB/s', family, 'kafka.topic_bytes', 'area'],
+            'lines': [
+                ['topic_{0}_bytes_in'.format(topic_id), 'in', 'incremental', 1, 1024],
+                ['topic_{0}_bytes_out'.format(topic_id), 'out', 'incremental', -1, 1024],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Topic Incoming Messages', 'messages/s', family, 'kafka.topic_messages', 'line'],
+            'lines': [
+                ['topic_{0}_messages_in'.format(topic_id), 'in', 'incremental'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def parse_mbean(name):
+    """
+    :param name: <str> 'domain:key=value,key=value', jolokia sorts the keys
+    :return: <dict> of the key properties
+    """
+    _, _, properties = name.partition(':')
+    return dict(p.split('=', 1) for p in properties.split(',') if '=' in p)
+
+
+class TopicSelector:
+    """
+    Shell wildcard patterns, patterns starting with '!' are negative, the first matching pattern wins.
+    Topics not matching any pattern are selected only if all the patterns are negative.
+    """
+
+    def __init__(self, patterns):
+        self.patterns = list()
+        for pattern in patterns or list():
+            pattern = str(pattern)
+            self.patterns.append((not pattern.startswith('!'), pattern.lstrip('!')))
+        self.default = not any(p[0] for p in self.patterns)
+
+    def selected(self, topic):
+        for positive, pattern in self.patterns:
+            if fnmatch(topic, pattern):
+                return positive
+        return self.default
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8778/jolokia').rstrip('/') + '/'
+        self.method = 'POST'
+        self.body = json.dumps(READ_REQUESTS)
+        self.do_topics = self.configuration.get('topic_charts', True)
+        self.selector = TopicSelector(self.configuration.get('topics'))
+        self.collected_topics = dict()
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        try:
+            responses = json.loads(raw)
+        except ValueError as error:
+            self.error('invalid jolokia response: {0}'.format(error))
+            return None
+
+        # mbean -> attributes, the single mbean reads are keyed by their request
+        mbeans = dict()
+        for response in responses:
+            if response.get('status') != 200:
+                # 404 for the mbeans of other kafka versions or roles
+                self.debug('jolokia {0}: {1}'.format(response.get('request', dict()).get('mbean'),
+                                                     response.get('error')))
+                continue
+            mbean = response['request']['mbean']
+            if mbean.endswith('*'):
+                mbeans.update(response['value'])
+            else:
+                mbeans[mbean] = response['value']
+
+        if not mbeans:
+            self.error('no kafka mbeans found, is the jolokia agent attached to a kafka broker?')
+            return None
+
+        data = dict()
+        data.update(self.broker_data(mbeans))
+        data.update(self.requests_data(mbeans))
+        data.update(self.topics_data(mbeans))
+        return data
+
+    @staticmethod
+    def broker_data(mbeans):
+        data = dict()
+        for mbean, attributes in mbeans.items():
+            properties = parse_mbean(mbean)
+            name = properties.get('name')
+            if properties.get('type') == 'ReplicaManager':
+                for metric, key in (('UnderReplicatedPartitions', 'under_replicated_partitions'),
+                                    ('UnderMinIsrPartitionCount', 'under_min_isr_partitions'),
+                                    ('PartitionCount', 'partitions'),
+                                    ('LeaderCount', 'leaders')):
+                    if name == metric:
+                        data[key] = attributes.get('Value', 0)
+                for metric, key in (('IsrShrinksPerSec', 'isr_shrinks'),
+                                    ('IsrExpandsPerSec', 'isr_expands')):
+                    if name == metric:
+                        data[key] = attributes.get('Count', 0)
+            elif properties.get('type') == 'KafkaController':
+                # the offline partitions are reported by the active controller only
+                if name == 'ActiveControllerCount':
+                    data['active_controller'] = attributes.get('Value', 0)
+                elif name == 'OfflinePartitionsCount':
+                    data['offline_partitions'] = attributes.get('Value', 0)
+
+        # 0.0 - 1.0
+        handlers = mbeans.get(MBEAN_REQUEST_HANDLERS)
+        if handlers:
+            data['request_handlers_idle'] = int(handlers.get('OneMinuteRate', 0) * 10000)
+        processors = mbeans.get(MBEAN_NETWORK_PROCESSORS)
+        if processors:
+            data['network_processors_idle'] = int(processors.get('Value', 0) * 10000)
+        return data
+
+    @staticmethod
+    def requests_data(mbeans):
+        data = dict()
+        names = dict(REQUESTS)
+        for mbean, attributes in mbeans.items():
+            properties = parse_mbean(mbean)
+            if properties.get('type') != 'RequestMetrics':
+                continue
+            request = names.get(properties.get('request'))
+            if not request:
+                continue
+            if properties.get('name') == 'RequestsPerSec':
+                # one mbean per api version since kafka 2.0
+                key = 'requests_' + request
+                data[key] = data.get(key, 0) + attributes.get('Count', 0)
+            elif properties.get('name') == 'TotalTimeMs':
+                data[request + '_p50'] = int(attributes.get('50thPercentile', 0) * 1000)
+                data[request + '_p99'] = int(attributes.get('99thPercentile', 0) * 1000)
+        return data
+
+    def topics_data(self, mbeans):
+        data = dict()
+        names = dict(TOPIC_METRICS)
+        topics = set()
+        for mbean, attributes in mbeans.items():
+            properties = parse_mbean(mbean)
+            if properties.get('type') != 'BrokerTopicMetrics':
+                continue
+            metric = names.get(properties.get('name'))
+            if not metric:
+                continue
+
+            topic = properties.get('topic')
+            if topic is None:
+                data[metric] = attributes.get('Count', 0)
+                continue
+            if not self.do_topics or not self.selector.selected(topic):
+                continue
+
+            topic_id = clean_name(topic)
+            topics.add(topic_id)
+            if topic_id not in self.collected_topics:
+                self.add_topic_charts(topic_id, topic)
+            data['topic_{0}_{1}'.format(topic_id, metric)] = attributes.get('Count', 0)
+
+        # the metrics of a topic are removed when the topic is deleted
+        for topic_id in set(self.collected_topics) - topics:
+            self.remove_topic_charts(topic_id)
+        return data
+
+    def add_topic_charts(self, topic_id, topic):
+        order, charts = topic_charts(topic_id, topic)
+        self.collected_topics[topic_id] = order
+        self.add_charts(order, charts)
+
+    def remove_topic_charts(self, topic_id):
+        self.remove_charts(self.collected_topics.pop(topic_id))
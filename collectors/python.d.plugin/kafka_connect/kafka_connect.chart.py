@@ -0,0 +1,200 @@
+# -*- coding: utf-8 -*-
+# Description: kafka connect netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+from json import loads
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+API_CONNECTORS = 'connectors?expand=status'
+
+STATES = (
+    'RUNNING',
+    'PAUSED',
+    'STOPPED',
+    'FAILED',
+    'RESTARTING',
+    'UNASSIGNED',
+)
+
+ORDER = [
+    'connectors',
+    'tasks',
+    'task_events',
+]
+
+CHARTS = {
+    'connectors': {
+        'options': [None, 'Connectors By State', 'connectors', 'cluster', 'kafka_connect.connectors', 'stacked'],
+        'lines': [['connectors_' + s.lower(), s.lower()] for s in STATES]
+    },
+    'tasks': {
+        'options': [None, 'Tasks By State', 'tasks', 'cluster', 'kafka_connect.tasks', 'stacked'],
+        'lines': [['tasks_' + s.lower(), s.lower()] for s in STATES]
+    },
+    'task_events': {
+        'options': [None, 'Task Restarts And Reassignments', 'tasks', 'cluster', 'kafka_connect.task_events',
+                    'line'],
+        'lines': [
+            ['task_restarts', 'restarts'],
+            ['task_reassignments', 'reassignments'],
+        ]
+    },
+}
+
+
+def connector_charts(connector_id, connector):
+    order = [
+        'connector_{0}_state'.format(connector_id),
+        'connector_{0}_tasks'.format(connector_id),
+        'connector_{0}_task_events'.format(connector_id),
+    ]
+    family = 'connector ' + connector
+    charts = {
+        order[0]: {
+            'options': [None, 'Connector State', 'state', family, 'kafka_connect.connector_state', 'line'],
+            'lines': [['connector_{0}_{1}'.format(connector_id, s.lower()), s.lower()] for s in STATES]
+        },
+        order[1]: {
+            'options': [None, 'Tasks By State', 'tasks', family, 'kafka_connect.connector_tasks', 'stacked'],
+            'lines': [['connector_{0}_tasks_{1}'.format(connector_id, s.lower()), s.lower()] for s in STATES]
+        },
+        order[2]: {
+            'options': [None, 'Task Restarts And Reassignments', 'tasks', family,
+                        'kafka_connect.connector_task_events', 'line'],
+            'lines': [
+                ['connector_{0}_task_restarts'.format(connector_id), 'restarts'],
+                ['connector_{0}_task_reassignments'.format(connector_id), 'reassignments'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def task_charts(connector_id, connector, task):
+    order = [
+        'connector_{0}_task_{1}_state'.format(connector_id, task),
+    ]
+    charts = {
+        order[0]: {
+            'options': [None, 'Task {0} State'.format(task), 'state', 'connector ' + connector,
+                        'kafka_connect.task_state', 'line'],
+            'lines': [['connector_{0}_task_{1}_{2}'.format(connector_id, task, s.lower()), s.lower()]
+                      for s in STATES]
+        },
+    }
+    return order, charts
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8083').rstrip('/')
+        self.do_connectors = self.configuration.get('connector_charts', True)
+        self.do_tasks = self.configuration.get('task_charts', True)
+        # chart id -> <list> of its charts
+        self.collected = dict()
+        # (connector id, task id) -> (state, worker id) of the last run
+        self.tasks = dict()
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_CONNECTORS))
+        if not raw:
+            return None
+
+        try:
+            connectors = loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(API_CONNECTORS, error))
+            return None
+
+        if not isinstance(connectors, dict):
+            self.error('{0}: unexpected response, expand=status needs Kafka 2.3 or newer'.format(API_CONNECTORS))
+            return None
+
+        data = {
+            'task_restarts': 0,
+            'task_reassignments': 0,
+        }
+        for state in STATES:
+            data['connectors_' + state.lower()] = 0
+            data['tasks_' + state.lower()] = 0
+
+        seen = set()
+        tasks = dict()
+        for name, connector in connectors.items():
+            status = connector.get('status') or dict()
+            state = (status.get('connector') or dict()).get('state')
+            if state in STATES:
+                data['connectors_' + state.lower()] += 1
+
+            connector_id = clean_name(name)
+            prefix = 'connector_{0}_'.format(connector_id)
+            if self.do_connectors:
+                seen.add(connector_id)
+                if connector_id not in self.collected:
+                    self.add_collected_charts(connector_id, *connector_charts(connector_id, name))
+                for s in STATES:
+                    data[prefix + s.lower()] = int(state == s)
+                    data[prefix + 'tasks_' + s.lower()] = 0
+                data[prefix + 'task_restarts'] = 0
+                data[prefix + 'task_reassignments'] = 0
+
+            for task in status.get('tasks') or list():
+                task_state, task_id, worker = task.get('state'), task.get('id'), task.get('worker_id')
+                if task_state not in STATES:
+                    continue
+                data['tasks_' + task_state.lower()] += 1
+
+                restarted, reassigned = self.task_events((connector_id, task_id), task_state, worker)
+                tasks[(connector_id, task_id)] = (task_state, worker)
+                data['task_restarts'] += restarted
+                data['task_reassignments'] += reassigned
+
+                if not self.do_connectors:
+                    continue
+                data[prefix + 'tasks_' + task_state.lower()] += 1
+                data[prefix + 'task_restarts'] += restarted
+                data[prefix + 'task_reassignments'] += reassigned
+
+                if not self.do_tasks:
+                    continue
+                chart_id = '{0}_task_{1}'.format(connector_id, task_id)
+                seen.add(chart_id)
+                if chart_id not in self.collected:
+                    self.add_collected_charts(chart_id, *task_charts(connector_id, name, task_id))
+                for s in STATES:
+                    data['connector_{0}_{1}'.format(chart_id, s.lower())] = int(task_state == s)
+
+        self.tasks = tasks
+        for chart_id in set(self.collected) - seen:
+            self.remove_collected_charts(chart_id)
+
+        return data
+
+    def task_events(self, key, state, worker):
+        """
+        Compare a task with the last run, the first run is the baseline.
+        :return: <tuple> restarted, reassigned to another worker (a rebalance)
+        """
+        if key not in self.tasks:
+            return 0, 0
+        prev_state, prev_worker = self.tasks[key]
+        # restarted between the runs if it failed and is running again
+        restarted = (state == 'RESTARTING' and prev_state != 'RESTARTING') or \
+                    (prev_state == 'FAILED' and state == 'RUNNING')
+        return int(restarted), int(worker != prev_worker)
+
+    def add_collected_charts(self, chart_id, order, charts):
+        self.collected[chart_id] = order
+        self.add_charts(order, charts)
+
+    def remove_collected_charts(self, chart_id):
+        # deleted connectors, and tasks of connectors scaled down
+        self.remove_charts(self.collected.pop(chart_id))
@@ -0,0 +1,170 @@
+# -*- coding: utf-8 -*-
+# Description: listeners netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import os
+import re
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import clean_name, find_binary
+
+update_every = 10
+
+SS = 'ss'
+
+UNKNOWN = 'unknown'
+
+LOCAL_PORT_RANGE = '/proc/sys/net/ipv4/ip_local_port_range'
+DEFAULT_LOCAL_PORT_RANGE = (32768, 60999)
+
+ORDER = [
+    'sockets',
+    'processes',
+    'changes',
+]
+
+CHARTS = {
+    'sockets': {
+        'options': [None, 'Listening Sockets', 'sockets', 'sockets', 'listeners.sockets', 'stacked'],
+        'lines': [
+            ['tcp', 'tcp'],
+            ['udp', 'udp'],
+        ]
+    },
+    'processes': {
+        'options': [None, 'Listening Sockets By Process', 'sockets', 'sockets', 'listeners.processes', 'stacked'],
+        'lines': []
+    },
+    'changes': {
+        'options': [None, 'Listening Sockets Changes', 'sockets', 'changes', 'listeners.changes', 'line'],
+        'lines': [
+            ['new', 'new'],
+            ['closed', 'closed', 'absolute', -1, 1],
+        ]
+    },
+}
+
+RE_PROCESS = re.compile(r'\(\("([^"]+)",pid=(\d+)')
+
+
+def read_local_port_range():
+    try:
+        with open(LOCAL_PORT_RANGE) as f:
+            low, high = f.read().split()
+        return int(low), int(high)
+    except (IOError, OSError, ValueError):
+        return DEFAULT_LOCAL_PORT_RANGE
+
+
+def local_port(address):
+    try:
+        return int(address.rsplit(':', 1)[1])
+    except (IndexError, ValueError):
+        return None
+
+
+def parse_listeners(raw, ephemeral):
+    """
+    Parse 'ss -tulnpH' output into a set of (protocol, local address, process) tuples.
+    """
+    listeners = set()
+    for line in raw:
+        parts = line.split(None, 6)
+        if len(parts) < 6 or parts[0] not in ('tcp', 'udp'):
+            continue
+
+        # every bound udp socket is listed, the ones of the clients (resolvers, ntp, ...) have an ephemeral port
+        if parts[0] == 'udp':
+            port = local_port(parts[4])
+            if port is not None and ephemeral[0] <= port <= ephemeral[1]:
+                continue
+
+        process = UNKNOWN
+        if len(parts) == 7:
+            match = RE_PROCESS.search(parts[6])
+            if match:
+                process = match.group(1)
+
+        listeners.add((parts[0], parts[4], process))
+    return listeners
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.ss = self.configuration.get('ss_path')
+        self.use_sudo = self.configuration.get('use_sudo', False)
+        self.log_changes = self.configuration.get('log_changes', True)
+        self.command = list()
+        self.listeners = None
+        self.processes = set()
+        self.ephemeral = DEFAULT_LOCAL_PORT_RANGE
+
+    def check(self):
+        self.ss = self.ss or find_binary(SS)
+        if not self.ss:
+            self.error('can\'t locate "{0}" binary'.format(SS))
+            return False
+
+        if self.use_sudo:
+            sudo = find_binary('sudo')
+            if not sudo:
+                self.error('can\'t locate "sudo" binary')
+                return False
+
+            allowed = self._get_raw_data(command=[sudo, '-n', '-l', self.ss])
+            if not allowed or allowed[0].strip() != os.path.realpath(self.ss):
+                self.error('not allowed to run sudo for command {0}'.format(self.ss))
+                return False
+
+            self.command = [sudo, '-n']
+
+        self.command.extend([self.ss, '-tulnpH'])
+        self.ephemeral = read_local_port_range()
+
+        return self._get_raw_data() is not None
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if raw is None:
+            return None
+
+        listeners = parse_listeners(raw, self.ephemeral)
+
+        data = {
+            'tcp': 0,
+            'udp': 0,
+            'new': 0,
+            'closed': 0,
+        }
+
+        for process in self.processes:
+            data['process_' + process] = 0
+
+        for protocol, local, process in listeners:
+            data[protocol] += 1
+
+            process = clean_name(process)
+            if process not in self.processes:
+                self.processes.add(process)
+                self.add_dimension('processes', ['process_' + process, process])
+                data['process_' + process] = 0
+            data['process_' + process] += 1
+
+        # the first collection is the baseline, only later changes are reported
+        if self.listeners is not None:
+            new = listeners - self.listeners
+            closed = self.listeners - listeners
+            data['new'], data['closed'] = len(new), len(closed)
+
+            if self.log_changes:
+                for protocol, local, process in sorted(new):
+                    self.info('new listener: {0} {1} ({2})'.format(protocol, local, process))
+                for protocol, local, process in sorted(closed):
+                    self.info('closed listener: {0} {1} ({2})'.format(protocol, local, process))
+
+        self.listeners = listeners
+
+        return data
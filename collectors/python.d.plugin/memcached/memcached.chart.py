@@ -3,6 +3,8 @@
 # Author: Pawel Krupa (paulfantom)
 # SPDX-License-Identifier: GPL-3.0-or-later
 
+from copy import deepcopy
+
 from bases.FrameworkServices.SocketService import SocketService
 
 ORDER = [
@@ -22,6 +24,59 @@ ORDER = [
     'touch_rate',
 ]
 
+SLABS_ORDER = [
+    'slabs_memory',
+    'slabs_pages',
+    'slabs_items',
+    'slabs_evictions',
+    'slabs_outofmemory',
+    'lru_items',
+]
+
+SLABS_CHARTS = {
+    'slabs_memory': {
+        'options': [None, 'Memory Requested By Slab Class', 'MiB', 'slabs', 'memcached.slabs_memory', 'stacked'],
+        'lines': []
+    },
+    'slabs_pages': {
+        'options': [None, 'Pages Allocated To Slab Class', 'pages', 'slabs', 'memcached.slabs_pages', 'stacked'],
+        'lines': []
+    },
+    'slabs_items': {
+        'options': [None, 'Items By Slab Class', 'items', 'slabs', 'memcached.slabs_items', 'stacked'],
+        'lines': []
+    },
+    'slabs_evictions': {
+        'options': [None, 'Evictions By Slab Class', 'items/s', 'slabs', 'memcached.slabs_evictions', 'stacked'],
+        'lines': []
+    },
+    'slabs_outofmemory': {
+        'options': [None, 'Out Of Memory Errors By Slab Class', 'errors/s', 'slabs', 'memcached.slabs_outofmemory',
+                    'stacked'],
+        'lines': []
+    },
+    'lru_items': {
+        'options': [None, 'Items By LRU Segment', 'items', 'slabs', 'memcached.lru_items', 'stacked'],
+        'lines': [
+            ['lru_number_hot', 'hot'],
+            ['lru_number_warm', 'warm'],
+            ['lru_number_cold', 'cold'],
+            ['lru_number_temp', 'temp'],
+        ]
+    },
+}
+
+# chart: (stat, dimension algorithm, dimension divisor)
+SLABS_STATS = {
+    'slabs_memory': ('mem_requested', 'absolute', 1 << 20),
+    'slabs_pages': ('total_pages', 'absolute', 1),
+    'slabs_items': ('number', 'absolute', 1),
+    'slabs_evictions': ('evicted', 'incremental', 1),
+    'slabs_outofmemory': ('outofmemory', 'incremental', 1),
+}
+
+LRU_SEGMENTS = ('number_hot', 'number_warm', 'number_cold', 'number_temp')
+
 CHARTS = {
     'cache': {
         'options': [None, 'Cache Size', 'MiB', 'cache', 'memcached.cache', 'stacked'],
@@ -133,6 +188,12 @@ class Service(SocketService):
         self.port = 11211
         self._keep_alive = True
         self.unix_socket = None
+        self.collect_slabs = self.configuration.get('collect_slabs', False)
+        self.collected_slabs = set()
+        if self.collect_slabs:
+            self.order = ORDER + SLABS_ORDER
+            self.definitions = dict(CHARTS)
+            self.definitions.update(deepcopy(SLABS_CHARTS))
 
     def _get_data(self):
         """
@@ -175,8 +236,62 @@ class Service(SocketService):
         except (KeyError, ValueError, TypeError):
             pass
 
+        if self.collect_slabs:
+            data.update(self.get_slabs_data())
+
         return data
 
+    def get_stats(self, command):
+        response = self._get_raw_data(request='stats {0}\r\n'.format(command).encode())
+        if not response or response.startswith('ERROR'):
+            return dict()
+
+        stats = dict()
+        for line in response.split('\n'):
+            parts = line.split()
+            if len(parts) == 3 and parts[0] == 'STAT':
+                stats[parts[1]] = parts[2]
+        return stats
+
+    def get_slabs_data(self):
+        """
+        'stats slabs' returns '<class>:<stat>' and 'stats items' returns 'items:<class>:<stat>'
+        :return: dict
+        """
+        slabs = dict()
+        for key, value in self.get_stats('slabs').items():
+            slab_class, _, stat = key.partition(':')
+            if slab_class.isdigit():
+                slabs.setdefault(slab_class, dict())[stat] = value
+
+        for key, value in self.get_stats('items').items():
+            parts = key.split(':')
+            if len(parts) == 3 and parts[1].isdigit():
+                slabs.setdefault(parts[1], dict())[parts[2]] = value
+
+        data = dict(('lru_' + segment, 0) for segment in LRU_SEGMENTS)
+        for slab_class, stats in slabs.items():
+            if slab_class not in self.collected_slabs:
+                self.collected_slabs.add(slab_class)
+                self.add_slab_dimensions(slab_class, stats.get('chunk_size'))
+
+            for stat, _, _ in SLABS_STATS.values():
+                data['slab_{0}_{1}'.format(slab_class, stat)] = stats.get(stat, 0)
+
+            for segment in LRU_SEGMENTS:
+                try:
+                    data['lru_' + segment] += int(stats.get(segment, 0))
+                except ValueError:
+                    continue
+
+        return data
+
+    def add_slab_dimensions(self, slab_class, chunk_size):
+        name = '{0} ({1}B)'.format(slab_class, chunk_size) if chunk_size else slab_class
+        for chart, (stat, algorithm, divisor) in SLABS_STATS.items():
+            dimension = ['slab_{0}_{1}'.format(slab_class, stat), name, algorithm, 1, divisor]
+            self.add_dimension(chart, dimension)
+
     def _check_raw_data(self, data):
         if data.endswith('END\r\n'):
             self.debug('received full response from memcached')
@@ -0,0 +1,276 @@
+# -*- coding: utf-8 -*-
+# Description: minio netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+from fnmatch import fnmatch
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse, sum_by
+
+update_every = 10
+
+API_CLUSTER_HEALTH = 'minio/metrics/v3/cluster/health'
+API_ERASURE_SETS = 'minio/metrics/v3/cluster/erasure-set'
+API_REQUESTS = 'minio/metrics/v3/api/requests'
+API_BUCKETS_USAGE = 'minio/metrics/v3/cluster/usage/buckets'
+
+HEALTH_METRICS = (
+    ('minio_cluster_health_drives_online_count', 'drives_online'),
+    ('minio_cluster_health_drives_offline_count', 'drives_offline'),
+    ('minio_cluster_health_nodes_online_count', 'nodes_online'),
+    ('minio_cluster_health_nodes_offline_count', 'nodes_offline'),
+    ('minio_cluster_health_capacity_usable_total_bytes', 'capacity_total'),
+    ('minio_cluster_health_capacity_usable_free_bytes', 'capacity_free'),
+)
+
+METRIC_SET_HEALTH = 'minio_cluster_erasure_set_health'
+METRIC_SET_HEALING_DRIVES = 'minio_cluster_erasure_set_healing_drives_count'
+
+METRIC_REQUESTS = 'minio_api_requests_total'
+METRIC_ERRORS = 'minio_api_requests_errors_total'
+METRIC_4XX_ERRORS = 'minio_api_requests_4xx_errors_total'
+METRIC_5XX_ERRORS = 'minio_api_requests_5xx_errors_total'
+METRIC_INFLIGHT = 'minio_api_requests_inflight_total'
+
+METRIC_BUCKET_SIZE = 'minio_cluster_usage_buckets_total_bytes'
+METRIC_BUCKET_OBJECTS = 'minio_cluster_usage_buckets_objects_count'
+
+ORDER = [
+    'drives',
+    'nodes',
+    'capacity',
+    'erasure_sets',
+    'healing',
+    'requests',
+    'errors',
+    'errors_by_class',
+    'inflight',
+]
+
+CHARTS = {
+    'drives': {
+        'options': [None, 'Drives', 'drives', 'cluster', 'minio.drives', 'stacked'],
+        'lines': [
+            ['drives_online', 'online'],
+            ['drives_offline', 'offline'],
+        ]
+    },
+    'nodes': {
+        'options': [None, 'Nodes', 'nodes', 'cluster', 'minio.nodes', 'stacked'],
+        'lines': [
+            ['nodes_online', 'online'],
+            ['nodes_offline', 'offline'],
+        ]
+    },
+    'capacity': {
+        'options': [None, 'Usable Capacity', 'GiB', 'cluster', 'minio.capacity', 'stacked'],
+        'lines': [
+            ['capacity_free', 'free', 'absolute', 1, 1 << 30],
+            ['capacity_used', 'used', 'absolute', 1, 1 << 30],
+        ]
+    },
+    'erasure_sets': {
+        'options': [None, 'Erasure Sets', 'sets', 'healing', 'minio.erasure_sets', 'stacked'],
+        'lines': [
+            ['erasure_sets_healthy', 'healthy'],
+            ['erasure_sets_unhealthy', 'unhealthy'],
+        ]
+    },
+    'healing': {
+        'options': [None, 'Healing Drives', 'drives', 'healing', 'minio.healing', 'line'],
+        'lines': [
+            ['drives_healing', 'healing'],
+        ]
+    },
+    'requests': {
+        'options': [None, 'S3 Requests', 'requests/s', 'requests', 'minio.requests', 'stacked'],
+        'lines': []
+    },
+    'errors': {
+        'options': [None, 'S3 Request Errors', 'errors/s', 'requests', 'minio.errors', 'stacked'],
+        'lines': []
+    },
+    'errors_by_class': {
+        'options': [None, 'S3 Request Errors By Class', 'errors/s', 'requests', 'minio.errors_by_class', 'line'],
+        'lines': [
+            ['errors_4xx', '4xx', 'incremental'],
+            ['errors_5xx', '5xx', 'incremental'],
+        ]
+    },
+    'inflight': {
+        'options': [None, 'S3 Requests In Flight', 'requests', 'requests', 'minio.inflight', 'line'],
+        'lines': [
+            ['requests_inflight', 'inflight'],
+        ]
+    },
+}
+
+
+def bucket_charts(bucket_id, bucket):
+    order = [
+        'bucket_{0}_size'.format(bucket_id),
+        'bucket_{0}_objects'.format(bucket_id),
+    ]
+    family = 'bucket ' + bucket
+    charts = {
+        order[0]: {
+            'options': [None, 'Bucket Size', 'MiB', family, 'minio.bucket_size', 'area'],
+            'lines': [
+                ['bucket_{0}_size'.format(bucket_id), 'size', 'absolute', 1, 1 << 20],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Bucket Objects', 'objects', family, 'minio.bucket_objects', 'line'],
+            'lines': [
+                ['bucket_{0}_objects'.format(bucket_id), 'objects'],
+            ]
+        },
+    }
+    return order, charts
+
+
+class BucketSelector:
+    """
+    Shell wildcard patterns, patterns starting with '!' are negative, the first matching pattern wins.
+    Buckets not matching any pattern are selected only if all the patterns are negative.
+    """
+
+    def __init__(self, patterns):
+        self.patterns = list()
+        for pattern in patterns or list():
+            pattern = str(pattern)
+            self.patterns.append((not pattern.startswith('!'), pattern.lstrip('!')))
+        self.default = not any(p[0] for p in self.patterns)
+
+    def selected(self, bucket):
+        for positive, pattern in self.patterns:
+            if fnmatch(bucket, pattern):
+                return positive
+        return self.default
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:9000').rstrip('/')
+        bearer_token = self.configuration.get('bearer_token')
+        if bearer_token:
+            # 'mc admin prometheus generate', not needed with MINIO_PROMETHEUS_AUTH_TYPE=public
+            self.header = dict(self.header or dict(), Authorization='Bearer {0}'.format(bearer_token))
+        self.do_buckets = self.configuration.get('bucket_charts', True)
+        self.selector = BucketSelector(self.configuration.get('buckets'))
+        self.collected_calls = set()
+        # bucket id -> <list> of its charts
+        self.collected_buckets = dict()
+
+    def get_samples(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        return parse(raw)
+
+    def _get_data(self):
+        samples = self.get_samples(API_CLUSTER_HEALTH)
+        if not samples:
+            return None
+
+        data = dict()
+        for metric, key in HEALTH_METRICS:
+            values = sum_by(samples, metric)
+            if values:
+                data[key] = int(values[None])
+        if 'capacity_total' in data and 'capacity_free' in data:
+            data['capacity_used'] = data['capacity_total'] - data['capacity_free']
+
+        samples = self.get_samples(API_ERASURE_SETS)
+        if samples:
+            data.update(self.erasure_sets_data(samples))
+
+        samples = self.get_samples(API_REQUESTS)
+        if samples:
+            data.update(self.requests_data(samples))
+
+        if self.do_buckets:
+            samples = self.get_samples(API_BUCKETS_USAGE)
+            if samples is not None:
+                data.update(self.buckets_data(samples))
+
+        return data
+
+    @staticmethod
+    def erasure_sets_data(samples):
+        data = {
+            'erasure_sets_healthy': 0,
+            'erasure_sets_unhealthy': 0,
+            'drives_healing': int(sum_by(samples, METRIC_SET_HEALING_DRIVES).get(None, 0)),
+        }
+        # one series per pool and set, 1 if the set has read and write quorum
+        for name, _, value in samples:
+            if name != METRIC_SET_HEALTH:
+                continue
+            if value:
+                data['erasure_sets_healthy'] += 1
+            else:
+                data['erasure_sets_unhealthy'] += 1
+        return data
+
+    def requests_data(self, samples):
+        # only the S3 API calls, not the admin, STS and internal ones
+        samples = [s for s in samples if s[1].get('type', 's3') == 's3']
+
+        data = dict()
+        requests, errors = sum_by(samples, METRIC_REQUESTS, 'name'), sum_by(samples, METRIC_ERRORS, 'name')
+        for call in requests:
+            if not call:
+                continue
+            call_id = clean_name(call)
+            if call_id not in self.collected_calls:
+                self.collected_calls.add(call_id)
+                self.add_call_dimensions(call_id, call)
+            data['requests_' + call_id] = int(requests[call])
+            data['errors_' + call_id] = int(errors.get(call, 0))
+
+        data['errors_4xx'] = int(sum_by(samples, METRIC_4XX_ERRORS).get(None, 0))
+        data['errors_5xx'] = int(sum_by(samples, METRIC_5XX_ERRORS).get(None, 0))
+        data['requests_inflight'] = int(sum_by(samples, METRIC_INFLIGHT).get(None, 0))
+        return data
+
+    def buckets_data(self, samples):
+        data = dict()
+        sizes = sum_by(samples, METRIC_BUCKET_SIZE, 'bucket')
+        objects = sum_by(samples, METRIC_BUCKET_OBJECTS, 'bucket')
+        buckets = set()
+        for bucket in sizes:
+            if not bucket or not self.selector.selected(bucket):
+                continue
+            bucket_id = clean_name(bucket)
+            buckets.add(bucket_id)
+            if bucket_id not in self.collected_buckets:
+                self.add_bucket_charts(bucket_id, bucket)
+            data['bucket_{0}_size'.format(bucket_id)] = int(sizes[bucket])
+            data['bucket_{0}_objects'.format(bucket_id)] = int(objects.get(bucket, 0))
+
+        # deleted buckets
+        for bucket_id in set(self.collected_buckets) - buckets:
+            self.remove_bucket_charts(bucket_id)
+        return data
+
+    def add_call_dimensions(self, call_id, call):
+        dimensions = (
+            ('requests', ['requests_' + call_id, call, 'incremental']),
+            ('errors', ['errors_' + call_id, call, 'incremental']),
+        )
+        for chart, dimension in dimensions:
+            self.add_dimension(chart, dimension)
+
+    def add_bucket_charts(self, bucket_id, bucket):
+        order, charts = bucket_charts(bucket_id, bucket)
+        self.collected_buckets[bucket_id] = order
+        self.add_charts(order, charts)
+
+    def remove_bucket_charts(self, bucket_id):
+        self.remove_charts(self.collected_buckets.pop(bucket_id))
@@ -0,0 +1,187 @@
+# -*- coding: utf-8 -*-
+# Description: mlflow netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import time
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.prometheus import parse, sum_by
+
+update_every = 10
+
+API_HEALTH = 'health'
+API_EXPERIMENTS = 'api/2.0/mlflow/experiments/search'
+API_RUNS = 'api/2.0/mlflow/runs/search'
+API_METRICS = 'metrics'
+
+# exported when the server runs with --expose-prometheus
+METRIC_REQUESTS_SUM = 'mlflow_requests_by_status_and_path_sum'
+METRIC_REQUESTS_COUNT = 'mlflow_requests_by_status_and_path_count'
+
+# search pages are 1000 items at most, stop counting after MAX_PAGES pages
+PAGE_SIZE = 1000
+MAX_PAGES = 10
+
+RUN_STATUSES = (
+    'RUNNING',
+    'SCHEDULED',
+)
+
+STATUS_CLASSES = (
+    '2xx',
+    '3xx',
+    '4xx',
+    '5xx',
+)
+
+ORDER = [
+    'health',
+    'health_time',
+    'experiments',
+    'runs',
+    'requests',
+    'request_time',
+]
+
+CHARTS = {
+    'health': {
+        'options': [None, 'Tracking Server Health', 'status', 'health', 'mlflow.health', 'line'],
+        'lines': [
+            ['healthy', 'healthy'],
+        ]
+    },
+    'health_time': {
+        'options': [None, 'Health Check Response Time', 'milliseconds', 'health', 'mlflow.health_time', 'line'],
+        'lines': [
+            ['health_time', 'time', 'absolute', 1, 1000],
+        ]
+    },
+    'experiments': {
+        'options': [None, 'Active Experiments', 'experiments', 'tracking', 'mlflow.experiments', 'line'],
+        'lines': [
+            ['experiments', 'experiments'],
+        ]
+    },
+    'runs': {
+        'options': [None, 'Active Runs', 'runs', 'tracking', 'mlflow.runs', 'stacked'],
+        'lines': [['runs_' + s.lower(), s.lower()] for s in RUN_STATUSES]
+    },
+    'requests': {
+        'options': [None, 'Requests By Status Class', 'requests/s', 'requests', 'mlflow.requests', 'stacked'],
+        'lines': [['requests_' + c, c, 'incremental'] for c in STATUS_CLASSES]
+    },
+    'request_time': {
+        'options': [None, 'Requests Average Response Time', 'milliseconds', 'requests', 'mlflow.request_time',
+                    'line'],
+        'lines': [
+            ['request_time', 'time', 'absolute', 1, 1000],
+        ]
+    },
+}
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.url = self.configuration.get('url', 'http://127.0.0.1:5000').rstrip('/')
+        self.collect_runs = self.configuration.get('runs', True)
+        self.requests = None
+
+    def check(self):
+        if not UrlService.check(self):
+            return False
+        # the health chart keeps being updated when the server goes down, but it has to be up to start
+        data = self._get_data()
+        if not data.get('healthy'):
+            self.error('{0}/{1} is not healthy'.format(self.url, API_HEALTH))
+            return False
+        # any web server answers '/health', the tracking api tells an mlflow server apart
+        if 'experiments' not in data:
+            self.error('{0}/{1} is not an mlflow tracking api'.format(self.url, API_EXPERIMENTS))
+            return False
+        return True
+
+    def _get_data(self):
+        data = dict()
+        start = time.time()
+        healthy = self._get_raw_data('{0}/{1}'.format(self.url, API_HEALTH)) is not None
+        data['healthy'] = int(healthy)
+        if not healthy:
+            return data
+        data['health_time'] = int((time.time() - start) * 1e6)
+
+        experiments = self.search(API_EXPERIMENTS, 'experiments', method='GET')
+        if experiments is not None:
+            data['experiments'] = len(experiments)
+        if experiments and self.collect_runs:
+            ids = [e['experiment_id'] for e in experiments]
+            for status in RUN_STATUSES:
+                runs = self.search(API_RUNS, 'runs', experiment_ids=ids,
+                                   filter="attributes.status = '{0}'".format(status))
+                if runs is not None:
+                    data['runs_' + status.lower()] = len(runs)
+
+        data.update(self.get_metrics_data())
+        return data
+
+    def search(self, path, key, method='POST', **query):
+        """
+        Walk the pages of a search endpoint.
+        :return: <list> of the found items
+        """
+        url = '{0}/{1}'.format(self.url, path)
+        query.update(max_results=PAGE_SIZE)
+        items = list()
+        for _ in range(MAX_PAGES):
+            try:
+                if method == 'GET':
+                    response = self._manager.request('GET', url, fields=query, headers=self._manager.headers,
+                                                     timeout=self.request_timeout)
+                else:
+                    headers = dict(self._manager.headers)
+                    headers['Content-Type'] = 'application/json'
+                    response = self._manager.request('POST', url, body=json.dumps(query), headers=headers,
+                                                     timeout=self.request_timeout)
+                if response.status != 200:
+                    self.debug('{0}: http response status code {1}'.format(url, response.status))
+                    return None
+                result = json.loads(response.data.decode(errors='ignore'))
+            except Exception as error:
+                self.error('{0}: {1}'.format(url, error))
+                return None
+
+            items.extend(result.get(key) or list())
+            if not result.get('next_page_token'):
+                break
+            query['page_token'] = result['next_page_token']
+        return items
+
+    def get_metrics_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_METRICS))
+        if not raw:
+            return dict()
+
+        samples = parse(raw, names=(METRIC_REQUESTS_SUM, METRIC_REQUESTS_COUNT))
+        counts = sum_by(samples, METRIC_REQUESTS_COUNT, 'status')
+        if not counts:
+            return dict()
+
+        data = dict(('requests_' + c, 0) for c in STATUS_CLASSES)
+        for status, count in counts.items():
+            status_class = '{0}xx'.format(str(status)[:1])
+            if status_class in STATUS_CLASSES:
+                data['requests_' + status_class] += int(count)
+
+        # average of the requests completed since the last run, in microseconds
+        total, count = sum(sum_by(samples, METRIC_REQUESTS_SUM).values()), sum(counts.values())
+        prev_total, prev_count = self.requests or (total, count)
+        self.requests = (total, count)
+        if count > prev_count:
+            data['request_time'] = int((total - prev_total) / (count - prev_count) * 1e6)
+        else:
+            data['request_time'] = 0
+
+        return data
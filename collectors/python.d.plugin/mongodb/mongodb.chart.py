@@ -420,6 +420,79 @@ CHARTS = {
     }
 }
 
+SHARDING_ORDER = [
+    'sharding_shards',
+    'sharding_balancer',
+    'sharding_balancer_rounds',
+    'sharding_balancer_failed_rounds',
+    'sharding_migrations',
+]
+
+SHARDING_CHARTS = {
+    'sharding_shards': {
+        'options': [None, 'Shards by state', 'shards', 'sharding', 'mongodb.sharding_shards', 'stacked'],
+        'lines': [
+            ['shards_up', 'up'],
+            ['shards_draining', 'draining'],
+        ]
+    },
+    'sharding_balancer': {
+        'options': [None, 'Balancer state', 'state', 'sharding', 'mongodb.sharding_balancer', 'line'],
+        'lines': [
+            ['balancer_enabled', 'enabled'],
+            ['balancer_in_round', 'in_round'],
+        ]
+    },
+    'sharding_balancer_rounds': {
+        'options': [None, 'Balancer rounds', 'rounds/s', 'sharding', 'mongodb.sharding_balancer_rounds', 'line'],
+        'lines': [
+            ['balancer_rounds', 'rounds', 'incremental'],
+        ]
+    },
+    'sharding_balancer_failed_rounds': {
+        'options': [None, 'Failed balancer rounds in the last 5 attempts', 'rounds', 'sharding',
+                    'mongodb.sharding_balancer_failed_rounds', 'line'],
+        'lines': [
+            ['balancer_failed_rounds', 'failed'],
+        ]
+    },
+    'sharding_migrations': {
+        'options': [None, 'Chunk migrations in progress', 'migrations', 'sharding', 'mongodb.sharding_migrations',
+                    'line'],
+        'lines': [
+            ['migrations_in_progress', 'in_progress'],
+        ]
+    },
+}
+
+# same as sh.status()
+BALANCER_ROUNDS_CHECKED = 5
+
+
+def shard_charts(shard):
+    order = [
+        '_'.join(['shard', shard, 'chunks']),
+        '_'.join(['shard', shard, 'data_size']),
+    ]
+    charts = {
+        order[0]: {
+            'options': [None, 'Shard %s: chunks' % shard, 'chunks', 'sharding', 'mongodb.shard_chunks', 'line'],
+            'lines': [
+                ['_'.join(['shard', shard, 'chunks']), 'chunks', 'absolute'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Shard %s: size of all documents and indexes' % shard, 'KB', 'sharding',
+                        'mongodb.shard_data_size', 'stacked'],
+            'lines': [
+                ['_'.join(['shard', shard, 'dataSize']), 'documents', 'absolute', 1, 1024],
+                ['_'.join(['shard', shard, 'indexSize']), 'indexes', 'absolute', 1, 1024],
+            ]
+        },
+    }
+    return order, charts
+
+
 DEFAULT_HOST = '127.0.0.1'
 DEFAULT_PORT = 27017
 DEFAULT_TIMEOUT = 100
@@ -448,7 +521,9 @@ class Service(SimpleService):
         self.metrics_to_collect = deepcopy(DEFAULT_METRICS)
         self.connection = None
         self.do_replica = None
+        self.do_sharding = None
         self.databases = list()
+        self.collected_shards = set()
 
     def check(self):
         if not PYMONGO:
@@ -476,6 +551,10 @@ class Service(SimpleService):
     def build_metrics_to_collect_(self, server_status):
 
         self.do_replica = 'repl' in server_status
+        self.do_sharding = server_status.get('process') == 'mongos'
+        if self.do_sharding:
+            self.order.extend(SHARDING_ORDER)
+            self.definitions.update(deepcopy(SHARDING_CHARTS))
         if 'dur' in server_status:
             self.metrics_to_collect.extend(DUR)
         if 'tcmalloc' in server_status:
@@ -589,6 +668,7 @@ class Service(SimpleService):
         raw_data.update(self.get_db_stats() or dict())
         raw_data.update(self.get_repl_set_get_status() or dict())
         raw_data.update(self.get_get_replication_info() or dict())
+        raw_data.update(self.get_sharding_status() or dict())
 
         return raw_data or None
 
@@ -640,6 +720,26 @@ class Service(SimpleService):
         except PyMongoError:
             return None
 
+    def get_sharding_status(self):
+        if not self.do_sharding:
+            return None
+
+        raw_data = dict()
+        raw_data['shardingStatus'] = dict()
+        config = self.connection.config
+        try:
+            raw_data['shardingStatus']['shards'] = list(config.shards.find())
+            raw_data['shardingStatus']['chunks'] = list(config.chunks.aggregate([
+                {'$group': {'_id': '$shard', 'count': {'$sum': 1}}},
+            ]))
+            raw_data['shardingStatus']['migrations'] = len(list(config.migrations.find({}, {'_id': 1})))
+            raw_data['shardingStatus']['rounds'] = list(config.actionlog.find({'what': 'balancer.round'}).sort(
+                'time', DESCENDING).limit(BALANCER_ROUNDS_CHECKED))
+            raw_data['shardingStatus']['balancerStatus'] = self.connection.admin.command('balancerStatus')
+        except PyMongoError:
+            return None
+        return raw_data
+
     def _get_data(self):
         """
         :return: dict
@@ -654,6 +754,7 @@ class Service(SimpleService):
         dbStats = raw_data.get('dbStats')
         replSetGetStatus = raw_data.get('replSetGetStatus')
         getReplicationInfo = raw_data.get('getReplicationInfo')
+        shardingStatus = raw_data.get('shardingStatus')
         utc_now = datetime.utcnow()
 
         # serverStatus
@@ -718,8 +819,62 @@ class Service(SimpleService):
             last_event = getReplicationInfo['DESCENDING']['ts'].as_datetime()
             data['timeDiff'] = int(delta_calculation(delta=last_event - first_event, multiplier=1000))
 
+        if shardingStatus:
+            data.update(self.sharding_data(shardingStatus, dbStats or dict()))
+
         return data
 
+    def sharding_data(self, status, db_stats):
+        data = {
+            'shards_up': 0,
+            'shards_draining': 0,
+            'migrations_in_progress': status['migrations'],
+        }
+
+        hosts = dict()
+        for shard in status['shards']:
+            name = shard['_id']
+            hosts[shard.get('host')] = name
+            if name not in self.collected_shards:
+                self.collected_shards.add(name)
+                self.add_shard_charts(name)
+            data['shard_%s_chunks' % name] = 0
+            data['shard_%s_dataSize' % name] = 0
+            data['shard_%s_indexSize' % name] = 0
+            if shard.get('draining'):
+                data['shards_draining'] += 1
+            else:
+                data['shards_up'] += 1
+
+        for chunks in status['chunks']:
+            key = 'shard_%s_chunks' % chunks['_id']
+            if key in data:
+                data[key] = chunks['count']
+
+        # dbStats on mongos reports every shard separately
+        for stats in db_stats.values():
+            for host, shard_stats in stats.get('raw', dict()).items():
+                # keys are the shards connection strings ('<replica set>/<host:port>,...')
+                name = hosts.get(host, host.split('/')[0])
+                for metric in ('dataSize', 'indexSize'):
+                    key = 'shard_%s_%s' % (name, metric)
+                    if key in data:
+                        data[key] += shard_stats.get(metric, 0)
+
+        balancer = status['balancerStatus']
+        data['balancer_enabled'] = int(balancer.get('mode') == 'full')
+        data['balancer_in_round'] = int(bool(balancer.get('inBalancerRound')))
+        if 'numBalancerRounds' in balancer:
+            data['balancer_rounds'] = balancer['numBalancerRounds']
+        data['balancer_failed_rounds'] = len(
+            [r for r in status['rounds'] if r.get('details', dict()).get('errorOccured')])
+
+        return data
+
+    def add_shard_charts(self, shard):
+        order, charts = shard_charts(shard)
+        self.add_charts(order, charts)
+
     def build_ssl_connection_params(self):
         conf = self.configuration
 
@@ -0,0 +1,258 @@
+# -*- coding: utf-8 -*-
+# Description: mosquitto netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import os
+import socket
+import struct
+import time
+from binascii import hexlify
+
+from bases.FrameworkServices.SimpleService import SimpleService
+
+update_every = 10
+
+# mosquitto publishes the $SYS tree as retained messages every 'sys_interval' seconds,
+# a subscriber gets the last values at once
+SYS_TOPIC = '$SYS/broker/#'
+
+DEFAULT_HOST = '127.0.0.1'
+DEFAULT_PORT = 1883
+DEFAULT_TIMEOUT = 2
+
+CONNECT = 0x10
+CONNACK = 0x20
+PUBLISH = 0x30
+SUBSCRIBE = 0x82
+PINGREQ = 0xc0
+PINGRESP = 0xd0
+DISCONNECT = 0xe0
+
+CONNACK_ERRORS = {
+    1: 'unacceptable protocol version',
+    2: 'identifier rejected',
+    3: 'server unavailable',
+    4: 'bad user name or password',
+    5: 'not authorized',
+}
+
+METRICS = {
+    '$SYS/broker/clients/connected': 'clients_connected',
+    '$SYS/broker/clients/disconnected': 'clients_disconnected',
+    '$SYS/broker/messages/received': 'messages_received',
+    '$SYS/broker/messages/sent': 'messages_sent',
+    '$SYS/broker/publish/messages/received': 'publish_received',
+    '$SYS/broker/publish/messages/sent': 'publish_sent',
+    '$SYS/broker/publish/messages/dropped': 'publish_dropped',
+    '$SYS/broker/bytes/received': 'bytes_received',
+    '$SYS/broker/bytes/sent': 'bytes_sent',
+    '$SYS/broker/retained messages/count': 'retained_messages',
+    '$SYS/broker/store/messages/count': 'stored_messages',
+    '$SYS/broker/subscriptions/count': 'subscriptions',
+    '$SYS/broker/heap/current': 'heap_current',
+}
+
+ORDER = [
+    'clients',
+    'messages',
+    'publish',
+    'dropped',
+    'traffic',
+    'retained',
+    'subscriptions',
+    'memory',
+]
+
+CHARTS = {
+    'clients': {
+        'options': [None, 'Clients', 'clients', 'clients', 'mosquitto.clients', 'stacked'],
+        'lines': [
+            ['clients_connected', 'connected'],
+            ['clients_disconnected', 'disconnected'],
+        ]
+    },
+    'messages': {
+        'options': [None, 'MQTT Packets', 'packets/s', 'messages', 'mosquitto.messages', 'line'],
+        'lines': [
+            ['messages_received', 'received', 'incremental'],
+            ['messages_sent', 'sent', 'incremental', -1, 1],
+        ]
+    },
+    'publish': {
+        'options': [None, 'Published Messages', 'messages/s', 'messages', 'mosquitto.publish', 'line'],
+        'lines': [
+            ['publish_received', 'received', 'incremental'],
+            ['publish_sent', 'sent', 'incremental', -1, 1],
+        ]
+    },
+    'dropped': {
+        'options': [None, 'Dropped Messages', 'messages/s', 'messages', 'mosquitto.dropped', 'line'],
+        'lines': [
+            ['publish_dropped', 'dropped', 'incremental'],
+        ]
+    },
+    'traffic': {
+        'options': [None, 'Traffic', 'KiB/s', 'messages', 'mosquitto.traffic', 'area'],
+        'lines': [
+            ['bytes_received', 'received', 'incremental', 1, 1 << 10],
+            ['bytes_sent', 'sent', 'incremental', -1, 1 << 10],
+        ]
+    },
+    'retained': {
+        'options': [None, 'Retained And Stored Messages', 'messages', 'store', 'mosquitto.retained', 'line'],
+        'lines': [
+            ['retained_messages', 'retained'],
+            ['stored_messages', 'stored'],
+        ]
+    },
+    'subscriptions': {
+        'options': [None, 'Subscriptions', 'subscriptions', 'store', 'mosquitto.subscriptions', 'line'],
+        'lines': [
+            ['subscriptions', 'subscriptions'],
+        ]
+    },
+    'memory': {
+        'options': [None, 'Heap Memory', 'MiB', 'store', 'mosquitto.memory', 'area'],
+        'lines': [
+            ['heap_current', 'used', 'absolute', 1, 1 << 20],
+        ]
+    },
+}
+
+
+def mqtt_string(value):
+    value = value.encode('utf-8')
+    return struct.pack('!H', len(value)) + value
+
+
+def mqtt_packet(packet_type, body):
+    # remaining length is a variable byte integer, 7 bits per byte
+    length, encoded = len(body), bytearray()
+    while True:
+        byte, length = length % 128, length // 128
+        encoded.append(byte | 0x80 if length else byte)
+        if not length:
+            break
+    return struct.pack('!B', packet_type) + bytes(encoded) + body
+
+
+def parse_packets(buf):
+    """
+    Split the buffer into MQTT packets.
+    Returns (list of (fixed header byte, body), remaining buffer).
+    """
+    packets = list()
+    while len(buf) >= 2:
+        length, multiplier, pos = 0, 1, 1
+        while True:
+            if pos >= len(buf):
+                return packets, buf
+            byte = ord(buf[pos:pos + 1])
+            length += (byte & 0x7f) * multiplier
+            multiplier *= 128
+            pos += 1
+            if not byte & 0x80:
+                break
+        if len(buf) < pos + length:
+            break
+        packets.append((ord(buf[0:1]), buf[pos:pos + length]))
+        buf = buf[pos + length:]
+    return packets, buf
+
+
+def parse_publish(body, qos):
+    size = struct.unpack('!H', body[:2])[0]
+    topic = body[2:2 + size].decode('utf-8', 'replace')
+    # QoS 1 and 2 messages carry a packet identifier
+    payload = body[2 + size + (2 if qos else 0):]
+    return topic, payload
+
+
+class Service(SimpleService):
+    def __init__(self, configuration=None, name=None):
+        SimpleService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.host = self.configuration.get('host', DEFAULT_HOST)
+        self.port = self.configuration.get('port', DEFAULT_PORT)
+        self.user = self.configuration.get('user')
+        self.password = self.configuration.get('pass')
+        self.timeout = self.configuration.get('timeout', DEFAULT_TIMEOUT)
+        self.client_id = 'netdata-{0}'.format(hexlify(os.urandom(6)).decode())
+
+    def check(self):
+        data = self.get_data()
+        if data is None:
+            return False
+        if not data:
+            self.error('no $SYS topics received, is "sys_interval" set to 0 in mosquitto.conf?')
+            return False
+        return True
+
+    def connect_packet(self):
+        # clean session
+        flags = 0x02
+        payload = mqtt_string(self.client_id)
+        if self.user:
+            flags |= 0x80
+            payload += mqtt_string(self.user)
+            if self.password:
+                flags |= 0x40
+                payload += mqtt_string(self.password)
+        # MQTT 3.1.1, keepalive is longer than a collection
+        header = mqtt_string('MQTT') + struct.pack('!BBH', 4, flags, 60)
+        return mqtt_packet(CONNECT, header + payload)
+
+    def read_sys_tree(self):
+        sock = socket.create_connection((self.host, self.port), timeout=self.timeout)
+        try:
+            # the broker handles the packets in order, the ping response comes after the retained messages
+            subscribe = struct.pack('!H', 1) + mqtt_string(SYS_TOPIC) + b'\x00'
+            sock.sendall(self.connect_packet() + mqtt_packet(SUBSCRIBE, subscribe) + mqtt_packet(PINGREQ, b''))
+
+            topics = dict()
+            buf = b''
+            deadline = time.time() + self.timeout
+            while time.time() < deadline:
+                sock.settimeout(max(deadline - time.time(), 0.01))
+                try:
+                    chunk = sock.recv(65536)
+                except socket.timeout:
+                    break
+                if not chunk:
+                    raise ValueError('connection closed by the broker')
+
+                packets, buf = parse_packets(buf + chunk)
+                for header, body in packets:
+                    packet_type = header & 0xf0
+                    if packet_type == CONNACK and ord(body[1:2]):
+                        code = ord(body[1:2])
+                        raise ValueError('connection refused: {0}'.format(CONNACK_ERRORS.get(code, code)))
+                    if packet_type == PUBLISH:
+                        topic, payload = parse_publish(body, header & 0x06)
+                        topics[topic] = payload
+                    elif packet_type == PINGRESP:
+                        sock.sendall(mqtt_packet(DISCONNECT, b''))
+                        return topics
+            return topics
+        finally:
+            sock.close()
+
+    def _get_data(self):
+        try:
+            topics = self.read_sys_tree()
+        except (socket.error, ValueError, struct.error) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return None
+
+        data = dict()
+        for topic, key in METRICS.items():
+            if topic not in topics:
+                continue
+            # some values come with units, e.g. '$SYS/broker/uptime' is '42 seconds'
+            try:
+                data[key] = int(topics[topic].split()[0])
+            except (ValueError, IndexError):
+                continue
+
+        return data
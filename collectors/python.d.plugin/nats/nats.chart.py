@@ -0,0 +1,245 @@
+# -*- coding: utf-8 -*-
+# Description: nats netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import os
+import socket
+import time
+from binascii import hexlify
+from copy import deepcopy
+
+from bases.FrameworkServices.SimpleService import SimpleService
+from bases.collection import clean_name
+
+update_every = 10
+
+# every server of the supercluster answers this request of the system account with its statz
+SUBJECT_STATZ = '$SYS.REQ.SERVER.PING.STATZ'
+
+DEFAULT_HOST = '127.0.0.1'
+DEFAULT_PORT = 4222
+DEFAULT_TIMEOUT = 2
+
+ORDER = [
+    'servers',
+]
+
+CHARTS = {
+    'servers': {
+        'options': [None, 'Responding Servers', 'servers', 'supercluster', 'nats.servers', 'stacked'],
+        'lines': []
+    },
+}
+
+
+def server_charts(server_id, server_name):
+    order = [
+        'server_{0}_connections'.format(server_id),
+        'server_{0}_subscriptions'.format(server_id),
+        'server_{0}_messages'.format(server_id),
+        'server_{0}_traffic'.format(server_id),
+        'server_{0}_slow_consumers'.format(server_id),
+        'server_{0}_routes'.format(server_id),
+        'server_{0}_cpu'.format(server_id),
+        'server_{0}_memory'.format(server_id),
+    ]
+    family = server_name
+    charts = {
+        order[0]: {
+            'options': [None, 'Client Connections', 'connections', family, 'nats.server_connections', 'line'],
+            'lines': [
+                ['server_{0}_connections'.format(server_id), 'connections'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Subscriptions', 'subscriptions', family, 'nats.server_subscriptions', 'line'],
+            'lines': [
+                ['server_{0}_subscriptions'.format(server_id), 'subscriptions'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Messages', 'messages/s', family, 'nats.server_messages', 'area'],
+            'lines': [
+                ['server_{0}_received_msgs'.format(server_id), 'received', 'incremental'],
+                ['server_{0}_sent_msgs'.format(server_id), 'sent', 'incremental', -1, 1],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Traffic', 'kilobits/s', family, 'nats.server_traffic', 'area'],
+            'lines': [
+                ['server_{0}_received_bytes'.format(server_id), 'received', 'incremental', 8, 1000],
+                ['server_{0}_sent_bytes'.format(server_id), 'sent', 'incremental', -8, 1000],
+            ]
+        },
+        order[4]: {
+            'options': [None, 'Slow Consumers', 'consumers/s', family, 'nats.server_slow_consumers', 'line'],
+            'lines': [
+                ['server_{0}_slow_consumers'.format(server_id), 'slow', 'incremental'],
+            ]
+        },
+        order[5]: {
+            'options': [None, 'Routes And Gateways', 'connections', family, 'nats.server_routes', 'line'],
+            'lines': [
+                ['server_{0}_routes'.format(server_id), 'routes'],
+                ['server_{0}_gateways'.format(server_id), 'gateways'],
+            ]
+        },
+        order[6]: {
+            'options': [None, 'CPU Usage', 'percentage', family, 'nats.server_cpu', 'line'],
+            'lines': [
+                ['server_{0}_cpu'.format(server_id), 'used', 'absolute', 1, 100],
+            ]
+        },
+        order[7]: {
+            'options': [None, 'Memory Usage', 'MiB', family, 'nats.server_memory', 'line'],
+            'lines': [
+                ['server_{0}_mem'.format(server_id), 'used', 'absolute', 1, 1 << 20],
+            ]
+        },
+    }
+    return order, charts
+
+
+def parse_messages(buf):
+    """
+    Parse 'MSG <subject> <sid> [reply-to] <#bytes>\r\n<payload>\r\n' frames.
+    Returns (list of payloads, remaining buffer, number of PINGs received).
+    """
+    payloads = list()
+    pings = 0
+    while True:
+        end = buf.find(b'\r\n')
+        if end < 0:
+            break
+        line = buf[:end]
+        if line.startswith(b'MSG '):
+            size = int(line.split()[-1])
+            if len(buf) < end + 2 + size + 2:
+                break
+            payloads.append(buf[end + 2:end + 2 + size])
+            buf = buf[end + 2 + size + 2:]
+            continue
+        if line == b'PING':
+            pings += 1
+        elif line.startswith(b'-ERR'):
+            raise ValueError(line.decode('utf-8', 'replace'))
+        buf = buf[end + 2:]
+    return payloads, buf, pings
+
+
+class Service(SimpleService):
+    def __init__(self, configuration=None, name=None):
+        SimpleService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.host = self.configuration.get('host', DEFAULT_HOST)
+        self.port = self.configuration.get('port', DEFAULT_PORT)
+        self.user = self.configuration.get('user')
+        self.password = self.configuration.get('pass')
+        self.token = self.configuration.get('token')
+        self.timeout = self.configuration.get('timeout', DEFAULT_TIMEOUT)
+        self.expected_servers = self.configuration.get('servers', 0)
+        self.inbox = '_INBOX.netdata.{0}'.format(hexlify(os.urandom(8)).decode())
+        self.collected_servers = set()
+        self.collected_clusters = set()
+
+    def check(self):
+        if not (self.user and self.password) and not self.token:
+            self.error('system account credentials are needed, set "user" and "pass", or "token"')
+            return False
+        return bool(self.get_data())
+
+    def connect_options(self):
+        options = {
+            'verbose': False,
+            'pedantic': False,
+            'name': 'netdata',
+            'lang': 'python',
+            'version': '1',
+            'protocol': 1,
+        }
+        if self.token:
+            options['auth_token'] = self.token
+        else:
+            options['user'], options['pass'] = self.user, self.password
+        return options
+
+    def request_statz(self):
+        sock = socket.create_connection((self.host, self.port), timeout=self.timeout)
+        try:
+            request = 'CONNECT {0}\r\nSUB {1} 1\r\nPUB {2} {1} 0\r\n\r\nPING\r\n'.format(
+                json.dumps(self.connect_options()), self.inbox, SUBJECT_STATZ)
+            sock.sendall(request.encode())
+
+            responses = list()
+            buf = b''
+            deadline = time.time() + self.timeout
+            while time.time() < deadline:
+                if self.expected_servers and len(responses) >= self.expected_servers:
+                    break
+                sock.settimeout(max(deadline - time.time(), 0.01))
+                try:
+                    chunk = sock.recv(65536)
+                except socket.timeout:
+                    break
+                if not chunk:
+                    break
+                payloads, buf, pings = parse_messages(buf + chunk)
+                responses.extend(payloads)
+                if pings:
+                    sock.sendall(b'PONG\r\n' * pings)
+            return responses
+        finally:
+            sock.close()
+
+    def _get_data(self):
+        try:
+            responses = self.request_statz()
+        except (socket.error, ValueError) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return None
+
+        data = dict(('cluster_' + c, 0) for c in self.collected_clusters)
+        for raw in responses:
+            try:
+                msg = json.loads(raw.decode('utf-8'))
+                server, statz = msg['server'], msg['statz']
+            except (ValueError, KeyError, TypeError):
+                continue
+
+            name = server.get('name') or server.get('id')
+            if not name:
+                continue
+            server_id = clean_name(name)
+            cluster = clean_name(server.get('cluster') or 'standalone')
+
+            if cluster not in self.collected_clusters:
+                self.collected_clusters.add(cluster)
+                self.add_cluster_dimension(cluster)
+                data['cluster_' + cluster] = 0
+            data['cluster_' + cluster] += 1
+
+            if server_id not in self.collected_servers:
+                self.collected_servers.add(server_id)
+                self.add_server_charts(server_id, name)
+
+            prefix = 'server_{0}_'.format(server_id)
+            for key in ('connections', 'subscriptions', 'slow_consumers', 'mem'):
+                data[prefix + key] = statz.get(key, 0)
+            for direction in ('sent', 'received'):
+                for key in ('msgs', 'bytes'):
+                    data['{0}{1}_{2}'.format(prefix, direction, key)] = (statz.get(direction) or dict()).get(key, 0)
+            data[prefix + 'cpu'] = int(statz.get('cpu', 0) * 100)
+            data[prefix + 'routes'] = len(statz.get('routes') or list())
+            data[prefix + 'gateways'] = len(statz.get('gateways') or list())
+
+        return data or None
+
+    def add_cluster_dimension(self, cluster):
+        dimension = ['cluster_' + cluster, cluster]
+        self.add_dimension('servers', dimension)
+
+    def add_server_charts(self, server_id, server_name):
+        order, charts = server_charts(server_id, server_name)
+        self.add_charts(order, charts)
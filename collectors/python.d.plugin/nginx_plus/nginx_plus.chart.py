@@ -246,6 +246,52 @@ def web_upstream_charts(wu):
     return charts
 
 
+def limit_req_zone_charts(lz):
+    charts = OrderedDict()
+    family = 'limit req zone {name}'.format(name=lz.real_name)
+
+    charts['{name}_requests'.format(name=lz.name)] = {
+        'options': [None, 'Zone "{name}" Requests'.format(name=lz.real_name), 'requests/s', family,
+                    'nginx_plus.limit_req_zone_requests', 'stacked'],
+        'lines': [
+            ['_'.join([lz.name, 'passed']), 'passed', 'incremental'],
+            ['_'.join([lz.name, 'delayed']), 'delayed', 'incremental'],
+            ['_'.join([lz.name, 'rejected']), 'rejected', 'incremental']
+        ]
+    }
+    charts['{name}_dry_run'.format(name=lz.name)] = {
+        'options': [None, 'Zone "{name}" Dry Run'.format(name=lz.real_name), 'requests/s', family,
+                    'nginx_plus.limit_req_zone_dry_run', 'stacked'],
+        'lines': [
+            ['_'.join([lz.name, 'delayed_dry_run']), 'delayed', 'incremental'],
+            ['_'.join([lz.name, 'rejected_dry_run']), 'rejected', 'incremental']
+        ]
+    }
+    return charts
+
+
+def limit_conn_zone_charts(lz):
+    charts = OrderedDict()
+    family = 'limit conn zone {name}'.format(name=lz.real_name)
+
+    charts['{name}_connections'.format(name=lz.name)] = {
+        'options': [None, 'Zone "{name}" Connections'.format(name=lz.real_name), 'connections/s', family,
+                    'nginx_plus.limit_conn_zone_connections', 'stacked'],
+        'lines': [
+            ['_'.join([lz.name, 'passed']), 'passed', 'incremental'],
+            ['_'.join([lz.name, 'rejected']), 'rejected', 'incremental']
+        ]
+    }
+    charts['{name}_dry_run'.format(name=lz.name)] = {
+        'options': [None, 'Zone "{name}" Dry Run'.format(name=lz.real_name), 'connections/s', family,
+                    'nginx_plus.limit_conn_zone_dry_run', 'line'],
+        'lines': [
+            ['_'.join([lz.name, 'rejected_dry_run']), 'rejected', 'incremental']
+        ]
+    }
+    return charts
+
+
 METRICS = {
     'SERVER': [
         'processes.respawned',
@@ -301,6 +347,18 @@ METRICS = {
         'sent',
         'received'
     ],
+    'LIMIT_REQ_ZONE': [
+        'passed',
+        'delayed',
+        'rejected',
+        'delayed_dry_run',
+        'rejected_dry_run'
+    ],
+    'LIMIT_CONN_ZONE': [
+        'passed',
+        'rejected',
+        'rejected_dry_run'
+    ],
     'CACHE': [
         'hit.bytes',  # served
         'miss.bytes_written',  # written
@@ -346,6 +404,34 @@ class WebZone:
         return dict(('_'.join([self.name, k]), v) for k, v in data.items())
 
 
+class LimitReqZone:
+    key = 'limit_reqs'
+    charts = limit_req_zone_charts
+
+    def __init__(self, **kw):
+        self.real_name = kw['name']
+        self.name = 'limit_req_' + BAD_SYMBOLS.sub('_', self.real_name)
+
+    def get_data(self, raw_data):
+        zone_data = raw_data['limit_reqs'][self.real_name]
+        data = parse_json(zone_data, METRICS['LIMIT_REQ_ZONE'])
+        return dict(('_'.join([self.name, k]), v) for k, v in data.items())
+
+
+class LimitConnZone:
+    key = 'limit_conns'
+    charts = limit_conn_zone_charts
+
+    def __init__(self, **kw):
+        self.real_name = kw['name']
+        self.name = 'limit_conn_' + BAD_SYMBOLS.sub('_', self.real_name)
+
+    def get_data(self, raw_data):
+        zone_data = raw_data['limit_conns'][self.real_name]
+        data = parse_json(zone_data, METRICS['LIMIT_CONN_ZONE'])
+        return dict(('_'.join([self.name, k]), v) for k, v in data.items())
+
+
 class WebUpstream:
     key = 'upstreams'
     charts = web_upstream_charts
@@ -422,6 +508,7 @@ class Service(UrlService):
         UrlService.__init__(self, configuration=configuration, name=name)
         self.order = list(ORDER)
         self.definitions = deepcopy(CHARTS)
+        self.api_url = self.configuration.get('api_url')
         self.objects = dict()
 
     def check(self):
@@ -441,11 +528,12 @@ class Service(UrlService):
             response = loads(raw_data)
         except ValueError:
             return None
+        response.update(self.get_limits())
 
-        for obj_cls in [WebZone, WebUpstream, Cache]:
+        for obj_cls in [WebZone, WebUpstream, Cache, LimitReqZone, LimitConnZone]:
             for obj_name in response.get(obj_cls.key, list()):
                 obj = obj_cls(name=obj_name, response=response)
-                self.objects[obj.real_name] = obj
+                self.objects[(obj.key, obj.real_name)] = obj
                 charts = obj_cls.charts(obj)
                 for chart in charts:
                     self.order.append(chart)
@@ -462,16 +550,36 @@ class Service(UrlService):
         if not raw_data:
             return None
         response = loads(raw_data)
+        response.update(self.get_limits())
 
         data = parse_json(response, METRICS['SERVER'])
         data['ssl_memory_usage'] = data['slabs_SSL_pages_used'] / float(data['slabs_SSL_pages_free']) * 1e4
 
         for obj in self.objects.values():
-            if obj.real_name in response[obj.key]:
+            if obj.real_name in response.get(obj.key, dict()):
                 data.update(obj.get_data(response))
 
         return data
 
+    def get_limits(self):
+        """
+        limit_req and limit_conn zones are available only in the API (R19+), not in the status module
+        :return: dict
+        """
+        limits = dict()
+        if not self.api_url:
+            return limits
+
+        for key, path in (('limit_reqs', 'http/limit_reqs'), ('limit_conns', 'http/limit_conns')):
+            raw_data = self._get_raw_data('{0}/{1}'.format(self.api_url.rstrip('/'), path))
+            if not raw_data:
+                continue
+            try:
+                limits[key] = loads(raw_data)
+            except ValueError:
+                continue
+        return limits
+
 
 def parse_json(raw_data, metrics):
     data = dict()
@@ -0,0 +1,213 @@
+# -*- coding: utf-8 -*-
+# Description: nsq netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+from json import loads
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+API_STATS = 'stats?format=json&include_clients=false'
+
+ORDER = [
+    'health',
+    'messages',
+    'depth',
+    'topology',
+]
+
+CHARTS = {
+    'health': {
+        'options': [None, 'Health', 'status', 'overview', 'nsq.health', 'line'],
+        'lines': [
+            ['healthy', 'healthy'],
+        ]
+    },
+    'messages': {
+        'options': [None, 'Messages Published', 'messages/s', 'overview', 'nsq.messages', 'line'],
+        'lines': [
+            ['messages', 'published', 'incremental'],
+        ]
+    },
+    'depth': {
+        'options': [None, 'Messages Queued', 'messages', 'overview', 'nsq.depth', 'stacked'],
+        'lines': [
+            ['depth_memory', 'memory'],
+            ['depth_backend', 'disk'],
+        ]
+    },
+    'topology': {
+        'options': [None, 'Topics, Channels And Clients', 'count', 'overview', 'nsq.topology', 'line'],
+        'lines': [
+            ['topics', 'topics'],
+            ['channels', 'channels'],
+            ['clients', 'clients'],
+        ]
+    },
+}
+
+
+def topic_charts(topic_id, topic):
+    order = [
+        'topic_{0}_messages'.format(topic_id),
+        'topic_{0}_depth'.format(topic_id),
+    ]
+    family = 'topic ' + topic
+    charts = {
+        order[0]: {
+            'options': [None, 'Topic Messages', 'messages/s', family, 'nsq.topic_messages', 'line'],
+            'lines': [
+                ['topic_{0}_messages'.format(topic_id), 'published', 'incremental'],
+                ['topic_{0}_requeues'.format(topic_id), 'requeued', 'incremental'],
+                ['topic_{0}_timeouts'.format(topic_id), 'timed_out', 'incremental'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Topic Messages Queued', 'messages', family, 'nsq.topic_depth', 'stacked'],
+            'lines': [
+                ['topic_{0}_depth_memory'.format(topic_id), 'memory'],
+                ['topic_{0}_depth_backend'.format(topic_id), 'disk'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def channel_charts(channel_id, topic, channel):
+    order = [
+        'channel_{0}_messages'.format(channel_id),
+        'channel_{0}_depth'.format(channel_id),
+        'channel_{0}_clients'.format(channel_id),
+    ]
+    family = 'topic ' + topic
+    title = 'Channel {0} '.format(channel)
+    charts = {
+        order[0]: {
+            'options': [None, title + 'Messages', 'messages/s', family, 'nsq.channel_messages', 'line'],
+            'lines': [
+                ['channel_{0}_messages'.format(channel_id), 'received', 'incremental'],
+                ['channel_{0}_requeues'.format(channel_id), 'requeued', 'incremental'],
+                ['channel_{0}_timeouts'.format(channel_id), 'timed_out', 'incremental'],
+            ]
+        },
+        order[1]: {
+            'options': [None, title + 'Messages Pending', 'messages', family, 'nsq.channel_depth', 'stacked'],
+            'lines': [
+                ['channel_{0}_depth_memory'.format(channel_id), 'memory'],
+                ['channel_{0}_depth_backend'.format(channel_id), 'disk'],
+                ['channel_{0}_in_flight'.format(channel_id), 'in_flight'],
+                ['channel_{0}_deferred'.format(channel_id), 'deferred'],
+            ]
+        },
+        order[2]: {
+            'options': [None, title + 'Clients', 'clients', family, 'nsq.channel_clients', 'line'],
+            'lines': [
+                ['channel_{0}_clients'.format(channel_id), 'clients'],
+            ]
+        },
+    }
+    return order, charts
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:4151').rstrip('/')
+        self.channel_charts = self.configuration.get('channel_charts', True)
+        self.collected_topics = set()
+        self.collected_channels = set()
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_STATS))
+        if not raw:
+            return None
+
+        try:
+            stats = loads(raw)
+        except ValueError as error:
+            self.error('invalid nsqd stats: {0}'.format(error))
+            return None
+
+        # nsqd before 1.0 wraps the response in a status envelope
+        if 'data' in stats and 'status_code' in stats:
+            stats = stats['data']
+        if not isinstance(stats, dict) or 'topics' not in stats:
+            self.error('no topics in the nsqd stats response')
+            return None
+
+        data = {
+            'healthy': int(stats.get('health') == 'OK'),
+            'messages': 0,
+            'depth_memory': 0,
+            'depth_backend': 0,
+            'topics': 0,
+            'channels': 0,
+            'clients': 0,
+        }
+        topics, channels = set(), set()
+
+        for topic in stats['topics'] or list():
+            name = topic['topic_name']
+            topic_id = clean_name(name)
+            topics.add(topic_id)
+            if topic_id not in self.collected_topics:
+                self.collected_topics.add(topic_id)
+                self.add_charts(*topic_charts(topic_id, name))
+
+            prefix = 'topic_{0}_'.format(topic_id)
+            backend = topic.get('backend_depth', 0)
+            data[prefix + 'messages'] = topic.get('message_count', 0)
+            data[prefix + 'depth_memory'] = topic.get('depth', 0) - backend
+            data[prefix + 'depth_backend'] = backend
+            data[prefix + 'requeues'] = 0
+            data[prefix + 'timeouts'] = 0
+
+            data['messages'] += data[prefix + 'messages']
+            data['depth_memory'] += data[prefix + 'depth_memory']
+            data['depth_backend'] += data[prefix + 'depth_backend']
+            data['topics'] += 1
+
+            for channel in topic.get('channels') or list():
+                data[prefix + 'requeues'] += channel.get('requeue_count', 0)
+                data[prefix + 'timeouts'] += channel.get('timeout_count', 0)
+                data['channels'] += 1
+                data['clients'] += channel.get('client_count', len(channel.get('clients') or list()))
+
+                if not self.channel_charts:
+                    continue
+                channel_id = '{0}_{1}'.format(topic_id, clean_name(channel['channel_name']))
+                channels.add(channel_id)
+                if channel_id not in self.collected_channels:
+                    self.collected_channels.add(channel_id)
+                    self.add_charts(*channel_charts(channel_id, name, channel['channel_name']))
+                data.update(self.channel_data(channel_id, channel))
+
+        # ephemeral channels and topics go away with their last client
+        for channel_id in self.collected_channels - channels:
+            self.remove_charts(channel_charts(channel_id, '', '')[0])
+            self.collected_channels.remove(channel_id)
+        for topic_id in self.collected_topics - topics:
+            self.remove_charts(topic_charts(topic_id, '')[0])
+            self.collected_topics.remove(topic_id)
+
+        return data
+
+    @staticmethod
+    def channel_data(channel_id, channel):
+        prefix = 'channel_{0}_'.format(channel_id)
+        backend = channel.get('backend_depth', 0)
+        return {
+            prefix + 'messages': channel.get('message_count', 0),
+            prefix + 'requeues': channel.get('requeue_count', 0),
+            prefix + 'timeouts': channel.get('timeout_count', 0),
+            prefix + 'depth_memory': channel.get('depth', 0) - backend,
+            prefix + 'depth_backend': backend,
+            prefix + 'in_flight': channel.get('in_flight_count', 0),
+            prefix + 'deferred': channel.get('deferred_count', 0),
+            prefix + 'clients': channel.get('client_count', len(channel.get('clients') or list())),
+        }
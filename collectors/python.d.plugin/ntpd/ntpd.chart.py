@@ -32,6 +32,10 @@ ORDER = [
     'sys_stratum',
     'sys_tc',
     'sys_precision',
+    'server_packets',
+    'server_rejected',
+    'server_kod',
+    'server_clients',
     'peer_offset',
     'peer_delay',
     'peer_dispersion',
@@ -110,6 +114,53 @@ CHARTS = {
     }
 }
 
+SERVER_CHARTS = {
+    'server_packets': {
+        'options': [None, 'Packets received from clients', 'packets/s', 'server', 'ntpd.server_packets', 'line'],
+        'lines': [
+            ['ss_received', 'received', 'incremental', 1, PRECISION],
+            ['ss_processed', 'processed', 'incremental', 1, PRECISION]
+        ]
+    },
+    'server_rejected': {
+        'options': [None, 'Packets rejected', 'packets/s', 'server', 'ntpd.server_rejected', 'stacked'],
+        'lines': [
+            ['ss_restricted', 'restricted', 'incremental', 1, PRECISION],
+            ['ss_limited', 'rate limited', 'incremental', 1, PRECISION],
+            ['ss_declined', 'declined', 'incremental', 1, PRECISION],
+            ['ss_badformat', 'bad format', 'incremental', 1, PRECISION],
+            ['ss_badauth', 'bad auth', 'incremental', 1, PRECISION],
+            ['ss_oldver', 'old version', 'incremental', 1, PRECISION]
+        ]
+    },
+    'server_kod': {
+        'options': [None, 'Kiss-o\'-Death packets sent', 'packets/s', 'server', 'ntpd.server_kod', 'line'],
+        'lines': [
+            ['ss_kodsent', 'kod', 'incremental', 1, PRECISION]
+        ]
+    },
+    'server_clients': {
+        'options': [None, 'Recent clients (MRU list entries)', 'clients', 'server', 'ntpd.server_clients', 'line'],
+        'lines': [
+            ['mru_depth', 'clients', 'absolute', 1, PRECISION]
+        ]
+    }
+}
+
+# System variables of the server statistics ('ntpq -c sysstats') and of the MRU list ('ntpq -c monstats')
+SERVER_VARIABLES = [
+    'ss_received',
+    'ss_processed',
+    'ss_restricted',
+    'ss_limited',
+    'ss_declined',
+    'ss_badformat',
+    'ss_badauth',
+    'ss_oldver',
+    'ss_kodsent',
+    'mru_depth',
+]
+
 PEER_CHARTS = {
     'peer_offset': {
         'options': [None, 'Filter offset', 'milliseconds', 'peers', 'ntpd.peer_offset', 'line'],
@@ -172,7 +223,7 @@ class Base:
     regex = re.compile(r'([a-z_]+)=((?:-)?[0-9]+(?:\.[0-9]+)?)')
 
     @staticmethod
-    def get_header(associd=0, operation='readvar'):
+    def get_header(associd=0, operation='readvar', variables=None):
         """
         Constructs the NTP Control Message header:
          0                   1                   2                   3
@@ -184,15 +235,19 @@ class Base:
         +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
         |            Offset             |            Count              |
         +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+        A readvar request may carry a comma separated list of variables in the data field,
+        padded to a 32 bit boundary.
         """
         version = 2
         sequence = 1
         status = 0
         offset = 0
-        count = 0
+        data = ','.join(variables).encode() if variables else b''
+        count = len(data)
         header = struct.pack(HEADER_FORMAT, (version << 3 | MODE), OPCODES[operation],
                              sequence, status, associd, offset, count)
-        return header
+        return header + data + b'\0' * (-count % 4)
 
 
 class System(Base):
@@ -209,6 +264,12 @@ class System(Base):
         return data
 
 
+class Server(System):
+    def __init__(self):
+        System.__init__(self)
+        self.request = self.get_header(variables=SERVER_VARIABLES)
+
+
 class Peer(Base):
     def __init__(self, idx, name):
         self.id = idx
@@ -235,11 +296,15 @@ class Service(SocketService):
         self.port = 'ntp'
         self.dgram_socket = True
         self.system = System()
+        self.server = Server()
         self.peers = dict()
         self.request = str()
         self.retries = 0
         self.show_peers = self.configuration.get('show_peers', False)
         self.peer_rescan = self.configuration.get('peer_rescan', 60)
+        self.show_server = self.configuration.get('show_server', False)
+        if self.show_server:
+            self.definitions.update(SERVER_CHARTS)
         if self.show_peers:
             self.definitions.update(PEER_CHARTS)
 
@@ -279,6 +344,12 @@ class Service(SocketService):
 
         data.update(self.system.get_data(raw))
 
+        if self.show_server:
+            self.request = self.server.request
+            raw = self._get_raw_data()
+            if raw:
+                data.update(self.server.get_data(raw))
+
         if not self.show_peers:
             return data
 
@@ -0,0 +1,212 @@
+# -*- coding: utf-8 -*-
+# Description: ollama netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import threading
+import time
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 5
+
+API_PS = 'api/ps'
+API_TAGS = 'api/tags'
+API_GENERATE = 'api/generate'
+
+DEFAULT_PROBE_PROMPT = 'Why is the sky blue? Answer in one sentence.'
+DEFAULT_PROBE_EVERY = 60
+DEFAULT_PROBE_TOKENS = 32
+
+# durations of the generate response, in nanoseconds
+PROBE_DURATIONS = (
+    ('load_duration', 'load'),
+    ('prompt_eval_duration', 'prompt_eval'),
+    ('eval_duration', 'eval'),
+    ('total_duration', 'total'),
+)
+
+ORDER = [
+    'models',
+    'models_size',
+    'models_vram',
+    'models_ram',
+    'probe_latency',
+    'probe_tokens',
+]
+
+CHARTS = {
+    'models': {
+        'options': [None, 'Models', 'models', 'models', 'ollama.models', 'line'],
+        'lines': [
+            ['models_loaded', 'loaded'],
+            ['models_installed', 'installed'],
+        ]
+    },
+    'models_size': {
+        'options': [None, 'Installed Models Size', 'GiB', 'models', 'ollama.models_size', 'line'],
+        'lines': [
+            ['models_size', 'size', 'absolute', 1, 1 << 30],
+        ]
+    },
+    'models_vram': {
+        'options': [None, 'Loaded Models VRAM', 'MiB', 'memory', 'ollama.models_vram', 'stacked'],
+        'lines': []
+    },
+    'models_ram': {
+        'options': [None, 'Loaded Models System Memory', 'MiB', 'memory', 'ollama.models_ram', 'stacked'],
+        'lines': []
+    },
+    'probe_latency': {
+        'options': [None, 'Probe Request Latency', 'milliseconds', 'probe', 'ollama.probe_latency', 'line'],
+        'lines': [['probe_' + d, d, 'absolute', 1, 1000] for _, d in PROBE_DURATIONS]
+    },
+    'probe_tokens': {
+        'options': [None, 'Probe Token Throughput', 'tokens/s', 'probe', 'ollama.probe_tokens', 'line'],
+        'lines': [
+            ['probe_prompt_tokens', 'prompt', 'absolute', 1, 100],
+            ['probe_generation_tokens', 'generation', 'absolute', 1, 100],
+        ]
+    },
+}
+
+
+class Prober(threading.Thread):
+    """
+    Runs the probe requests, they last longer than update_every when the model has to be loaded
+    """
+
+    def __init__(self, probe, interval):
+        threading.Thread.__init__(self)
+        self.daemon = True
+        self.probe = probe
+        self.interval = interval
+        self.lock = threading.RLock()
+        self.last_data = dict()
+
+    def run(self):
+        while True:
+            data = self.probe()
+            with self.lock:
+                self.last_data = data
+            time.sleep(self.interval)
+
+    def is_started(self):
+        return self.ident is not None
+
+    def data(self):
+        with self.lock:
+            return dict(self.last_data)
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:11434').rstrip('/')
+        self.probe_model = self.configuration.get('probe_model')
+        self.probe_prompt = self.configuration.get('probe_prompt', DEFAULT_PROBE_PROMPT)
+        self.probe_tokens = self.configuration.get('probe_tokens', DEFAULT_PROBE_TOKENS)
+        self.probe_timeout = self.configuration.get('probe_timeout', 60)
+        self.prober = Prober(self.probe, self.configuration.get('probe_every', DEFAULT_PROBE_EVERY))
+        self.collected_models = set()
+
+    def check(self):
+        if not self.probe_model:
+            self.order = [c for c in self.order if not c.startswith('probe_')]
+        return UrlService.check(self)
+
+    def get_json(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        try:
+            return json.loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(path, error))
+            return None
+
+    def _get_data(self):
+        loaded = self.get_json(API_PS)
+        if loaded is None:
+            return None
+
+        data = {
+            'models_loaded': 0,
+        }
+        for model_id in self.collected_models:
+            data['model_{0}_vram'.format(model_id)] = 0
+            data['model_{0}_ram'.format(model_id)] = 0
+
+        for model in loaded.get('models') or list():
+            name = model.get('name') or model.get('model')
+            model_id = clean_name(name)
+            if model_id not in self.collected_models:
+                self.collected_models.add(model_id)
+                self.add_model_dimensions(model_id, name)
+            size, vram = model.get('size', 0), model.get('size_vram', 0)
+            data['models_loaded'] += 1
+            data['model_{0}_vram'.format(model_id)] = vram
+            # the layers not offloaded to the GPUs
+            data['model_{0}_ram'.format(model_id)] = max(size - vram, 0)
+
+        installed = self.get_json(API_TAGS)
+        if installed is not None:
+            models = installed.get('models') or list()
+            data['models_installed'] = len(models)
+            data['models_size'] = sum(m.get('size', 0) for m in models)
+
+        if self.probe_model:
+            if not self.prober.is_started():
+                self.prober.start()
+            data.update(self.prober.data())
+
+        return data
+
+    def probe(self):
+        """
+        Generate a short answer with the probe model, loading it if needed.
+        :return: <dict> latency in microseconds and tokens/s * 100
+        """
+        url = '{0}/{1}'.format(self.url, API_GENERATE)
+        body = {
+            'model': self.probe_model,
+            'prompt': self.probe_prompt,
+            'stream': False,
+            'options': {'num_predict': self.probe_tokens},
+        }
+        headers = dict(self._manager.headers)
+        headers['Content-Type'] = 'application/json'
+        try:
+            response = self._manager.request('POST', url, body=json.dumps(body), headers=headers,
+                                             timeout=self.probe_timeout)
+            if response.status != 200:
+                self.error('probe {0}: http response status code {1}'.format(self.probe_model, response.status))
+                return dict()
+            result = json.loads(response.data.decode(errors='ignore'))
+        except Exception as error:
+            self.error('probe {0}: {1}'.format(self.probe_model, error))
+            return dict()
+
+        data = dict()
+        for key, duration in PROBE_DURATIONS:
+            data['probe_' + duration] = result.get(key, 0) // 1000
+        for count, duration, dimension in (('prompt_eval_count', 'prompt_eval_duration', 'probe_prompt_tokens'),
+                                           ('eval_count', 'eval_duration', 'probe_generation_tokens')):
+            # the prompt is not evaluated again when it is cached
+            if result.get(duration):
+                data[dimension] = int(result.get(count, 0) * 1e9 * 100 / result[duration])
+            else:
+                data[dimension] = 0
+        return data
+
+    def add_model_dimensions(self, model_id, name):
+        dimensions = (
+            ('models_vram', ['model_{0}_vram'.format(model_id), name, 'absolute', 1, 1 << 20]),
+            ('models_ram', ['model_{0}_ram'.format(model_id), name, 'absolute', 1, 1 << 20]),
+        )
+        for chart, dimension in dimensions:
+            self.add_dimension(chart, dimension)
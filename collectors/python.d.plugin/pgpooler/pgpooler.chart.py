@@ -0,0 +1,260 @@
+# -*- coding: utf-8 -*-
+# Description: pgcat and odyssey postgres poolers netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+try:
+    import psycopg2
+    from psycopg2 import extensions
+    from psycopg2.extras import DictCursor
+    from psycopg2 import Error
+
+    PSYCOPG2 = True
+except ImportError:
+    PSYCOPG2 = False
+
+from copy import deepcopy
+
+from bases.FrameworkServices.SimpleService import SimpleService
+from bases.collection import clean_name
+
+update_every = 5
+
+PGCAT = 'pgcat'
+ODYSSEY = 'odyssey'
+
+# the admin console of the pooler is a virtual database
+CONSOLE_DATABASES = {
+    PGCAT: 'pgcat',
+    ODYSSEY: 'console',
+}
+
+DEFAULT_PORT = 6432
+DEFAULT_USER = 'admin'
+DEFAULT_CONNECT_TIMEOUT = 2  # seconds
+
+# both consoles answer these like pgbouncer, pgcat reports the stats per database and user, odyssey per database
+QUERY_POOLS = 'SHOW POOLS'
+QUERY_STATS = 'SHOW STATS'
+
+CLIENT_STATES = ('active', 'idle', 'waiting')
+SERVER_STATES = ('active', 'idle', 'used', 'tested', 'login')
+
+ORDER = [
+    'pooler',
+]
+
+CHARTS = {
+    'pooler': {
+        'options': [None, 'Pools And Databases', 'count', 'pooler', 'pgpooler.pooler', 'line'],
+        'lines': [
+            ['pools', 'pools', 'absolute'],
+            ['databases', 'databases', 'absolute'],
+        ]
+    },
+}
+
+
+def pool_charts(pool_id, pool):
+    order = [
+        'pool_{0}_clients'.format(pool_id),
+        'pool_{0}_servers'.format(pool_id),
+        'pool_{0}_saturation'.format(pool_id),
+        'pool_{0}_maxwait'.format(pool_id),
+    ]
+    family = 'pool ' + pool
+    charts = {
+        order[0]: {
+            'options': [None, 'Client Connections', 'connections', family, 'pgpooler.pool_clients', 'stacked'],
+            'lines': [['pool_{0}_cl_{1}'.format(pool_id, s), s] for s in CLIENT_STATES]
+        },
+        order[1]: {
+            'options': [None, 'Server Connections', 'connections', family, 'pgpooler.pool_servers', 'stacked'],
+            'lines': [['pool_{0}_sv_{1}'.format(pool_id, s), s] for s in SERVER_STATES]
+        },
+        order[2]: {
+            'options': [None, 'Pool Saturation', 'percentage', family, 'pgpooler.pool_saturation', 'area'],
+            'lines': [
+                ['pool_{0}_saturation'.format(pool_id), 'saturation', 'absolute', 1, 100],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Oldest Waiting Client', 'milliseconds', family, 'pgpooler.pool_maxwait', 'line'],
+            'lines': [
+                ['pool_{0}_maxwait'.format(pool_id), 'wait', 'absolute', 1, 1000],
+            ]
+        },
+    }
+    return order, charts
+
+
+def database_charts(database_id, database):
+    order = [
+        'database_{0}_requests'.format(database_id),
+        'database_{0}_traffic'.format(database_id),
+    ]
+    family = 'database ' + database
+    charts = {
+        order[0]: {
+            'options': [None, 'Queries And Transactions', 'requests/s', family, 'pgpooler.database_requests',
+                        'line'],
+            'lines': [
+                ['database_{0}_queries'.format(database_id), 'queries', 'incremental'],
+                ['database_{0}_transactions'.format(database_id), 'transactions', 'incremental'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Traffic', 'KiB/s', family, 'pgpooler.database_traffic', 'area'],
+            'lines': [
+                ['database_{0}_received'.format(database_id), 'received', 'incremental', 1, 1 << 10],
+                ['database_{0}_sent'.format(database_id), 'sent', 'incremental', -1, 1 << 10],
+            ]
+        },
+    }
+    return order, charts
+
+
+def number(row, column):
+    try:
+        return int(row[column] or 0)
+    except (KeyError, ValueError):
+        return 0
+
+
+class Service(SimpleService):
+    def __init__(self, configuration=None, name=None):
+        SimpleService.__init__(self, configuration=configuration, name=name)
+        # the charts of the pools and the databases are appended at runtime
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.pooler = self.configuration.get('pooler', PGCAT)
+        self.conn = None
+        self.alive = False
+        # pool/database id -> <list> of its charts
+        self.collected_pools = dict()
+        self.collected_databases = dict()
+
+    def build_conn_params(self):
+        conf = self.configuration
+        if conf.get('dsn'):
+            return {'dsn': conf['dsn']}
+        # the consoles do not know the 'options' startup parameter, no statement_timeout
+        return {
+            'host': conf.get('host'),
+            'port': conf.get('port', DEFAULT_PORT),
+            'database': conf.get('database', CONSOLE_DATABASES[self.pooler]),
+            'user': conf.get('user', DEFAULT_USER),
+            'password': conf.get('password'),
+            'connect_timeout': conf.get('connect_timeout', DEFAULT_CONNECT_TIMEOUT),
+        }
+
+    def connect(self):
+        if self.conn:
+            self.conn.close()
+            self.conn = None
+
+        try:
+            self.conn = psycopg2.connect(**self.build_conn_params())
+            # the consoles do not support transactions
+            self.conn.set_isolation_level(extensions.ISOLATION_LEVEL_AUTOCOMMIT)
+        except Error as error:
+            self.error(error)
+            self.alive = False
+        else:
+            self.alive = True
+
+        return self.alive
+
+    def check(self):
+        if not PSYCOPG2:
+            self.error("'python-psycopg2' package is needed to use pgpooler module")
+            return False
+
+        if self.pooler not in CONSOLE_DATABASES:
+            self.error("unknown pooler '{0}', expected one of {1}".format(self.pooler, sorted(CONSOLE_DATABASES)))
+            return False
+
+        if not self.connect():
+            return False
+
+        return bool(self.get_data())
+
+    def _get_data(self):
+        if not self.alive and not self.connect():
+            return None
+
+        try:
+            cursor = self.conn.cursor(cursor_factory=DictCursor)
+            cursor.execute(QUERY_POOLS)
+            pools = cursor.fetchall()
+            cursor.execute(QUERY_STATS)
+            stats = cursor.fetchall()
+            cursor.close()
+        except Error as error:
+            self.error(error)
+            self.alive = False
+            return None
+
+        data = dict()
+        data.update(self.pools_data(pools))
+        data.update(self.databases_data(stats))
+        data['pools'] = len(self.collected_pools)
+        data['databases'] = len(self.collected_databases)
+        return data
+
+    def pools_data(self, rows):
+        data = dict()
+        seen = set()
+        for row in rows:
+            pool = '{0}/{1}'.format(row['database'], row['user'])
+            pool_id = clean_name(pool)
+            seen.add(pool_id)
+            if pool_id not in self.collected_pools:
+                self.add_collected_charts(self.collected_pools, pool_id, *pool_charts(pool_id, pool))
+
+            prefix = 'pool_{0}_'.format(pool_id)
+            # odyssey has no 'cl_idle'
+            for state in CLIENT_STATES:
+                data[prefix + 'cl_' + state] = number(row, 'cl_' + state)
+            for state in SERVER_STATES:
+                data[prefix + 'sv_' + state] = number(row, 'sv_' + state)
+
+            # the share of the server connections of the pool that run a query or a transaction
+            servers = sum(data[prefix + 'sv_' + s] for s in SERVER_STATES)
+            data[prefix + 'saturation'] = data[prefix + 'sv_active'] * 100 * 100 // servers if servers else 0
+            data[prefix + 'maxwait'] = number(row, 'maxwait') * 1000000 + number(row, 'maxwait_us')
+
+        # pools removed from the config
+        for pool_id in set(self.collected_pools) - seen:
+            self.remove_collected_charts(self.collected_pools, pool_id)
+        return data
+
+    def databases_data(self, rows):
+        data = dict()
+        seen = set()
+        for row in rows:
+            database_id = clean_name(row['database'])
+            seen.add(database_id)
+            if database_id not in self.collected_databases:
+                self.add_collected_charts(self.collected_databases, database_id,
+                                          *database_charts(database_id, row['database']))
+
+            # pgcat has one row per user of the database
+            prefix = 'database_{0}_'.format(database_id)
+            for key, column in (
+                    ('queries', 'total_query_count'),
+                    ('transactions', 'total_xact_count'),
+                    ('received', 'total_received'),
+                    ('sent', 'total_sent'),
+            ):
+                data[prefix + key] = data.get(prefix + key, 0) + number(row, column)
+
+        for database_id in set(self.collected_databases) - seen:
+            self.remove_collected_charts(self.collected_databases, database_id)
+        return data
+
+    def add_collected_charts(self, collected, key, order, charts):
+        collected[key] = order
+        self.add_charts(order, charts)
+
+    def remove_collected_charts(self, collected, key):
+        self.remove_charts(collected.pop(key))
@@ -49,9 +49,12 @@ QUERY_NAME_BLOCKERS = 'BLOCKERS'
 QUERY_NAME_DATABASES = 'DATABASES'
 QUERY_NAME_STANDBY = 'STANDBY'
 QUERY_NAME_REPLICATION_SLOT = 'REPLICATION_SLOT'
+QUERY_NAME_REPLICATION_SLOT_APPLICATION = 'REPLICATION_SLOT_APPLICATION'
+QUERY_NAME_LOGICAL_REPLICATION_SLOT = 'LOGICAL_REPLICATION_SLOT'
 QUERY_NAME_STANDBY_DELTA = 'STANDBY_DELTA'
 QUERY_NAME_STANDBY_LAG = 'STANDBY_LAG'
 QUERY_NAME_REPSLOT_FILES = 'REPSLOT_FILES'
+QUERY_NAME_REPSLOT_LAG = 'REPSLOT_LAG'
 QUERY_NAME_IF_SUPERUSER = 'IF_SUPERUSER'
 QUERY_NAME_SERVER_VERSION = 'SERVER_VERSION'
 QUERY_NAME_AUTOVACUUM = 'AUTOVACUUM'
@@ -158,6 +161,11 @@ METRICS = {
     QUERY_NAME_REPSLOT_FILES: [
         'replslot_wal_keep',
         'replslot_files'
+    ],
+    QUERY_NAME_REPSLOT_LAG: [
+        'replslot_confirmed_flush_lag',
+        'replslot_wal_retained',
+        'replslot_active'
     ]
 }
 
@@ -477,6 +485,24 @@ FROM pg_replication_slots;
 """
 }
 
+QUERY_LOGICAL_REPLICATION_SLOT = {
+    DEFAULT: """
+SELECT slot_name
+FROM pg_replication_slots
+WHERE slot_type = 'logical';
+"""
+}
+
+QUERY_REPLICATION_SLOT_APPLICATION = {
+    DEFAULT: """
+SELECT
+    prs.slot_name,
+    psr.application_name
+FROM pg_replication_slots prs
+LEFT OUTER JOIN pg_stat_replication psr on psr.pid = prs.active_pid;
+"""
+}
+
 QUERY_STANDBY_DELTA = {
     DEFAULT: """
 SELECT
@@ -555,6 +581,28 @@ WHERE application_name IS NOT NULL;
 """
 }
 
+QUERY_REPSLOT_LAG = {
+    DEFAULT: """
+SELECT
+    slot_name,
+    pg_wal_lsn_diff(
+      CASE pg_is_in_recovery()
+        WHEN true THEN pg_last_wal_receive_lsn()
+        ELSE pg_current_wal_lsn()
+      END,
+    confirmed_flush_lsn) AS replslot_confirmed_flush_lag,
+    pg_wal_lsn_diff(
+      CASE pg_is_in_recovery()
+        WHEN true THEN pg_last_wal_receive_lsn()
+        ELSE pg_current_wal_lsn()
+      END,
+    restart_lsn) AS replslot_wal_retained,
+    active::int AS replslot_active
+FROM pg_replication_slots
+WHERE slot_type = 'logical';
+""",
+}
+
 QUERY_REPSLOT_FILES = {
     DEFAULT: """
 WITH wal_size AS (
@@ -804,6 +852,12 @@ def query_factory(name, version=NO_VERSION):
         return QUERY_STANDBY_DELTA[DEFAULT]
     elif name == QUERY_NAME_STANDBY_LAG:
         return QUERY_STANDBY_LAG[DEFAULT]
+    elif name == QUERY_NAME_REPLICATION_SLOT_APPLICATION:
+        return QUERY_REPLICATION_SLOT_APPLICATION[DEFAULT]
+    elif name == QUERY_NAME_LOGICAL_REPLICATION_SLOT:
+        return QUERY_LOGICAL_REPLICATION_SLOT[DEFAULT]
+    elif name == QUERY_NAME_REPSLOT_LAG:
+        return QUERY_REPSLOT_LAG[DEFAULT]
     elif name == QUERY_NAME_REPSLOT_FILES:
         if version < 110000:
             return QUERY_REPSLOT_FILES[V10]
@@ -1076,6 +1130,21 @@ CHARTS = {
             ['replslot_wal_keep', 'wal keeped', 'absolute'],
             ['replslot_files', 'pg_replslot files', 'absolute']
         ]
+    },
+    'replication_slot_lag': {
+        'options': [None, 'Replication slot lag', 'KiB', 'replication slot lag', 'postgres.replication_slot_lag',
+                    'line'],
+        'lines': [
+            ['replslot_confirmed_flush_lag', 'confirmed flush lag', 'absolute', 1, 1024],
+            ['replslot_wal_retained', 'wal retained', 'absolute', 1, 1024]
+        ]
+    },
+    'replication_slot_active': {
+        'options': [None, 'Replication slot active', 'status', 'replication slot lag',
+                    'postgres.replication_slot_active', 'line'],
+        'lines': [
+            ['replslot_active', 'active', 'absolute']
+        ]
     }
 }
 
@@ -1097,6 +1166,8 @@ class Service(SimpleService):
         self.databases = list()
         self.secondaries = list()
         self.replication_slots = list()
+        self.replication_slots_applications = dict()
+        self.logical_replication_slots = list()
         self.queries = dict()
         self.data = dict()
 
@@ -1249,6 +1320,12 @@ class Service(SimpleService):
             self.replication_slots = discover(cursor, query_factory(QUERY_NAME_REPLICATION_SLOT))
             self.debug('discovered replication slots: {0}'.format(self.replication_slots))
 
+        if self.server_version >= 100000:
+            cursor.execute(query_factory(QUERY_NAME_REPLICATION_SLOT_APPLICATION))
+            self.replication_slots_applications = dict((slot, app) for slot, app in cursor if app)
+            self.logical_replication_slots = discover(cursor, query_factory(QUERY_NAME_LOGICAL_REPLICATION_SLOT))
+            self.debug('discovered logical replication slots: {0}'.format(self.logical_replication_slots))
+
         cursor.close()
 
     def populate_queries(self):
@@ -1284,6 +1361,7 @@ class Service(SimpleService):
 
         if self.server_version >= 100000:
             self.queries[query_factory(QUERY_NAME_STANDBY_LAG)] = METRICS[QUERY_NAME_STANDBY_LAG]
+            self.queries[query_factory(QUERY_NAME_REPSLOT_LAG)] = METRICS[QUERY_NAME_REPSLOT_LAG]
 
     def create_dynamic_charts(self):
         for database_name in self.databases[::-1]:
@@ -1330,7 +1408,20 @@ class Service(SimpleService):
                 definitions=self.definitions,
                 name='replication_slot',
                 slot_name=slot_name,
+                chart_family='replication slot files',
             )
+            # physical slots have no confirmed_flush_lsn
+            if slot_name not in self.logical_replication_slots:
+                continue
+            for name in ('replication_slot_lag', 'replication_slot_active'):
+                add_replication_slot_chart(
+                    order=self.order,
+                    definitions=self.definitions,
+                    name=name,
+                    slot_name=slot_name,
+                    chart_family='replication slot lag',
+                    application_name=self.replication_slots_applications.get(slot_name),
+                )
 
 
 def discover(cursor, query):
@@ -1418,7 +1509,7 @@ def add_replication_standby_chart(order, definitions, name, application_name, ch
         'lines': create_lines(application_name, chart_template['lines'])}
 
 
-def add_replication_slot_chart(order, definitions, name, slot_name):
+def add_replication_slot_chart(order, definitions, name, slot_name, chart_family, application_name=None):
     def create_lines(slot, lines):
         result = list()
         for line in lines:
@@ -1431,6 +1522,9 @@ def add_replication_slot_chart(order, definitions, name, slot_name):
     position = order.index('database_size')
     order.insert(position, chart_name)
     name, title, units, _, context, chart_type = chart_template['options']
+    title = title + ': ' + slot_name
+    if application_name:
+        title += ' (' + application_name + ')'
     definitions[chart_name] = {
-        'options': [name, title + ': ' + slot_name, units, 'replication slot files', context, chart_type],
+        'options': [name, title, units, chart_family, context, chart_type],
         'lines': create_lines(slot_name, chart_template['lines'])}
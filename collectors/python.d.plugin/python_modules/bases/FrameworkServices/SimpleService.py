@@ -76,6 +76,7 @@ class SimpleService(PythonDLimitedLogger, object):
         self.configuration = configuration
         self.order = list()
         self.definitions = dict()
+        self.charts_created = False
 
         self.module_name = clean_module_name(self.__module__)
         self.job_name = configuration.pop('job_name')
@@ -174,10 +175,68 @@ class SimpleService(PythonDLimitedLogger, object):
 
         del self.order
         del self.definitions
+        self.charts_created = True
 
         # True if job has at least 1 chart else False
         return bool(self.charts)
 
+    def add_charts(self, order, charts):
+        """
+        Adds charts found at runtime, before or after create()
+        :param order: <list> of chart names
+        :param charts: <dict> of chart definitions
+        :return:
+        """
+        for chart_name in order:
+            if not self.charts_created:
+                # charts have not been created yet, create() will handle them
+                self.order.append(chart_name)
+                self.definitions[chart_name] = charts[chart_name]
+                continue
+
+            try:
+                self.charts.add_chart([chart_name] + charts[chart_name]['options'])
+            except ChartError as error:
+                self.error("add_charts() => [NOT ADDED] (chart '{chart}': {error})".format(chart=chart_name,
+                                                                                           error=error))
+                continue
+
+            for dimension in charts[chart_name]['lines']:
+                try:
+                    self.charts[chart_name].add_dimension(dimension)
+                except ChartError as error:
+                    self.error("add_charts() => [NOT ADDED] (dimension '{dimension}': {error})".format(
+                        dimension=dimension, error=error))
+
+    def add_dimension(self, chart_name, dimension):
+        """
+        Adds a dimension found at runtime, before or after create()
+        :param chart_name: <str>
+        :param dimension: <list>
+        :return:
+        """
+        if not self.charts_created:
+            # charts have not been created yet, create() will handle them
+            self.definitions[chart_name]['lines'].append(dimension)
+            return
+
+        try:
+            self.charts[chart_name].add_dimension(dimension)
+        except ChartError as error:
+            self.error("add_dimension() => [NOT ADDED] (dimension '{dimension}': {error})".format(dimension=dimension,
+                                                                                                  error=error))
+
+    def remove_charts(self, order):
+        """
+        Obsoletes the charts of things that are gone
+        :param order: <list> of chart names
+        :return:
+        """
+        for chart_name in order:
+            if chart_name in self.charts:
+                self.charts[chart_name].obsolete()
+                del self.charts[chart_name]
+
     def run(self):
         """
         Runs job in thread. Handles retries.
@@ -7,7 +7,7 @@ from bases.collection import safe_print
 
 CHART_PARAMS = ['type', 'id', 'name', 'title', 'units', 'family', 'context', 'chart_type', 'hidden']
 DIMENSION_PARAMS = ['id', 'name', 'algorithm', 'multiplier', 'divisor', 'hidden']
-VARIABLE_PARAMS = ['id', 'value']
+VARIABLE_PARAMS = ['id', 'value', 'key']
 
 CHART_TYPES = ['line', 'area', 'stacked']
 DIMENSION_ALGORITHMS = ['absolute', 'incremental', 'percentage-of-absolute-row', 'percentage-of-incremental-row']
@@ -365,6 +365,9 @@ class ChartVariable:
 
         self.params = dict(zip(VARIABLE_PARAMS, params))
         self.params.setdefault('value', None)
+        # data key, defaults to the variable id
+        if not self.params.get('key'):
+            self.params['key'] = self.params['id']
 
     def __getattr__(self, item):
         try:
@@ -402,8 +405,12 @@ class ChartVariable:
 
     def get_value(self, data):
         try:
-            return int(data[self.id])
-        except (KeyError, TypeError):
+            value = data[self.key]
+            # variables are not scaled by a divisor, keep the fractional part
+            if isinstance(value, float):
+                return value
+            return int(value)
+        except (KeyError, TypeError, ValueError):
             return None
 
 
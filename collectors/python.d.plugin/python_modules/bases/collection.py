@@ -115,3 +115,12 @@ def unicode_str(arg):
         return unicode(arg)
     except NameError:
         return str(arg)
+
+
+def clean_name(name):
+    """Replaces the characters that are not allowed in chart and dimension ids with underscores.
+
+    :param name:
+    :return: <str>
+    """
+    return ''.join(c if c.isalnum() else '_' for c in unicode_str(name))
@@ -0,0 +1,62 @@
+# -*- coding: utf-8 -*-
+# Description: Prometheus text exposition format parser
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import re
+
+RE_SAMPLE = re.compile(r'^([a-zA-Z_:][a-zA-Z0-9_:]*)(?:\{(.*)\})?\s+(\S+)(?:\s+-?\d+)?$')
+RE_LABEL = re.compile(r'([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"')
+
+
+def unescape(value):
+    return value.replace('\\"', '"').replace('\\n', '\n').replace('\\\\', '\\')
+
+
+def parse(text, names=None):
+    """
+    Parse the Prometheus text exposition format.
+    :param text: <str>
+    :param names: <container> metric names to keep, all if None
+    :return: <list> of (name, labels <dict>, value <float>)
+    """
+    samples = list()
+    for line in text.splitlines():
+        line = line.strip()
+        if not line or line.startswith('#'):
+            continue
+
+        match = RE_SAMPLE.match(line)
+        if not match:
+            continue
+
+        name, labels, value = match.groups()
+        if names is not None and name not in names:
+            continue
+
+        try:
+            value = float(value)
+        except ValueError:
+            continue
+        if value != value:
+            # NaN
+            continue
+
+        samples.append((name, dict((k, unescape(v)) for k, v in RE_LABEL.findall(labels or '')), value))
+    return samples
+
+
+def sum_by(samples, name, label=None):
+    """
+    Sum the values of a metric, grouped by the value of a label.
+    :param samples: <list> parse() output
+    :param name: <str> metric name
+    :param label: <str> label to group by, None to sum all series
+    :return: <dict> label value -> sum, or {None: sum}
+    """
+    result = dict()
+    for sample_name, labels, value in samples:
+        if sample_name != name:
+            continue
+        key = labels.get(label, '') if label else None
+        result[key] = result.get(key, 0) + value
+    return result
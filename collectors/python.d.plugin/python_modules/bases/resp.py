@@ -0,0 +1,97 @@
+# -*- coding: utf-8 -*-
+# Description: Redis serialization protocol (RESP) helpers
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import socket
+
+
+class ReplyError(Exception):
+    pass
+
+
+class Incomplete(Exception):
+    pass
+
+
+def encode_command(*args):
+    parts = ['*{0}\r\n'.format(len(args))]
+    for arg in args:
+        arg = str(arg)
+        parts.append('${0}\r\n{1}\r\n'.format(len(arg.encode()), arg))
+    return ''.join(parts).encode()
+
+
+def parse_reply(buf, pos=0):
+    """
+    Parse one RESP reply starting at pos. Error replies are returned as ReplyError instances.
+    :return: (reply, position after the reply)
+    """
+    end = buf.find(b'\r\n', pos)
+    if end < 0:
+        raise Incomplete()
+    kind, line, pos = buf[pos:pos + 1], buf[pos + 1:end].decode('utf-8', 'replace'), end + 2
+
+    if kind == b'+':
+        return line, pos
+    if kind == b'-':
+        return ReplyError(line), pos
+    if kind == b':':
+        return int(line), pos
+    if kind == b'$':
+        size = int(line)
+        if size < 0:
+            return None, pos
+        if len(buf) < pos + size + 2:
+            raise Incomplete()
+        return buf[pos:pos + size].decode('utf-8', 'replace'), pos + size + 2
+    if kind == b'*':
+        size = int(line)
+        if size < 0:
+            return None, pos
+        items = list()
+        for _ in range(size):
+            item, pos = parse_reply(buf, pos)
+            items.append(item)
+        return items, pos
+    raise ValueError('unexpected reply type {0!r}'.format(kind))
+
+
+def parse_replies(buf):
+    """
+    Parse all the complete replies of buf.
+    :return: (list of replies, unparsed remainder of buf)
+    """
+    replies = list()
+    pos = 0
+    while pos < len(buf):
+        try:
+            reply, pos = parse_reply(buf, pos)
+        except Incomplete:
+            break
+        replies.append(reply)
+    return replies, buf[pos:]
+
+
+def read_replies(sock, count):
+    """
+    Read count replies from a connected socket.
+    :return: <list> of replies
+    """
+    replies, buf = list(), b''
+    while len(replies) < count:
+        chunk = sock.recv(65536)
+        if not chunk:
+            raise socket.error('connection closed')
+        parsed, buf = parse_replies(buf + chunk)
+        replies.extend(parsed)
+    return replies
+
+
+def execute(sock, *commands):
+    """
+    Send the commands in a single request (pipeline) over a connected socket.
+    :param commands: <tuple> of command arguments
+    :return: <list> of replies, one per command
+    """
+    sock.sendall(b''.join(encode_command(*c) for c in commands))
+    return read_replies(sock, len(commands))
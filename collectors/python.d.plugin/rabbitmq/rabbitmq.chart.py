@@ -11,6 +11,8 @@ API_NODE = 'api/nodes'
 API_OVERVIEW = 'api/overview'
 API_QUEUES = 'api/queues'
 API_VHOSTS = 'api/vhosts'
+API_STREAM_PUBLISHERS = 'api/stream/publishers'
+API_STREAM_CONSUMERS = 'api/stream/consumers'
 
 NODE_STATS = [
     'fd_used',
@@ -58,6 +60,14 @@ QUEUE_STATS = [
     'message_stats.return_unroutable',
 ]
 
+QUORUM_QUEUE_STATS = [
+    'memory',
+]
+
+STREAM_STATS = [
+    'committed_offset',
+]
+
 VHOST_MESSAGE_STATS = [
     'message_stats.ack',
     'message_stats.confirm',
@@ -236,6 +246,83 @@ def queue_chart_template(queue_id):
     return order, charts
 
 
+def quorum_queue_chart_template(queue_id):
+    vhost, name = queue_id
+    order = [
+        'vhost_{0}_queue_{1}_leader'.format(vhost, name),
+        'vhost_{0}_queue_{1}_members'.format(vhost, name),
+        'vhost_{0}_queue_{1}_memory'.format(vhost, name),
+    ]
+    family = 'vhost {0}'.format(vhost)
+
+    charts = {
+        order[0]: {
+            'options': [
+                None, 'Quorum queue "{0}" in "{1}" leader on this node'.format(name, vhost), 'status', family,
+                'rabbitmq.queue_leader', 'line'],
+            'lines': [
+                ['vhost_{0}_queue_{1}_leader'.format(vhost, name), 'leader', 'absolute'],
+            ]
+        },
+        order[1]: {
+            'options': [
+                None, 'Quorum queue "{0}" in "{1}" members'.format(name, vhost), 'members', family,
+                'rabbitmq.queue_members', 'line'],
+            'lines': [
+                ['vhost_{0}_queue_{1}_members'.format(vhost, name), 'members', 'absolute'],
+                ['vhost_{0}_queue_{1}_online'.format(vhost, name), 'online', 'absolute'],
+            ]
+        },
+        order[2]: {
+            'options': [
+                None, 'Quorum queue "{0}" in "{1}" memory'.format(name, vhost), 'KiB', family,
+                'rabbitmq.queue_memory', 'area'],
+            'lines': [
+                ['vhost_{0}_queue_{1}_memory'.format(vhost, name), 'used', 'absolute', 1, 1 << 10],
+            ]
+        },
+    }
+
+    return order, charts
+
+
+def stream_chart_template(queue_id):
+    vhost, name = queue_id
+    order = [
+        'vhost_{0}_queue_{1}_stream_clients'.format(vhost, name),
+        'vhost_{0}_queue_{1}_committed_offset'.format(vhost, name),
+    ]
+    family = 'vhost {0}'.format(vhost)
+
+    charts = {
+        order[0]: {
+            'options': [
+                None, 'Stream "{0}" in "{1}" clients'.format(name, vhost), 'clients', family,
+                'rabbitmq.stream_clients', 'line'],
+            'lines': [
+                ['vhost_{0}_queue_{1}_publishers'.format(vhost, name), 'publishers', 'absolute'],
+                ['vhost_{0}_queue_{1}_consumers'.format(vhost, name), 'consumers', 'absolute'],
+            ]
+        },
+        order[1]: {
+            'options': [
+                None, 'Stream "{0}" in "{1}" committed offset'.format(name, vhost), 'offset', family,
+                'rabbitmq.stream_committed_offset', 'line'],
+            'lines': [
+                ['vhost_{0}_queue_{1}_committed_offset'.format(vhost, name), 'committed', 'absolute'],
+            ]
+        },
+    }
+
+    return order, charts
+
+
+QUEUE_TYPE_CHART_TEMPLATES = {
+    'quorum': quorum_queue_chart_template,
+    'stream': stream_chart_template,
+}
+
+
 class VhostStatsBuilder:
     def __init__(self):
         self.stats = None
@@ -269,6 +356,24 @@ class QueueStatsBuilder:
         stats = fetch_data(raw_data=self.stats, metrics=QUEUE_STATS)
         return dict(('vhost_{0}_queue_{1}_{2}'.format(vhost, name, k), v) for k, v in stats.items())
 
+    def type(self):
+        # 'classic', 'quorum' or 'stream', missing before RabbitMQ 3.8
+        return self.stats.get('type', 'classic')
+
+    def quorum_stats(self, node_name):
+        vhost, name = self.id()
+        stats = fetch_data(raw_data=self.stats, metrics=QUORUM_QUEUE_STATS)
+        stats['leader'] = int(self.stats.get('leader') == node_name)
+        stats['members'] = len(self.stats.get('members') or [])
+        stats['online'] = len(self.stats.get('online') or [])
+        return dict(('vhost_{0}_queue_{1}_{2}'.format(vhost, name, k), v) for k, v in stats.items())
+
+    def stream_stats(self, clients):
+        vhost, name = self.id()
+        stats = fetch_data(raw_data=self.stats, metrics=STREAM_STATS)
+        stats['publishers'], stats['consumers'] = clients.get(self.id(), (0, 0))
+        return dict(('vhost_{0}_queue_{1}_{2}'.format(vhost, name, k), v) for k, v in stats.items())
+
 
 class Service(UrlService):
     def __init__(self, configuration=None, name=None):
@@ -382,6 +487,7 @@ class Service(UrlService):
         data = dict()
         queues = loads(raw)
         charts_initialized = len(self.charts) > 0
+        stream_clients = None
 
         for queue in queues:
             self.queue.set(queue)
@@ -390,13 +496,38 @@ class Service(UrlService):
 
             if charts_initialized and self.queue.id() not in self.collected_queues:
                 self.collected_queues.add(self.queue.id())
-                self.add_queue_charts(self.queue.id())
+                self.add_queue_charts(self.queue.id(), self.queue.type())
 
             data.update(self.queue.queue_stats())
 
+            if self.queue.type() == 'quorum':
+                data.update(self.queue.quorum_stats(self.node_name))
+            elif self.queue.type() == 'stream':
+                if stream_clients is None:
+                    stream_clients = self.get_stream_clients()
+                data.update(self.queue.stream_stats(stream_clients))
+
         self.debug("number of queues: {0}, metrics: {1}".format(len(queues), len(data)))
         return data
 
+    def get_stream_clients(self):
+        # needs the rabbitmq_stream_management plugin, streams have no clients without it
+        clients = dict()
+        for idx, api in enumerate((API_STREAM_PUBLISHERS, API_STREAM_CONSUMERS)):
+            url = '{0}/{1}'.format(self.url, api)
+            self.debug("doing http request to '{0}'".format(url))
+            raw = self._get_raw_data(url)
+            if not raw:
+                continue
+
+            for client in loads(raw):
+                queue = client.get('queue') or dict()
+                queue_id = queue.get('vhost'), queue.get('name')
+                counts = clients.setdefault(queue_id, [0, 0])
+                counts[idx] += 1
+
+        return clients
+
     def add_vhost_charts(self, vhost_name):
         order, charts = vhost_chart_template(vhost_name)
 
@@ -408,9 +539,14 @@ class Service(UrlService):
             for dimension in dimensions:
                 new_chart.add_dimension(dimension)
 
-    def add_queue_charts(self, queue_id):
+    def add_queue_charts(self, queue_id, queue_type):
         order, charts = queue_chart_template(queue_id)
 
+        if queue_type in QUEUE_TYPE_CHART_TEMPLATES:
+            type_order, type_charts = QUEUE_TYPE_CHART_TEMPLATES[queue_type](queue_id)
+            order.extend(type_order)
+            charts.update(type_charts)
+
         for chart_name in order:
             params = [chart_name] + charts[chart_name]['options']
             dimensions = charts[chart_name]['lines']
@@ -0,0 +1,209 @@
+# -*- coding: utf-8 -*-
+# Description: ray netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from json import loads
+
+from bases.FrameworkServices.UrlService import UrlService
+
+update_every = 5
+
+API_NODES = 'nodes?view=summary'
+API_TASKS = 'api/v0/tasks/summarize'
+API_ACTORS = 'api/v0/actors/summarize'
+
+TASK_STATES = (
+    'pending',
+    'running',
+    'finished',
+    'failed',
+)
+
+ACTOR_STATES = (
+    ('DEPENDENCIES_UNREADY', 'dependencies_unready'),
+    ('PENDING_CREATION', 'pending_creation'),
+    ('ALIVE', 'alive'),
+    ('RESTARTING', 'restarting'),
+    ('DEAD', 'dead'),
+)
+
+NODE_STATES = (
+    ('ALIVE', 'alive'),
+    ('DEAD', 'dead'),
+)
+
+ORDER = [
+    'nodes',
+    'tasks',
+    'actors',
+    'object_store',
+    'object_store_usage',
+    'gpu_utilization',
+    'gpu_memory',
+]
+
+CHARTS = {
+    'nodes': {
+        'options': [None, 'Nodes', 'nodes', 'cluster', 'ray.nodes', 'stacked'],
+        'lines': [['nodes_' + s, s] for _, s in NODE_STATES]
+    },
+    'tasks': {
+        'options': [None, 'Tasks By State', 'tasks', 'tasks', 'ray.tasks', 'line'],
+        'lines': [['tasks_' + s, s] for s in TASK_STATES]
+    },
+    'actors': {
+        'options': [None, 'Actors By State', 'actors', 'actors', 'ray.actors', 'line'],
+        'lines': [['actors_' + s, s] for _, s in ACTOR_STATES]
+    },
+    'object_store': {
+        'options': [None, 'Object Store Memory', 'MiB', 'object store', 'ray.object_store', 'stacked'],
+        'lines': [
+            ['object_store_available', 'available', 'absolute', 1, 1 << 20],
+            ['object_store_used', 'used', 'absolute', 1, 1 << 20],
+        ]
+    },
+    'object_store_usage': {
+        'options': [None, 'Object Store Memory Usage', 'percentage', 'object store', 'ray.object_store_usage',
+                    'area'],
+        'lines': [
+            ['object_store_used', 'used', 'percentage-of-absolute-row'],
+            ['object_store_available', 'available', 'percentage-of-absolute-row'],
+        ]
+    },
+    'gpu_utilization': {
+        'options': [None, 'GPUs Average Utilization', 'percentage', 'gpu', 'ray.gpu_utilization', 'line'],
+        'lines': [
+            ['gpu_utilization', 'utilization', 'absolute', 1, 100],
+        ]
+    },
+    'gpu_memory': {
+        'options': [None, 'GPUs Memory', 'MiB', 'gpu', 'ray.gpu_memory', 'stacked'],
+        'lines': [
+            ['gpu_memory_free', 'free'],
+            ['gpu_memory_used', 'used'],
+        ]
+    },
+}
+
+
+def task_state(state):
+    """
+    Group the task states of the state api
+    """
+    if state.startswith('RUNNING'):
+        return 'running'
+    if state == 'FINISHED':
+        return 'finished'
+    if state == 'FAILED':
+        return 'failed'
+    # PENDING_*, SUBMITTED_TO_WORKER and NIL
+    return 'pending'
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8265').rstrip('/')
+        # the state api walks the task events of the whole cluster, give it more time than the default
+        self.request_timeout = self.configuration.get('timeout', 5)
+        self.collect_tasks = self.configuration.get('tasks', True)
+        self.collect_actors = self.configuration.get('actors', True)
+
+    def get_json(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        try:
+            response = loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(path, error))
+            return None
+        # {"result": true, "msg": "...", "data": {...}}
+        if not response.get('result'):
+            self.error('{0}: {1}'.format(path, response.get('msg')))
+            return None
+        return response.get('data')
+
+    def _get_data(self):
+        nodes = self.get_json(API_NODES)
+        if nodes is None:
+            return None
+
+        data = dict()
+        data.update(self.nodes_data(nodes.get('summary') or list()))
+        if self.collect_tasks:
+            data.update(self.tasks_data())
+        if self.collect_actors:
+            data.update(self.actors_data())
+
+        return data
+
+    @staticmethod
+    def nodes_data(nodes):
+        data = {
+            'object_store_used': 0,
+            'object_store_available': 0,
+        }
+        for _, state in NODE_STATES:
+            data['nodes_' + state] = 0
+
+        gpus, utilization, memory_used, memory_total = 0, 0, 0, 0
+        for node in nodes:
+            raylet = node.get('raylet') or dict()
+            state = raylet.get('state', 'DEAD')
+            data['nodes_' + dict(NODE_STATES).get(state, 'dead')] += 1
+            if state != 'ALIVE':
+                continue
+
+            data['object_store_used'] += raylet.get('objectStoreUsedMemory', 0)
+            data['object_store_available'] += raylet.get('objectStoreAvailableMemory', 0)
+
+            # reported by the dashboard agent of the node, memory in MiB
+            for gpu in node.get('gpus') or list():
+                gpus += 1
+                utilization += gpu.get('utilizationGpu') or 0
+                memory_used += gpu.get('memoryUsed') or 0
+                memory_total += gpu.get('memoryTotal') or 0
+
+        if gpus:
+            data['gpu_utilization'] = int(utilization * 100 / gpus)
+            data['gpu_memory_used'] = int(memory_used)
+            data['gpu_memory_free'] = int(memory_total - memory_used)
+
+        return data
+
+    def get_summary(self, path):
+        data = self.get_json(path)
+        if not data:
+            return None
+        try:
+            return data['result']['node_id_to_summary']['cluster']['summary']
+        except (KeyError, TypeError):
+            self.error('{0}: unexpected response'.format(path))
+            return None
+
+    def tasks_data(self):
+        summary = self.get_summary(API_TASKS)
+        if summary is None:
+            return dict()
+
+        data = dict(('tasks_' + s, 0) for s in TASK_STATES)
+        for function in summary.values():
+            for state, count in (function.get('state_counts') or dict()).items():
+                data['tasks_' + task_state(state)] += count
+        return data
+
+    def actors_data(self):
+        summary = self.get_summary(API_ACTORS)
+        if summary is None:
+            return dict()
+
+        states = dict(ACTOR_STATES)
+        data = dict(('actors_' + s, 0) for _, s in ACTOR_STATES)
+        for actor_class in summary.values():
+            for state, count in (actor_class.get('state_counts') or dict()).items():
+                if state in states:
+                    data['actors_' + states[state]] += count
+        return data
@@ -0,0 +1,370 @@
+# -*- coding: utf-8 -*-
+# Description: redis backed job queues netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import socket
+import time
+from copy import deepcopy
+
+from bases.FrameworkServices.SimpleService import SimpleService
+from bases.collection import clean_name
+from bases.resp import ReplyError, execute
+
+update_every = 5
+
+DEFAULT_HOST = '127.0.0.1'
+DEFAULT_PORT = 6379
+DEFAULT_DB = 0
+DEFAULT_TIMEOUT = 2
+
+FLAVOR_SIDEKIQ = 'sidekiq'
+FLAVOR_RESQUE = 'resque'
+FLAVOR_BULLMQ = 'bullmq'
+
+# key prefix of every flavor, sidekiq has none unless redis-namespace is used
+DEFAULT_PREFIX = {
+    FLAVOR_SIDEKIQ: '',
+    FLAVOR_RESQUE: 'resque',
+    FLAVOR_BULLMQ: 'bull',
+}
+
+SCAN_COUNT = 1000
+
+ORDER = [
+    'jobs',
+    'sets',
+    'workers',
+]
+
+CHARTS = {
+    'jobs': {
+        'options': [None, 'Jobs', 'jobs/s', 'overview', 'redis_queues.jobs', 'line'],
+        'lines': [
+            ['processed', 'processed', 'incremental'],
+            ['failed', 'failed', 'incremental'],
+        ]
+    },
+    'sets': {
+        'options': [None, 'Jobs Waiting Outside The Queues', 'jobs', 'overview', 'redis_queues.sets', 'line'],
+        'lines': [
+            ['retry', 'retry'],
+            ['schedule', 'scheduled'],
+            ['dead', 'dead'],
+            ['failed_list', 'failed'],
+        ]
+    },
+    'workers': {
+        'options': [None, 'Workers', 'workers', 'overview', 'redis_queues.workers', 'line'],
+        'lines': [
+            ['workers', 'workers'],
+        ]
+    },
+}
+
+
+def queue_charts(queue_id, queue, flavor):
+    order = [
+        'queue_{0}_length'.format(queue_id),
+        'queue_{0}_oldest_job_age'.format(queue_id),
+    ]
+    family = 'queue ' + queue
+    charts = {
+        order[0]: {
+            'options': [None, 'Queue Length', 'jobs', family, 'redis_queues.queue_length', 'stacked'],
+            'lines': [
+                ['queue_{0}_waiting'.format(queue_id), 'waiting'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Oldest Waiting Job Age', 'seconds', family, 'redis_queues.queue_oldest_job_age',
+                        'line'],
+            'lines': [
+                ['queue_{0}_oldest_job_age'.format(queue_id), 'age', 'absolute', 1, 1000],
+            ]
+        },
+    }
+    if flavor != FLAVOR_BULLMQ:
+        return order, charts
+
+    charts[order[0]]['lines'].extend([
+        ['queue_{0}_prioritized'.format(queue_id), 'prioritized'],
+        ['queue_{0}_paused'.format(queue_id), 'paused'],
+        ['queue_{0}_delayed'.format(queue_id), 'delayed'],
+        ['queue_{0}_active'.format(queue_id), 'active'],
+    ])
+    order.extend([
+        'queue_{0}_jobs'.format(queue_id),
+        'queue_{0}_retained'.format(queue_id),
+    ])
+    charts[order[2]] = {
+        'options': [None, 'Jobs', 'jobs/s', family, 'redis_queues.queue_jobs', 'line'],
+        'lines': [
+            ['queue_{0}_added'.format(queue_id), 'added', 'incremental'],
+            ['queue_{0}_completed'.format(queue_id), 'completed', 'incremental'],
+            ['queue_{0}_failed'.format(queue_id), 'failed', 'incremental'],
+        ]
+    }
+    charts[order[3]] = {
+        'options': [None, 'Retained Jobs', 'jobs', family, 'redis_queues.queue_retained', 'line'],
+        'lines': [
+            ['queue_{0}_completed_retained'.format(queue_id), 'completed'],
+            ['queue_{0}_failed_retained'.format(queue_id), 'failed'],
+        ]
+    }
+    return order, charts
+
+
+def to_int(reply):
+    try:
+        return int(reply)
+    except (TypeError, ValueError):
+        return 0
+
+
+class Service(SimpleService):
+    def __init__(self, configuration=None, name=None):
+        SimpleService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.host = self.configuration.get('host', DEFAULT_HOST)
+        self.port = self.configuration.get('port', DEFAULT_PORT)
+        self.db = self.configuration.get('db', DEFAULT_DB)
+        self.password = self.configuration.get('pass')
+        self.timeout = self.configuration.get('timeout', DEFAULT_TIMEOUT)
+        self.flavor = self.configuration.get('flavor', FLAVOR_SIDEKIQ)
+        self.prefix = self.configuration.get('prefix', DEFAULT_PREFIX.get(self.flavor))
+        self.queues = self.configuration.get('queues') or list()
+        self.collected_queues = set()
+
+    def check(self):
+        if self.flavor not in DEFAULT_PREFIX:
+            self.error('unknown flavor "{0}", must be one of {1}'.format(self.flavor, ', '.join(DEFAULT_PREFIX)))
+            return False
+
+        # LLEN and GET of missing keys answer 0 and nil, any redis would pass without looking for the flavor first
+        try:
+            found = self.find_flavor()
+        except (socket.error, ValueError) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return False
+        if not found:
+            self.error('no {0} queue in {1}:{2} db {3}'.format(self.flavor, self.host, self.port, self.db))
+            return False
+
+        if self.flavor == FLAVOR_BULLMQ:
+            # no global counters, everything is kept per queue
+            self.order = list()
+        else:
+            # sidekiq moves the failed jobs to the retry and dead sets, resque to the failed list
+            keep = ('failed_list',) if self.flavor == FLAVOR_RESQUE else ('retry', 'schedule', 'dead')
+            lines = self.definitions['sets']['lines']
+            self.definitions['sets']['lines'] = [line for line in lines if line[0] in keep]
+        return bool(self.get_data())
+
+    def key(self, *parts):
+        return ':'.join(((self.prefix,) if self.prefix else ()) + parts)
+
+    def connect(self):
+        sock = socket.create_connection((self.host, self.port), timeout=self.timeout)
+        commands = list()
+        if self.password:
+            commands.append(('AUTH', self.password))
+        commands.append(('SELECT', self.db))
+        try:
+            for reply in execute(sock, *commands):
+                if isinstance(reply, ReplyError):
+                    raise ValueError(reply)
+        except (socket.error, ValueError):
+            sock.close()
+            raise
+        return sock
+
+    def _get_data(self):
+        try:
+            sock = self.connect()
+        except (socket.error, ValueError) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return None
+
+        try:
+            if self.flavor == FLAVOR_SIDEKIQ:
+                return self.sidekiq_data(sock)
+            if self.flavor == FLAVOR_RESQUE:
+                return self.resque_data(sock)
+            return self.bullmq_data(sock)
+        except (socket.error, ValueError) as error:
+            self.error('{0}:{1}: {2}'.format(self.host, self.port, error))
+            return None
+        finally:
+            sock.close()
+
+    def find_flavor(self):
+        sock = self.connect()
+        try:
+            if self.flavor == FLAVOR_BULLMQ:
+                return bool(self.bullmq_queues(sock))
+            known, processed = execute(
+                sock,
+                ('SMEMBERS', self.key('queues')),
+                ('EXISTS', self.key('stat', 'processed')),
+            )
+        finally:
+            sock.close()
+        return bool(known and isinstance(known, list)) or processed == 1
+
+    def sidekiq_data(self, sock):
+        known, processed, failed, retry, schedule, dead, processes = execute(
+            sock,
+            ('SMEMBERS', self.key('queues')),
+            ('GET', self.key('stat', 'processed')),
+            ('GET', self.key('stat', 'failed')),
+            ('ZCARD', self.key('retry')),
+            ('ZCARD', self.key('schedule')),
+            ('ZCARD', self.key('dead')),
+            ('SCARD', self.key('processes')),
+        )
+        data = {
+            'processed': to_int(processed),
+            'failed': to_int(failed),
+            'retry': to_int(retry),
+            'schedule': to_int(schedule),
+            'dead': to_int(dead),
+            'workers': to_int(processes),
+        }
+
+        queues = self.queues or (known if isinstance(known, list) else list())
+        commands = list()
+        for queue in queues:
+            commands.append(('LLEN', self.key('queue', queue)))
+            # jobs are pushed on the left and fetched from the right
+            commands.append(('LINDEX', self.key('queue', queue), -1))
+        replies = execute(sock, *commands) if commands else list()
+
+        now = time.time()
+        for idx, queue in enumerate(queues):
+            length, oldest = replies[idx * 2:idx * 2 + 2]
+            prefix = self.queue_prefix(queue)
+            data[prefix + 'waiting'] = to_int(length)
+            data[prefix + 'oldest_job_age'] = 0
+            try:
+                enqueued_at = json.loads(oldest)['enqueued_at']
+            except (TypeError, ValueError, KeyError):
+                continue
+            # seconds, or milliseconds since sidekiq 8
+            if enqueued_at > 1e11:
+                enqueued_at /= 1000.0
+            data[prefix + 'oldest_job_age'] = int(max(now - enqueued_at, 0) * 1000)
+
+        return data
+
+    def resque_data(self, sock):
+        known, processed, failed, failed_list, workers = execute(
+            sock,
+            ('SMEMBERS', self.key('queues')),
+            ('GET', self.key('stat', 'processed')),
+            ('GET', self.key('stat', 'failed')),
+            ('LLEN', self.key('failed')),
+            ('SCARD', self.key('workers')),
+        )
+        data = {
+            'processed': to_int(processed),
+            'failed': to_int(failed),
+            'failed_list': to_int(failed_list),
+            'workers': to_int(workers),
+        }
+
+        queues = self.queues or (known if isinstance(known, list) else list())
+        commands = [('LLEN', self.key('queue', queue)) for queue in queues]
+        replies = execute(sock, *commands) if commands else list()
+
+        # resque jobs have no enqueue time, the age of the oldest job is unknown
+        for queue, length in zip(queues, replies):
+            data[self.queue_prefix(queue) + 'waiting'] = to_int(length)
+
+        return data
+
+    def bullmq_data(self, sock):
+        queues = self.queues or self.bullmq_queues(sock)
+        commands = list()
+        for queue in queues:
+            commands.extend([
+                ('LLEN', self.key(queue, 'wait')),
+                ('ZCARD', self.key(queue, 'prioritized')),
+                ('LLEN', self.key(queue, 'paused')),
+                ('ZCARD', self.key(queue, 'delayed')),
+                ('LLEN', self.key(queue, 'active')),
+                ('GET', self.key(queue, 'id')),
+                ('HGET', self.key(queue, 'metrics', 'completed'), 'count'),
+                ('HGET', self.key(queue, 'metrics', 'failed'), 'count'),
+                ('ZCARD', self.key(queue, 'completed')),
+                ('ZCARD', self.key(queue, 'failed')),
+                # jobs are pushed on the left and moved to active from the right
+                ('LINDEX', self.key(queue, 'wait'), -1),
+            ])
+        replies = execute(sock, *commands) if commands else list()
+
+        data = dict()
+        oldest = list()
+        step = len(commands) // len(queues) if queues else 0
+        for idx, queue in enumerate(queues):
+            (waiting, prioritized, paused, delayed, active, added, completed, failed, completed_retained,
+             failed_retained, oldest_id) = replies[idx * step:(idx + 1) * step]
+            prefix = self.queue_prefix(queue)
+            data.update({
+                prefix + 'waiting': to_int(waiting),
+                prefix + 'prioritized': to_int(prioritized),
+                prefix + 'paused': to_int(paused),
+                prefix + 'delayed': to_int(delayed),
+                prefix + 'active': to_int(active),
+                prefix + 'added': to_int(added),
+                prefix + 'completed_retained': to_int(completed_retained),
+                prefix + 'failed_retained': to_int(failed_retained),
+                prefix + 'oldest_job_age': 0,
+            })
+            # the counters are kept only when the queue is created with the 'metrics' option
+            if completed is not None:
+                data[prefix + 'completed'] = to_int(completed)
+            if failed is not None:
+                data[prefix + 'failed'] = to_int(failed)
+            if oldest_id:
+                oldest.append((prefix, self.key(queue, oldest_id)))
+
+        if oldest:
+            now = time.time() * 1000
+            replies = execute(sock, *[('HGET', job, 'timestamp') for _, job in oldest])
+            for (prefix, _), timestamp in zip(oldest, replies):
+                if to_int(timestamp):
+                    data[prefix + 'oldest_job_age'] = int(max(now - to_int(timestamp), 0))
+
+        return data
+
+    def bullmq_queues(self, sock):
+        """
+        Every queue has a '<prefix>:<queue>:meta' hash
+        """
+        pattern = self.key('*', 'meta')
+        queues, cursor = set(), '0'
+        while True:
+            reply = execute(sock, ('SCAN', cursor, 'MATCH', pattern, 'COUNT', SCAN_COUNT))[0]
+            if isinstance(reply, ReplyError):
+                raise ValueError(reply)
+            cursor, keys = reply
+            start = len(self.prefix) + 1 if self.prefix else 0
+            queues.update(k[start:-len(':meta')] for k in keys)
+            if cursor == '0':
+                break
+        return sorted(queues)
+
+    def queue_prefix(self, queue):
+        queue_id = clean_name(queue)
+        if queue_id not in self.collected_queues:
+            self.collected_queues.add(queue_id)
+            self.add_queue_charts(queue_id, queue)
+        return 'queue_{0}_'.format(queue_id)
+
+    def add_queue_charts(self, queue_id, queue):
+        order, charts = queue_charts(queue_id, queue, self.flavor)
+        if self.flavor == FLAVOR_RESQUE:
+            order = order[:1]
+        self.add_charts(order, charts)
@@ -0,0 +1,231 @@
+# -*- coding: utf-8 -*-
+# Description: redis sentinel netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+
+from bases.FrameworkServices.SocketService import SocketService
+from bases.collection import clean_name
+from bases.resp import ReplyError, encode_command, parse_replies
+
+update_every = 5
+
+ORDER = [
+    'masters',
+    'tilt',
+]
+
+CHARTS = {
+    'masters': {
+        'options': [None, 'Monitored Masters', 'masters', 'sentinel', 'redis_sentinel.masters', 'line'],
+        'lines': [
+            ['sentinel_masters', 'masters'],
+        ]
+    },
+    'tilt': {
+        'options': [None, 'TILT Mode', 'status', 'sentinel', 'redis_sentinel.tilt', 'line'],
+        'lines': [
+            ['sentinel_tilt', 'tilt'],
+        ]
+    },
+}
+
+
+def master_charts(master_id, master_name):
+    order = [
+        'master_{0}_status'.format(master_id),
+        'master_{0}_nodes'.format(master_id),
+        'master_{0}_quorum'.format(master_id),
+        'master_{0}_failovers'.format(master_id),
+        'master_{0}_replicas_link'.format(master_id),
+        'master_{0}_replicas_link_down_time'.format(master_id),
+    ]
+    family = master_name
+    charts = {
+        order[0]: {
+            'options': [None, 'Master Status', 'status', family, 'redis_sentinel.master_status', 'line'],
+            'lines': [
+                ['master_{0}_ok'.format(master_id), 'ok'],
+                ['master_{0}_s_down'.format(master_id), 'sdown'],
+                ['master_{0}_o_down'.format(master_id), 'odown'],
+                ['master_{0}_failover_in_progress'.format(master_id), 'failover'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Known Nodes', 'nodes', family, 'redis_sentinel.master_nodes', 'line'],
+            'lines': [
+                ['master_{0}_num_slaves'.format(master_id), 'replicas'],
+                ['master_{0}_num_sentinels'.format(master_id), 'sentinels'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Quorum', 'sentinels', family, 'redis_sentinel.master_quorum', 'line'],
+            'lines': [
+                ['master_{0}_usable_sentinels'.format(master_id), 'usable'],
+                ['master_{0}_quorum'.format(master_id), 'required'],
+                ['master_{0}_quorum_unreachable'.format(master_id), 'unreachable'],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Failovers', 'failovers', family, 'redis_sentinel.master_failovers', 'line'],
+            'lines': [
+                ['master_{0}_config_epoch'.format(master_id), 'failovers', 'incremental'],
+            ]
+        },
+        order[4]: {
+            'options': [None, 'Replicas By Link To Master', 'replicas', family,
+                        'redis_sentinel.master_replicas_link', 'stacked'],
+            'lines': [
+                ['master_{0}_replicas_link_ok'.format(master_id), 'ok'],
+                ['master_{0}_replicas_link_err'.format(master_id), 'down'],
+            ]
+        },
+        order[5]: {
+            'options': [None, 'Longest Replica Link Down Time', 'seconds', family,
+                        'redis_sentinel.master_replicas_link_down_time', 'line'],
+            'lines': [
+                ['master_{0}_replicas_link_down_time'.format(master_id), 'down time', 'absolute', 1, 1000],
+            ]
+        },
+    }
+    return order, charts
+
+
+def to_dict(pairs):
+    """
+    SENTINEL MASTERS/SLAVES describe every instance as a flat list of field names and values
+    """
+    return dict(zip(pairs[::2], pairs[1::2]))
+
+
+class Service(SocketService):
+    def __init__(self, configuration=None, name=None):
+        SocketService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.host = 'localhost'
+        self.port = 26379
+        self.request = 'PING\r\n'
+        self.password = self.configuration.get('pass')
+        self.masters = self.configuration.get('masters') or list()
+        self.collected_masters = set()
+        self.expected_replies = 0
+
+    def execute(self, *commands):
+        """
+        Send commands in a single request, authenticating first if a password is set
+        :param commands: <tuple> of command arguments
+        :return: <list> of replies, one per command
+        """
+        if self.password:
+            commands = (('AUTH', self.password),) + commands
+        self.expected_replies = len(commands)
+
+        raw = self._get_raw_data(raw=True, request=b''.join(encode_command(*c) for c in commands))
+        if not raw:
+            return None
+        try:
+            replies, _ = parse_replies(raw)
+        except ValueError as error:
+            self.error('invalid response: {0}'.format(error))
+            return None
+        if len(replies) < self.expected_replies:
+            self.error('incomplete response')
+            return None
+
+        if self.password:
+            auth, replies = replies[0], replies[1:]
+            if isinstance(auth, ReplyError):
+                self.error('AUTH failed: {0}'.format(auth))
+                return None
+        return replies
+
+    def _check_raw_data(self, data):
+        try:
+            return len(parse_replies(data)[0]) >= self.expected_replies
+        except ValueError:
+            return True
+
+    def _get_data(self):
+        replies = self.execute(('INFO', 'sentinel'), ('SENTINEL', 'MASTERS'))
+        if not replies:
+            return None
+
+        info, masters = replies
+        if isinstance(info, ReplyError) or isinstance(masters, ReplyError):
+            self.error('not a sentinel: {0}'.format(info if isinstance(info, ReplyError) else masters))
+            return None
+
+        data = {
+            'sentinel_masters': len(masters),
+            'sentinel_tilt': 0,
+        }
+        for line in info.splitlines():
+            key, _, value = line.partition(':')
+            if key == 'sentinel_tilt':
+                data['sentinel_tilt'] = int(value)
+
+        for master in masters:
+            master = to_dict(master)
+            name = master.get('name')
+            if not name or (self.masters and name not in self.masters):
+                continue
+            data.update(self.get_master_data(name, master))
+
+        return data
+
+    def get_master_data(self, name, master):
+        master_id = clean_name(name)
+        if master_id not in self.collected_masters:
+            self.collected_masters.add(master_id)
+            self.add_master_charts(master_id, name)
+
+        prefix = 'master_{0}_'.format(master_id)
+        flags = master.get('flags', '').split(',')
+        data = {
+            prefix + 'ok': int('s_down' not in flags and 'o_down' not in flags),
+            prefix + 's_down': int('s_down' in flags),
+            prefix + 'o_down': int('o_down' in flags),
+            prefix + 'failover_in_progress': int('failover_in_progress' in flags),
+            prefix + 'num_slaves': int(master.get('num-slaves', 0)),
+            # the sentinel reports the others, count itself too
+            prefix + 'num_sentinels': int(master.get('num-other-sentinels', 0)) + 1,
+            prefix + 'quorum': int(master.get('quorum', 0)),
+            # the configuration epoch is incremented by every failover
+            prefix + 'config_epoch': int(master.get('config-epoch', 0)),
+        }
+
+        replies = self.execute(('SENTINEL', 'CKQUORUM', name), ('SENTINEL', 'SLAVES', name))
+        if not replies:
+            return data
+        ckquorum, replicas = replies
+
+        # '+OK 3 usable Sentinels. ...' or '-NOQUORUM 1 usable Sentinels. ...'
+        words = str(ckquorum).split()
+        if len(words) > 1 and words[1].isdigit():
+            data[prefix + 'usable_sentinels'] = int(words[1])
+        data[prefix + 'quorum_unreachable'] = int(isinstance(ckquorum, ReplyError))
+
+        if isinstance(replicas, ReplyError):
+            return data
+        data[prefix + 'replicas_link_ok'] = 0
+        data[prefix + 'replicas_link_err'] = 0
+        data[prefix + 'replicas_link_down_time'] = 0
+        for replica in replicas:
+            replica = to_dict(replica)
+            if replica.get('master-link-status') == 'ok':
+                data[prefix + 'replicas_link_ok'] += 1
+            else:
+                data[prefix + 'replicas_link_err'] += 1
+            data[prefix + 'replicas_link_down_time'] = max(data[prefix + 'replicas_link_down_time'],
+                                                           int(replica.get('master-link-down-time', 0)))
+
+        return data
+
+    def add_master_charts(self, master_id, master_name):
+        order, charts = master_charts(master_id, master_name)
+        self.add_charts(order, charts)
+
+    def check(self):
+        self._parse_config()
+        return bool(self._get_data())
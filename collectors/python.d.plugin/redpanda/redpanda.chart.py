@@ -0,0 +1,277 @@
+# -*- coding: utf-8 -*-
+# Description: redpanda netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+from json import loads
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse, sum_by
+
+update_every = 5
+
+API_METRICS = 'public_metrics'
+API_NODE_CONFIG = 'v1/node_config'
+API_PARTITIONS = 'v1/partitions'
+API_HEALTH = 'v1/cluster/health_overview'
+
+METRIC_LATENCY_SUM = 'redpanda_kafka_request_latency_seconds_sum'
+METRIC_LATENCY_COUNT = 'redpanda_kafka_request_latency_seconds_count'
+METRIC_RECOVERY_ACTIVE = 'redpanda_raft_recovery_partitions_active'
+METRIC_RECOVERY_PENDING = 'redpanda_raft_recovery_partitions_to_recover'
+METRIC_RECOVERY_OFFSETS = 'redpanda_raft_recovery_offsets_pending'
+METRIC_DISK_ALERT = 'redpanda_storage_disk_free_space_alert'
+METRIC_DISK_FREE = 'redpanda_storage_disk_free_bytes'
+METRIC_DISK_TOTAL = 'redpanda_storage_disk_total_bytes'
+
+METRICS = (
+    METRIC_LATENCY_SUM,
+    METRIC_LATENCY_COUNT,
+    METRIC_RECOVERY_ACTIVE,
+    METRIC_RECOVERY_PENDING,
+    METRIC_RECOVERY_OFFSETS,
+    METRIC_DISK_ALERT,
+    METRIC_DISK_FREE,
+    METRIC_DISK_TOTAL,
+)
+
+REQUESTS = (
+    'produce',
+    'consume',
+)
+
+# values of redpanda_storage_disk_free_space_alert
+DISK_ALERTS = (
+    'ok',
+    'low_space',
+    'degraded',
+)
+
+# only user topics, internal namespaces are 'redpanda' and 'kafka_internal'
+NAMESPACE_KAFKA = 'kafka'
+
+ORDER = [
+    'cluster_health',
+    'cluster_partitions',
+    'partition_leadership',
+    'kafka_requests',
+    'kafka_latency',
+    'raft_recovery_partitions',
+    'raft_recovery_offsets',
+    'disk_alert',
+    'disk_space',
+]
+
+CHARTS = {
+    'cluster_health': {
+        'options': [None, 'Cluster Health', 'status', 'cluster', 'redpanda.cluster_health', 'line'],
+        'lines': [
+            ['cluster_healthy', 'healthy'],
+            ['cluster_nodes_down', 'nodes down'],
+        ]
+    },
+    'cluster_partitions': {
+        'options': [None, 'Unhealthy Partitions', 'partitions', 'cluster', 'redpanda.cluster_partitions', 'line'],
+        'lines': [
+            ['cluster_leaderless_partitions', 'leaderless'],
+            ['cluster_under_replicated_partitions', 'under replicated'],
+        ]
+    },
+    'partition_leadership': {
+        'options': [None, 'Local Partitions By Leadership', 'partitions', 'partitions',
+                    'redpanda.partition_leadership', 'stacked'],
+        'lines': [
+            ['partitions_leader', 'leader'],
+            ['partitions_follower', 'follower'],
+            ['partitions_leaderless', 'leaderless'],
+        ]
+    },
+    'kafka_requests': {
+        'options': [None, 'Kafka Requests', 'requests/s', 'kafka api', 'redpanda.kafka_requests', 'line'],
+        'lines': [['kafka_{0}_requests'.format(r), r, 'incremental'] for r in REQUESTS]
+    },
+    'kafka_latency': {
+        'options': [None, 'Kafka Requests Average Latency', 'milliseconds', 'kafka api', 'redpanda.kafka_latency',
+                    'line'],
+        'lines': [['kafka_{0}_latency'.format(r), r, 'absolute', 1, 1000] for r in REQUESTS]
+    },
+    'raft_recovery_partitions': {
+        'options': [None, 'Raft Recovery', 'partitions', 'raft', 'redpanda.raft_recovery_partitions', 'line'],
+        'lines': [
+            ['raft_recovery_active', 'recovering'],
+            ['raft_recovery_pending', 'pending'],
+        ]
+    },
+    'raft_recovery_offsets': {
+        'options': [None, 'Raft Recovery Offsets Pending', 'offsets', 'raft', 'redpanda.raft_recovery_offsets',
+                    'line'],
+        'lines': [
+            ['raft_recovery_offsets', 'pending'],
+        ]
+    },
+    'disk_alert': {
+        'options': [None, 'Disk Free Space Alert', 'status', 'disk', 'redpanda.disk_alert', 'line'],
+        'lines': [['disk_alert_' + a, a] for a in DISK_ALERTS]
+    },
+    'disk_space': {
+        'options': [None, 'Data Disk Space', 'GiB', 'disk', 'redpanda.disk_space', 'stacked'],
+        'lines': [
+            ['disk_free', 'free', 'absolute', 1, 1 << 30],
+            ['disk_used', 'used', 'absolute', 1, 1 << 30],
+        ]
+    },
+}
+
+
+def topic_chart(topic_id, topic):
+    return {
+        'options': [None, 'Partitions By Leadership', 'partitions', 'topic ' + topic, 'redpanda.topic_leadership',
+                    'stacked'],
+        'lines': [
+            ['topic_{0}_leader'.format(topic_id), 'leader'],
+            ['topic_{0}_follower'.format(topic_id), 'follower'],
+            ['topic_{0}_leaderless'.format(topic_id), 'leaderless'],
+        ]
+    }
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:9644').rstrip('/')
+        self.topic_charts = self.configuration.get('topic_charts', True)
+        self.node_id = None
+        self.collected_topics = set()
+        self.latency = dict()
+
+    def get_json(self, path):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, path))
+        if not raw:
+            return None
+        try:
+            return loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(path, error))
+            return None
+
+    def _get_data(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_METRICS))
+        if not raw:
+            return None
+
+        samples = parse(raw, names=METRICS)
+        if not samples:
+            self.error('no redpanda metrics found at {0}/{1}'.format(self.url, API_METRICS))
+            return None
+
+        data = dict()
+        data.update(self.get_metrics_data(samples))
+        data.update(self.get_health_data())
+        data.update(self.get_partitions_data())
+
+        return data
+
+    def get_metrics_data(self, samples):
+        data = dict()
+
+        sums = sum_by(samples, METRIC_LATENCY_SUM, 'redpanda_request')
+        counts = sum_by(samples, METRIC_LATENCY_COUNT, 'redpanda_request')
+        for request in REQUESTS:
+            if request not in counts:
+                continue
+            total, count = sums.get(request, 0), counts[request]
+            data['kafka_{0}_requests'.format(request)] = int(count)
+
+            # average of the requests completed since the last run, in microseconds
+            prev_total, prev_count = self.latency.get(request, (total, count))
+            self.latency[request] = (total, count)
+            if count > prev_count:
+                data['kafka_{0}_latency'.format(request)] = int((total - prev_total) / (count - prev_count) * 1e6)
+            else:
+                data['kafka_{0}_latency'.format(request)] = 0
+
+        for key, metric in (('raft_recovery_active', METRIC_RECOVERY_ACTIVE),
+                            ('raft_recovery_pending', METRIC_RECOVERY_PENDING),
+                            ('raft_recovery_offsets', METRIC_RECOVERY_OFFSETS)):
+            values = sum_by(samples, metric)
+            if values:
+                data[key] = int(values[None])
+
+        alert = sum_by(samples, METRIC_DISK_ALERT)
+        if alert:
+            for value, name in enumerate(DISK_ALERTS):
+                data['disk_alert_' + name] = int(alert[None] == value)
+
+        free, total = sum_by(samples, METRIC_DISK_FREE), sum_by(samples, METRIC_DISK_TOTAL)
+        if free and total:
+            data['disk_free'] = int(free[None])
+            data['disk_used'] = int(total[None] - free[None])
+
+        return data
+
+    def get_health_data(self):
+        health = self.get_json(API_HEALTH)
+        if not health:
+            return dict()
+
+        return {
+            'cluster_healthy': int(bool(health.get('is_healthy'))),
+            'cluster_nodes_down': len(health.get('nodes_down') or list()),
+            'cluster_leaderless_partitions': health.get('leaderless_count',
+                                                        len(health.get('leaderless_partitions') or list())),
+            'cluster_under_replicated_partitions': health.get('under_replicated_count',
+                                                              len(health.get('under_replicated_partitions') or list())),
+        }
+
+    def get_partitions_data(self):
+        if self.node_id is None:
+            node_config = self.get_json(API_NODE_CONFIG)
+            if not node_config or 'node_id' not in node_config:
+                return dict()
+            self.node_id = node_config['node_id']
+
+        partitions = self.get_json(API_PARTITIONS)
+        if partitions is None:
+            return dict()
+
+        data = {
+            'partitions_leader': 0,
+            'partitions_follower': 0,
+            'partitions_leaderless': 0,
+        }
+        for topic_id in self.collected_topics:
+            for state in ('leader', 'follower', 'leaderless'):
+                data['topic_{0}_{1}'.format(topic_id, state)] = 0
+
+        for partition in partitions:
+            if partition.get('ns') != NAMESPACE_KAFKA:
+                continue
+
+            leader = partition.get('leader', -1)
+            if leader == self.node_id:
+                state = 'leader'
+            elif leader is None or leader < 0:
+                state = 'leaderless'
+            else:
+                state = 'follower'
+            data['partitions_' + state] += 1
+
+            if not self.topic_charts:
+                continue
+            topic = partition.get('topic')
+            topic_id = clean_name(topic)
+            if topic_id not in self.collected_topics:
+                self.collected_topics.add(topic_id)
+                self.add_topic_chart(topic_id, topic)
+                for s in ('leader', 'follower', 'leaderless'):
+                    data['topic_{0}_{1}'.format(topic_id, s)] = 0
+            data['topic_{0}_{1}'.format(topic_id, state)] += 1
+
+        return data
+
+    def add_topic_chart(self, topic_id, topic):
+        chart_name = 'topic_{0}_leadership'.format(topic_id)
+        self.add_charts([chart_name], {chart_name: topic_chart(topic_id, topic)})
@@ -0,0 +1,198 @@
+# -*- coding: utf-8 -*-
+# Description: rpi netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import re
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import find_binary
+
+update_every = 5
+
+VCGENCMD = 'vcgencmd'
+
+# only boards booted with a device tree are considered single board computers
+DEVICE_TREE_MODEL = '/proc/device-tree/model'
+
+# used when vcgencmd is not available (non Raspberry Pi boards, containers without /dev/vchiq)
+SYSFS_TEMPERATURE = '/sys/class/thermal/thermal_zone0/temp'
+SYSFS_THROTTLED = '/sys/devices/platform/soc/soc:firmware/get_throttled'
+
+ORDER = [
+    'temperature',
+    'throttling',
+    'throttling_since_boot',
+    'voltage',
+    'clock',
+    'memory_split',
+]
+
+CHARTS = {
+    'temperature': {
+        'options': [None, 'SoC Temperature', 'celsius', 'temperature', 'rpi.temperature', 'line'],
+        'lines': [
+            ['temperature', 'soc', 'absolute', 1, 1000],
+        ]
+    },
+    'throttling': {
+        'options': [None, 'Throttling State', 'status', 'throttling', 'rpi.throttling', 'line'],
+        'lines': [
+            ['under_voltage', 'under voltage'],
+            ['freq_capped', 'frequency capped'],
+            ['throttled', 'throttled'],
+            ['soft_temp_limit', 'soft temperature limit'],
+        ]
+    },
+    'throttling_since_boot': {
+        'options': [None, 'Throttling Occurred Since Boot', 'status', 'throttling', 'rpi.throttling_since_boot',
+                    'line'],
+        'lines': [
+            ['under_voltage_occurred', 'under voltage'],
+            ['freq_capped_occurred', 'frequency capped'],
+            ['throttled_occurred', 'throttled'],
+            ['soft_temp_limit_occurred', 'soft temperature limit'],
+        ]
+    },
+    'voltage': {
+        'options': [None, 'Voltage', 'volts', 'power', 'rpi.voltage', 'line'],
+        'lines': [
+            ['volt_core', 'core', 'absolute', 1, 10000],
+            ['volt_sdram_c', 'sdram_c', 'absolute', 1, 10000],
+            ['volt_sdram_i', 'sdram_i', 'absolute', 1, 10000],
+            ['volt_sdram_p', 'sdram_p', 'absolute', 1, 10000],
+        ]
+    },
+    'clock': {
+        'options': [None, 'Clock Frequency', 'MHz', 'clocks', 'rpi.clock', 'line'],
+        'lines': [
+            ['clock_arm', 'arm', 'absolute', 1, 1000000],
+            ['clock_core', 'core', 'absolute', 1, 1000000],
+        ]
+    },
+    'memory_split': {
+        'options': [None, 'Memory Split', 'MiB', 'memory', 'rpi.memory_split', 'stacked'],
+        'lines': [
+            ['mem_arm', 'arm'],
+            ['mem_gpu', 'gpu'],
+        ]
+    },
+}
+
+# https://www.raspberrypi.com/documentation/computers/os.html#get_throttled
+THROTTLED_BITS = [
+    (0, 'under_voltage'),
+    (1, 'freq_capped'),
+    (2, 'throttled'),
+    (3, 'soft_temp_limit'),
+    (16, 'under_voltage_occurred'),
+    (17, 'freq_capped_occurred'),
+    (18, 'throttled_occurred'),
+    (19, 'soft_temp_limit_occurred'),
+]
+
+VOLTS = ['core', 'sdram_c', 'sdram_i', 'sdram_p']
+CLOCKS = ['arm', 'core']
+MEMORY = ['arm', 'gpu']
+
+RE_TEMP = re.compile(r"^temp=([0-9.]+)'C$")
+RE_THROTTLED = re.compile(r'^throttled=(0x[0-9a-fA-F]+)$')
+RE_VOLT = re.compile(r'^volt=([0-9.]+)V$')
+RE_CLOCK = re.compile(r'^frequency\(\d+\)=(\d+)$')
+RE_MEM = re.compile(r'^\w+=(\d+)M$')
+
+
+def parse_throttled(value):
+    value = int(value, 16)
+    return dict((name, int(bool(value & (1 << bit)))) for bit, name in THROTTLED_BITS)
+
+
+def read_file(path):
+    try:
+        with open(path) as f:
+            return f.read().strip()
+    except (OSError, IOError):
+        return None
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.vcgencmd = self.configuration.get('vcgencmd_path')
+
+    def check(self):
+        model = read_file(DEVICE_TREE_MODEL)
+        if not model:
+            self.error('"{0}" not found, not a single board computer'.format(DEVICE_TREE_MODEL))
+            return False
+        self.debug('board model: {0}'.format(model.rstrip('\x00')))
+
+        self.vcgencmd = self.vcgencmd or find_binary(VCGENCMD)
+        if not self.vcgencmd:
+            self.info('can\'t locate "{0}" binary, falling back to sysfs'.format(VCGENCMD))
+
+        data = self._get_data()
+        if not data:
+            self.error('no data collected from "{0}" nor sysfs'.format(VCGENCMD))
+            return False
+        return True
+
+    def vcgencmd_value(self, regex, *args):
+        raw = self._get_raw_data(command=[self.vcgencmd] + list(args))
+        if not raw:
+            return None
+        match = regex.match(raw[0].strip())
+        return match and match.group(1)
+
+    def _get_data(self):
+        if self.vcgencmd:
+            data = self.get_vcgencmd_data()
+            if data:
+                return data
+        return self.get_sysfs_data()
+
+    def get_vcgencmd_data(self):
+        data = dict()
+
+        value = self.vcgencmd_value(RE_TEMP, 'measure_temp')
+        if value:
+            data['temperature'] = int(float(value) * 1000)
+
+        value = self.vcgencmd_value(RE_THROTTLED, 'get_throttled')
+        if value:
+            data.update(parse_throttled(value))
+
+        for name in VOLTS:
+            value = self.vcgencmd_value(RE_VOLT, 'measure_volts', name)
+            if value:
+                data['volt_' + name] = int(float(value) * 10000)
+
+        for name in CLOCKS:
+            value = self.vcgencmd_value(RE_CLOCK, 'measure_clock', name)
+            if value:
+                data['clock_' + name] = value
+
+        for name in MEMORY:
+            value = self.vcgencmd_value(RE_MEM, 'get_mem', name)
+            if value:
+                data['mem_' + name] = value
+
+        return data or None
+
+    @staticmethod
+    def get_sysfs_data():
+        data = dict()
+
+        value = read_file(SYSFS_TEMPERATURE)
+        if value and value.isdigit():
+            data['temperature'] = value
+
+        value = read_file(SYSFS_THROTTLED)
+        if value:
+            try:
+                data.update(parse_throttled('0x' + value))
+            except ValueError:
+                pass
+
+        return data or None
@@ -3,6 +3,8 @@
 # Author: Pawel Krupa (paulfantom)
 # SPDX-License-Identifier: GPL-3.0-or-later
 
+from fnmatch import fnmatch
+
 from bases.FrameworkServices.SimpleService import SimpleService
 from third_party import lm_sensors as sensors
 
@@ -84,6 +86,109 @@ TYPE_MAP = {
     24: 'beep_enable'
 }
 
+# libsensors subfeature types (sensors_subfeature_type), ((feature type << 8) | index)
+# thresholds are exposed as chart variables, in the chart units
+THRESHOLDS = {
+    0x001: 'min',
+    0x002: 'max',
+    0x003: 'lcrit',
+    0x004: 'crit',
+    0x101: 'min',
+    0x102: 'max',
+    0x201: 'max',
+    0x203: 'min',
+    0x204: 'crit',
+    0x206: 'lcrit',
+    0x207: 'emergency',
+    0x306: 'cap',
+    0x308: 'max',
+    0x309: 'crit',
+    0x30a: 'min',
+    0x30b: 'lcrit',
+    0x501: 'min',
+    0x502: 'max',
+    0x503: 'lcrit',
+    0x504: 'crit',
+}
+
+# additional dimensions, added next to the sensor input when the driver provides them
+EXTRA_DIMENSIONS = {
+    0x005: 'average',
+    0x006: 'lowest',
+    0x007: 'highest',
+    0x209: 'lowest',
+    0x20a: 'highest',
+    0x304: 'highest',
+    0x305: 'lowest',
+    0x505: 'average',
+    0x506: 'lowest',
+    0x507: 'highest',
+}
+
+
+class Feature:
+    def __init__(self, chip, feature):
+        self.chip = chip
+        self.chip_name = sensors.chip_snprintf_name(chip)
+        self.name = str(feature.name.decode())
+        self.type = TYPE_MAP.get(feature.type)
+        self.feature = feature
+        self.label = sensors.get_label(chip, feature)
+
+    def id(self):
+        return self.chip_name + '_' + self.name
+
+    def read(self, error=None):
+        """
+        Reads all subfeatures of the feature.
+        :return: (input value, {subfeature type: value})
+        """
+        value, values = None, dict()
+        for sf in sensors.SubFeatureIterator(self.chip, self.feature):
+            try:
+                v = sensors.get_value(self.chip, sf.number)
+            except sensors.SensorsError as err:
+                if error:
+                    error('{0}: {1}'.format(sf.name, err))
+                continue
+            # the first readable subfeature is the sensor input
+            if value is None:
+                value = v
+            else:
+                values[sf.type] = v
+        return value, values
+
+
+def iter_features():
+    for chip in sensors.ChipIterator():
+        for feature in sensors.FeatureIterator(chip):
+            yield Feature(chip, feature)
+
+
+class FeatureSelector:
+    """
+    'chip:feature' patterns, shell wildcards are supported in both parts.
+    The feature part is matched against the feature name (temp1) and its label (Core 0).
+    Patterns starting with '!' are negative, the first matching pattern wins.
+    Features not matching any pattern are selected only if all the patterns are negative.
+    """
+
+    def __init__(self, patterns):
+        self.patterns = list()
+        for pattern in patterns or list():
+            pattern = str(pattern)
+            positive = not pattern.startswith('!')
+            pattern = pattern.lstrip('!')
+            chip, _, feature = pattern.partition(':')
+            self.patterns.append((positive, chip or '*', feature or '*'))
+        self.default = not any(p[0] for p in self.patterns)
+
+    def selected(self, f):
+        for positive, chip, feature in self.patterns:
+            if fnmatch(f.chip_name, chip) and (fnmatch(f.name, feature) or fnmatch(f.label, feature)):
+                return positive
+        return self.default
+
 
 class Service(SimpleService):
     def __init__(self, configuration=None, name=None):
@@ -91,64 +196,82 @@ class Service(SimpleService):
         self.order = list()
         self.definitions = dict()
         self.chips = configuration.get('chips')
+        self.types = configuration.get('types') or ORDER
+        self.selector = FeatureSelector(configuration.get('features'))
+        self.collected = set()
 
     def get_data(self):
         data = dict()
         try:
-            for chip in sensors.ChipIterator():
-                prefix = sensors.chip_snprintf_name(chip)
-                for feature in sensors.FeatureIterator(chip):
-                    sfi = sensors.SubFeatureIterator(chip, feature)
-                    val = None
-                    for sf in sfi:
-                        try:
-                            val = sensors.get_value(chip, sf.number)
-                            break
-                        except sensors.SensorsError:
-                            continue
-                    if val is None:
+            for f in iter_features():
+                if f.id() not in self.collected:
+                    continue
+                val, values = f.read()
+                if val is None:
+                    continue
+                if f.type in LIMITS:
+                    limit = LIMITS[f.type]
+                    if val < limit[0] or val > limit[1]:
                         continue
-                    type_name = TYPE_MAP[feature.type]
-                    if type_name in LIMITS:
-                        limit = LIMITS[type_name]
-                        if val < limit[0] or val > limit[1]:
-                            continue
-                    data[prefix + '_' + str(feature.name.decode())] = int(val * 1000)
+                data[f.id()] = int(val * 1000)
+                for sf_type, v in values.items():
+                    if sf_type in EXTRA_DIMENSIONS:
+                        data['{0}_{1}'.format(f.id(), EXTRA_DIMENSIONS[sf_type])] = int(v * 1000)
+                    elif sf_type in THRESHOLDS:
+                        data['{0}_{1}'.format(f.id(), THRESHOLDS[sf_type])] = v
         except sensors.SensorsError as error:
             self.error(error)
             return None
 
         return data or None
 
+    def selected(self, f):
+        if f.type not in self.types:
+            return False
+        if self.chips and not any([f.chip_name.startswith(ex) for ex in self.chips]):
+            return False
+        return self.selector.selected(f)
+
     def create_definitions(self):
-        for sensor in ORDER:
-            for chip in sensors.ChipIterator():
-                chip_name = sensors.chip_snprintf_name(chip)
-                if self.chips and not any([chip_name.startswith(ex) for ex in self.chips]):
+        features = [f for f in iter_features() if self.selected(f)]
+
+        for sensor in self.types:
+            if sensor not in CHARTS:
+                continue
+            for f in features:
+                if f.type != sensor:
                     continue
-                for feature in sensors.FeatureIterator(chip):
-                    sfi = sensors.SubFeatureIterator(chip, feature)
-                    vals = list()
-                    for sf in sfi:
-                        try:
-                            vals.append(sensors.get_value(chip, sf.number))
-                        except sensors.SensorsError as error:
-                            self.error('{0}: {1}'.format(sf.name, error))
-                            continue
-                    if not vals or (vals[0] == 0 and feature.type != 1):
-                        continue
-                    if TYPE_MAP[feature.type] == sensor:
-                        # create chart
-                        name = chip_name + '_' + TYPE_MAP[feature.type]
-                        if name not in self.order:
-                            self.order.append(name)
-                            chart_def = list(CHARTS[sensor]['options'])
-                            self.definitions[name] = {'options': chart_def}
-                            self.definitions[name]['lines'] = []
+                val, values = f.read(self.error)
+                if val is None or (val == 0 and f.type != 'fan'):
+                    continue
+
+                # create chart
+                name = f.chip_name + '_' + f.type
+                if name not in self.order:
+                    self.order.append(name)
+                    chart_def = list(CHARTS[sensor]['options'])
+                    self.definitions[name] = {'options': chart_def}
+                    self.definitions[name]['lines'] = []
+                    self.definitions[name]['variables'] = []
+
+                line = list(CHARTS[sensor]['lines'][0])
+                line[0] = f.id()
+                line[1] = f.label
+                self.definitions[name]['lines'].append(line)
+                self.collected.add(f.id())
+
+                for sf_type in sorted(values):
+                    if sf_type in EXTRA_DIMENSIONS:
+                        kind = EXTRA_DIMENSIONS[sf_type]
                         line = list(CHARTS[sensor]['lines'][0])
-                        line[0] = chip_name + '_' + str(feature.name.decode())
-                        line[1] = sensors.get_label(chip, feature)
+                        line[0] = '{0}_{1}'.format(f.id(), kind)
+                        line[1] = '{0} {1}'.format(f.label, kind)
                         self.definitions[name]['lines'].append(line)
+                    elif sf_type in THRESHOLDS:
+                        kind = THRESHOLDS[sf_type]
+                        # e.g. 'temp1_crit' on the 'coretemp-isa-0000_temperature' chart
+                        variable = ['{0}_{1}'.format(f.name, kind), None, '{0}_{1}'.format(f.id(), kind)]
+                        self.definitions[name]['variables'].append(variable)
 
     def check(self):
         try:
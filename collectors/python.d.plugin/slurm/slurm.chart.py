@@ -0,0 +1,259 @@
+# -*- coding: utf-8 -*-
+# Description: slurm netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import time
+from copy import deepcopy
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import clean_name, find_binary
+
+update_every = 30
+
+SINFO = 'sinfo'
+SQUEUE = 'squeue'
+
+# one line per node and partition
+SINFO_ARGS = ['-h', '-N', '-o', '%N|%R|%T|%C']
+# one line per job, and per task of the pending job arrays
+SQUEUE_ARGS = ['-h', '-a', '-r', '-o', '%P|%T|%r|%V']
+
+NODE_STATES = (
+    'allocated',
+    'mixed',
+    'idle',
+    'completing',
+    'draining',
+    'drained',
+    'down',
+    'other',
+)
+
+# the states not in the list are charted as 'other', 'fail' and 'failing' are down
+NODE_STATES_MAP = {
+    'allocated': 'allocated',
+    'mixed': 'mixed',
+    'idle': 'idle',
+    'completing': 'completing',
+    'draining': 'draining',
+    'drained': 'drained',
+    'down': 'down',
+    'fail': 'down',
+    'failing': 'draining',
+}
+
+# suffixes of the node state: not responding, powered down, powering up etc.
+NODE_STATE_FLAGS = '*~#!%$@^-'
+
+JOB_STATES = (
+    'pending',
+    'running',
+    'suspended',
+    'completing',
+    'configuring',
+    'other',
+)
+
+PENDING_REASONS = (
+    'resources',
+    'priority',
+    'dependency',
+    'held',
+    'limits',
+    'other',
+)
+
+WAIT_PERCENTILES = (
+    50,
+    90,
+    99,
+)
+
+ORDER = [
+    'nodes',
+    'cpus',
+    'jobs',
+    'pending_reasons',
+    'pending_wait',
+]
+
+CHARTS = {
+    'nodes': {
+        'options': [None, 'Nodes', 'nodes', 'nodes', 'slurm.nodes', 'stacked'],
+        'lines': [['nodes_' + s, s] for s in NODE_STATES]
+    },
+    'cpus': {
+        'options': [None, 'CPUs', 'cpus', 'nodes', 'slurm.cpus', 'stacked'],
+        'lines': [
+            ['cpus_allocated', 'allocated'],
+            ['cpus_idle', 'idle'],
+            ['cpus_other', 'other'],
+        ]
+    },
+    'jobs': {
+        'options': [None, 'Jobs', 'jobs', 'jobs', 'slurm.jobs', 'stacked'],
+        'lines': [['jobs_' + s, s] for s in JOB_STATES]
+    },
+    'pending_reasons': {
+        'options': [None, 'Pending Jobs By Reason', 'jobs', 'jobs', 'slurm.pending_reasons', 'stacked'],
+        'lines': [['pending_' + r, r] for r in PENDING_REASONS]
+    },
+    'pending_wait': {
+        'options': [None, 'Pending Jobs Wait Time', 'seconds', 'jobs', 'slurm.pending_wait', 'line'],
+        'lines': [['wait_p{0}'.format(p), 'p{0}'.format(p)] for p in WAIT_PERCENTILES] + [
+            ['wait_max', 'max'],
+        ]
+    },
+}
+
+
+def partition_charts(partition_id, partition):
+    order = [
+        'partition_{0}_jobs'.format(partition_id),
+    ]
+    charts = {
+        order[0]: {
+            'options': [None, 'Jobs', 'jobs', 'partition ' + partition, 'slurm.partition_jobs', 'stacked'],
+            'lines': [['partition_{0}_{1}'.format(partition_id, s), s] for s in JOB_STATES]
+        },
+    }
+    return order, charts
+
+
+def node_state(state):
+    # 'idle~', 'drained*', 'mixed+drain' (extended states)
+    state = state.lower().split('+')[0].rstrip(NODE_STATE_FLAGS)
+    return NODE_STATES_MAP.get(state, 'other')
+
+
+def pending_reason(reason):
+    if reason in ('Resources', 'Priority', 'Dependency'):
+        return reason.lower()
+    if reason.startswith('JobHeld'):
+        return 'held'
+    if 'Limit' in reason:
+        # QOSMaxJobsPerUserLimit, AssocGrpCpuLimit, PartitionTimeLimit...
+        return 'limits'
+    return 'other'
+
+
+def percentile(values, p):
+    """
+    Nearest rank percentile of sorted values.
+    """
+    return values[max(int(len(values) * p / 100.0 + 0.5), 1) - 1]
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.sinfo = self.configuration.get('sinfo_path')
+        self.squeue = self.configuration.get('squeue_path')
+        self.do_partitions = self.configuration.get('partition_charts', True)
+        self.collected_partitions = set()
+
+    def check(self):
+        self.sinfo = self.sinfo or find_binary(SINFO)
+        self.squeue = self.squeue or find_binary(SQUEUE)
+        for name, binary in ((SINFO, self.sinfo), (SQUEUE, self.squeue)):
+            if not binary:
+                self.error('can\'t locate "{0}" binary'.format(name))
+                return False
+
+        data = self._get_data()
+        if not data:
+            self.error('no data from {0} and {1}, is slurmctld reachable?'.format(self.sinfo, self.squeue))
+            return False
+        return True
+
+    def _get_data(self):
+        nodes = self._get_raw_data(command=[self.sinfo] + SINFO_ARGS)
+        jobs = self._get_raw_data(command=[self.squeue] + SQUEUE_ARGS)
+        if not nodes or jobs is None:
+            return None
+
+        data = dict()
+        data.update(self.nodes_data(nodes))
+        data.update(self.jobs_data(jobs))
+        return data
+
+    def nodes_data(self, raw):
+        data = dict(('nodes_' + s, 0) for s in NODE_STATES)
+        data.update(cpus_allocated=0, cpus_idle=0, cpus_other=0)
+
+        seen = set()
+        for line in raw:
+            parts = line.strip().split('|')
+            if len(parts) != 4:
+                continue
+            node, partition, state, cpus = parts
+
+            if self.do_partitions:
+                self.add_partition(partition)
+
+            # the nodes of several partitions are listed once per partition
+            if node in seen:
+                continue
+            seen.add(node)
+
+            data['nodes_' + node_state(state)] += 1
+            # allocated/idle/other/total
+            cpus = cpus.split('/')
+            if len(cpus) == 4 and all(c.isdigit() for c in cpus):
+                data['cpus_allocated'] += int(cpus[0])
+                data['cpus_idle'] += int(cpus[1])
+                data['cpus_other'] += int(cpus[2])
+
+        return data
+
+    def jobs_data(self, raw):
+        data = dict(('jobs_' + s, 0) for s in JOB_STATES)
+        data.update(('pending_' + r, 0) for r in PENDING_REASONS)
+        for partition in self.collected_partitions:
+            data.update(('partition_{0}_{1}'.format(partition, s), 0) for s in JOB_STATES)
+
+        now = time.time()
+        waits = list()
+        for line in raw:
+            parts = line.strip().split('|')
+            if len(parts) != 4:
+                continue
+            partitions, state, reason, submitted = parts
+
+            state = state.lower()
+            if state not in JOB_STATES:
+                state = 'other'
+            data['jobs_' + state] += 1
+
+            # pending jobs can be submitted to several partitions, 'debug,batch'
+            for partition in partitions.split(','):
+                key = 'partition_{0}_{1}'.format(clean_name(partition), state)
+                if key in data:
+                    data[key] += 1
+
+            if state != 'pending':
+                continue
+
+            data['pending_' + pending_reason(reason)] += 1
+            try:
+                waits.append(max(now - time.mktime(time.strptime(submitted, '%Y-%m-%dT%H:%M:%S')), 0))
+            except ValueError:
+                continue
+
+        waits.sort()
+        for p in WAIT_PERCENTILES:
+            data['wait_p{0}'.format(p)] = int(percentile(waits, p)) if waits else 0
+        data['wait_max'] = int(waits[-1]) if waits else 0
+
+        return data
+
+    def add_partition(self, partition):
+        partition_id = clean_name(partition)
+        if partition_id in self.collected_partitions:
+            return
+        self.collected_partitions.add(partition_id)
+
+        order, charts = partition_charts(partition_id, partition)
+        self.add_charts(order, charts)
@@ -0,0 +1,318 @@
+# -*- coding: utf-8 -*-
+# Description: smartctl netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+import os
+import time
+from copy import deepcopy
+from fnmatch import fnmatch
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import clean_name, find_binary
+
+update_every = 30
+
+SMARTCTL = 'smartctl'
+SUDO = 'sudo'
+
+DEFAULT_SCAN_EVERY = 600
+
+# -n standby: do not spin up sleeping disks, smartctl exits with 2 without reading them
+DEVICE_ARGS = ['--json', '--info', '--health', '--attributes', '-n', 'standby']
+
+# the raw values of these ATA attributes are counts
+ATA_ATTRIBUTES = (
+    (5, 'reallocated_sectors'),
+    (197, 'pending_sectors'),
+    (198, 'offline_uncorrectable'),
+    (187, 'reported_uncorrectable'),
+    (199, 'udma_crc_errors'),
+)
+
+NVME_LOG = (
+    ('critical_warning', 'critical_warning'),
+    ('available_spare', 'available_spare'),
+    ('percentage_used', 'percentage_used'),
+    ('media_errors', 'media_errors'),
+    ('num_err_log_entries', 'error_log_entries'),
+    ('unsafe_shutdowns', 'unsafe_shutdowns'),
+)
+
+ORDER = [
+    'devices',
+]
+
+CHARTS = {
+    'devices': {
+        'options': [None, 'Devices By Health Status', 'devices', 'overview', 'smartctl.devices', 'stacked'],
+        'lines': [
+            ['devices_passed', 'passed'],
+            ['devices_failed', 'failed'],
+            ['devices_standby', 'standby'],
+        ]
+    },
+}
+
+
+def device_charts(device_id, device, protocol, attributes):
+    order = [
+        'device_{0}_health'.format(device_id),
+        'device_{0}_temperature'.format(device_id),
+        'device_{0}_power_on_time'.format(device_id),
+    ]
+    family = device
+    charts = {
+        order[0]: {
+            'options': [None, 'Health Status', 'status', family, 'smartctl.device_health', 'line'],
+            'lines': [
+                ['device_{0}_passed'.format(device_id), 'passed'],
+                ['device_{0}_failed'.format(device_id), 'failed'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Temperature', 'celsius', family, 'smartctl.device_temperature', 'line'],
+            'lines': [
+                ['device_{0}_temperature'.format(device_id), 'temperature'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Power On Time', 'hours', family, 'smartctl.device_power_on_time', 'line'],
+            'lines': [
+                ['device_{0}_power_on_hours'.format(device_id), 'power_on'],
+            ]
+        },
+    }
+
+    if protocol == 'ATA':
+        order.extend([
+            'device_{0}_sectors'.format(device_id),
+            'device_{0}_errors'.format(device_id),
+        ])
+        charts[order[3]] = {
+            'options': [None, 'Bad Sectors', 'sectors', family, 'smartctl.device_sectors', 'line'],
+            'lines': [
+                ['device_{0}_reallocated_sectors'.format(device_id), 'reallocated'],
+                ['device_{0}_pending_sectors'.format(device_id), 'pending'],
+                ['device_{0}_offline_uncorrectable'.format(device_id), 'offline_uncorrectable'],
+            ]
+        }
+        charts[order[4]] = {
+            'options': [None, 'Errors', 'errors', family, 'smartctl.device_errors', 'line'],
+            'lines': [
+                ['device_{0}_reported_uncorrectable'.format(device_id), 'reported_uncorrectable'],
+                ['device_{0}_udma_crc_errors'.format(device_id), 'udma_crc'],
+            ]
+        }
+        if attributes:
+            order.append('device_{0}_attributes'.format(device_id))
+            charts[order[5]] = {
+                'options': [None, 'SMART Attributes Normalized Values', 'value', family,
+                            'smartctl.device_attributes', 'line'],
+                'lines': [['device_{0}_attribute_{1}'.format(device_id, a), a] for a in attributes]
+            }
+    elif protocol == 'NVMe':
+        order.extend([
+            'device_{0}_wear'.format(device_id),
+            'device_{0}_errors'.format(device_id),
+            'device_{0}_critical_warning'.format(device_id),
+        ])
+        charts[order[3]] = {
+            'options': [None, 'Wear', 'percentage', family, 'smartctl.device_wear', 'line'],
+            'lines': [
+                ['device_{0}_percentage_used'.format(device_id), 'used'],
+                ['device_{0}_available_spare'.format(device_id), 'available_spare'],
+            ]
+        }
+        charts[order[4]] = {
+            'options': [None, 'Errors', 'errors', family, 'smartctl.device_errors', 'line'],
+            'lines': [
+                ['device_{0}_media_errors'.format(device_id), 'media'],
+                ['device_{0}_error_log_entries'.format(device_id), 'error_log_entries'],
+                ['device_{0}_unsafe_shutdowns'.format(device_id), 'unsafe_shutdowns'],
+            ]
+        }
+        charts[order[5]] = {
+            'options': [None, 'Critical Warning', 'status', family, 'smartctl.device_critical_warning', 'line'],
+            'lines': [
+                ['device_{0}_critical_warning'.format(device_id), 'critical_warning'],
+            ]
+        }
+
+    return order, charts
+
+
+def device_name(name, dev_type):
+    """
+    :return: (<str> device, <str> device id)
+    """
+    # the disks behind a raid controller share the name of the controller, e.g. '/dev/bus/0 -d megaraid,1'
+    device = name if not dev_type or ',' not in dev_type else '{0} {1}'.format(name, dev_type)
+    return device, clean_name(device.replace('/dev/', '', 1))
+
+
+def is_standby(result):
+    # 'Device is in STANDBY mode, exit(2)'
+    messages = (result.get('smartctl') or dict()).get('messages') or list()
+    return any('STANDBY' in m.get('string', '') for m in messages)
+
+
+class DeviceSelector:
+    """
+    Shell wildcard patterns, patterns starting with '!' are negative, the first matching pattern wins.
+    Devices not matching any pattern are selected only if all the patterns are negative.
+    """
+
+    def __init__(self, patterns):
+        self.patterns = list()
+        for pattern in patterns or list():
+            pattern = str(pattern)
+            self.patterns.append((not pattern.startswith('!'), pattern.lstrip('!')))
+        self.default = not any(p[0] for p in self.patterns)
+
+    def selected(self, device):
+        for positive, pattern in self.patterns:
+            if fnmatch(device, pattern):
+                return positive
+        return self.default
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.smartctl = self.configuration.get('smartctl_path')
+        self.use_sudo = self.configuration.get('use_sudo', True)
+        self.scan_every = self.configuration.get('scan_every', DEFAULT_SCAN_EVERY)
+        self.selector = DeviceSelector(self.configuration.get('devices'))
+        self.cmd = list()
+        # (name, type) of the scanned devices
+        self.devices = list()
+        self.last_scan = 0
+        # device id -> <list> of its charts
+        self.collected_devices = dict()
+
+    def check(self):
+        self.smartctl = self.smartctl or find_binary(SMARTCTL)
+        if not self.smartctl:
+            self.error('can\'t locate "{0}" binary'.format(SMARTCTL))
+            return False
+
+        if self.use_sudo:
+            sudo = find_binary(SUDO)
+            if not sudo:
+                self.error('can\'t locate "{0}" binary'.format(SUDO))
+                return False
+
+            allowed = self._get_raw_data(command=[sudo, '-n', '-l', self.smartctl])
+            if not allowed or allowed[0].strip() != os.path.realpath(self.smartctl):
+                self.error('not allowed to run sudo for command {0}'.format(self.smartctl))
+                return False
+
+            self.cmd = [sudo, '-n']
+        self.cmd.append(self.smartctl)
+
+        if not self.scan():
+            self.error('no devices found by "{0} --scan"'.format(self.smartctl))
+            return False
+        return bool(self._get_data())
+
+    def smartctl_json(self, args):
+        raw = self._get_raw_data(command=self.cmd + args)
+        if not raw:
+            return None
+        try:
+            return json.loads(''.join(raw))
+        except ValueError as error:
+            self.error('{0}: {1}'.format(' '.join(args), error))
+            return None
+
+    def scan(self):
+        self.last_scan = time.time()
+        result = self.smartctl_json(['--scan', '--json'])
+        if result is None:
+            return False
+
+        devices = list()
+        for device in result.get('devices') or list():
+            name, dev_type = device.get('name'), device.get('type')
+            if name and self.selector.selected(name):
+                devices.append((name, dev_type))
+        self.devices = devices
+        return bool(devices)
+
+    def _get_data(self):
+        if time.time() - self.last_scan >= self.scan_every:
+            self.scan()
+            # the devices that are gone, a failed scan keeps the previous ones
+            scanned = set(device_name(name, dev_type)[1] for name, dev_type in self.devices)
+            for device_id in set(self.collected_devices) - scanned:
+                self.remove_device_charts(device_id)
+
+        data = {
+            'devices_passed': 0,
+            'devices_failed': 0,
+            'devices_standby': 0,
+        }
+
+        for name, dev_type in self.devices:
+            args = DEVICE_ARGS + ['-d', dev_type, name] if dev_type else DEVICE_ARGS + [name]
+            result = self.smartctl_json(args)
+            if result is None:
+                continue
+
+            if 'smart_status' not in result:
+                if is_standby(result):
+                    data['devices_standby'] += 1
+                continue
+
+            passed = bool(result['smart_status'].get('passed'))
+            data['devices_passed' if passed else 'devices_failed'] += 1
+            data.update(self.device_data(name, dev_type, result, passed))
+
+        return data
+
+    def device_data(self, name, dev_type, result, passed):
+        device, device_id = device_name(name, dev_type)
+        protocol = (result.get('device') or dict()).get('protocol')
+        attributes = dict(
+            (clean_name(a.get('name', str(a.get('id')))), a)
+            for a in (result.get('ata_smart_attributes') or dict()).get('table') or list()
+        )
+
+        if device_id not in self.collected_devices:
+            self.add_device_charts(device_id, device, protocol, sorted(attributes))
+
+        prefix = 'device_{0}_'.format(device_id)
+        data = {
+            prefix + 'passed': int(passed),
+            prefix + 'failed': int(not passed),
+        }
+        if 'temperature' in result:
+            data[prefix + 'temperature'] = result['temperature'].get('current', 0)
+        if 'power_on_time' in result:
+            data[prefix + 'power_on_hours'] = result['power_on_time'].get('hours', 0)
+
+        if protocol == 'ATA':
+            by_id = dict((a.get('id'), a) for a in attributes.values())
+            for attribute_id, key in ATA_ATTRIBUTES:
+                if attribute_id in by_id:
+                    data[prefix + key] = (by_id[attribute_id].get('raw') or dict()).get('value', 0)
+            for attribute_name, attribute in attributes.items():
+                data['{0}attribute_{1}'.format(prefix, attribute_name)] = attribute.get('value', 0)
+        elif protocol == 'NVMe':
+            log = result.get('nvme_smart_health_information_log') or dict()
+            for field, key in NVME_LOG:
+                if field in log:
+                    data[prefix + key] = log[field]
+
+        return data
+
+    def add_device_charts(self, device_id, device, protocol, attributes):
+        order, charts = device_charts(device_id, device, protocol, attributes)
+        self.collected_devices[device_id] = order
+        self.add_charts(order, charts)
+
+    def remove_device_charts(self, device_id):
+        self.remove_charts(self.collected_devices.pop(device_id))
@@ -3,7 +3,11 @@
 # Author: Pawel Krupa (paulfantom)
 # SPDX-License-Identifier: GPL-3.0-or-later
 
+import re
+from copy import deepcopy
+
 from bases.FrameworkServices.SocketService import SocketService
+from bases.collection import clean_name
 
 ORDER = [
     'clients_net',
@@ -45,6 +49,105 @@ CHARTS = {
     }
 }
 
+# cache manager pages, read along with 'counters' when 'detailed_pages' is enabled
+PAGE_INFO = 'info'
+PAGE_STOREDIR = 'storedir'
+PAGE_SERVICE_TIMES = 'service_times'
+
+PAGES_ORDER = {
+    PAGE_INFO: ['hit_ratios'],
+    PAGE_STOREDIR: ['storedir_utilization'],
+    PAGE_SERVICE_TIMES: ['service_times', 'dns_lookup_time'],
+}
+
+PAGES_CHARTS = {
+    'hit_ratios': {
+        'options': [None, 'Squid Hit Ratios', 'percentage', 'cache', 'squid.hit_ratios', 'line'],
+        'lines': [
+            ['hits_requests', 'requests', 'absolute', 1, 100],
+            ['hits_bytes', 'bytes', 'absolute', 1, 100],
+            ['hits_memory', 'memory', 'absolute', 1, 100],
+            ['hits_disk', 'disk', 'absolute', 1, 100]
+        ]
+    },
+    'storedir_utilization': {
+        'options': [None, 'Squid Storage Directories Utilization', 'percentage', 'storage',
+                    'squid.storedir_utilization', 'line'],
+        'lines': []
+    },
+    'service_times': {
+        'options': [None, 'Squid Median Service Times', 'milliseconds', 'service times', 'squid.service_times',
+                    'line'],
+        'lines': [
+            ['service_time_http_requests', 'http_requests', 'absolute', 1, 1000],
+            ['service_time_cache_misses', 'cache_misses', 'absolute', 1, 1000],
+            ['service_time_cache_hits', 'cache_hits', 'absolute', 1, 1000],
+            ['service_time_near_hits', 'near_hits', 'absolute', 1, 1000],
+            ['service_time_not_modified_replies', 'not_modified_replies', 'absolute', 1, 1000],
+            ['service_time_icp_queries', 'icp_queries', 'absolute', 1, 1000]
+        ]
+    },
+    'dns_lookup_time': {
+        'options': [None, 'Squid DNS Lookup Time', 'milliseconds', 'service times', 'squid.dns_lookup_time', 'line'],
+        'lines': [
+            ['dns_lookups_p50', 'median', 'absolute', 1, 1000],
+            ['dns_lookups_p95', 'p95', 'absolute', 1, 1000]
+        ]
+    }
+}
+
+# 'Hits as % of all requests: 5min: 12.5%, 60min: 10.3%', the 5 minutes ratios
+RE_HIT_RATIO = re.compile(r'^\s*(Hits as % of all requests|Hits as % of bytes sent|Memory hits as % of hit requests'
+                          r'|Disk hits as % of hit requests):\s*5min:\s*(-?[\d.]+)%', re.M)
+
+HIT_RATIOS = {
+    'Hits as % of all requests': 'hits_requests',
+    'Hits as % of bytes sent': 'hits_bytes',
+    'Memory hits as % of hit requests': 'hits_memory',
+    'Disk hits as % of hit requests': 'hits_disk',
+}
+
+# 'Store Directory #0 (aufs): /var/spool/squid' ... 'Percent Used: 1.18%'
+RE_STOREDIR = re.compile(r'^Store Directory #\d+ \([^)]*\): (\S+)$', re.M)
+RE_PERCENT_USED = re.compile(r'^Percent Used: ([\d.]+)%', re.M)
+
+# 'HTTP Requests (All):  50%   0.04277   0.04277', the 5 minutes percentiles in seconds
+RE_SERVICE_TIME = re.compile(r'^\s*([^:\n]+):\s+(\d+)%\s+([\d.]+)\s+[\d.]+', re.M)
+
+SERVICE_TIMES = {
+    'HTTP Requests (All)': 'service_time_http_requests',
+    'Cache Misses': 'service_time_cache_misses',
+    'Cache Hits': 'service_time_cache_hits',
+    'Near Hits': 'service_time_near_hits',
+    'Not-Modified Replies': 'service_time_not_modified_replies',
+    'ICP Queries': 'service_time_icp_queries',
+}
+
+DNS_LOOKUPS = 'DNS Lookups'
+
+
+def response_body(response):
+    """
+    :param response: <str> HTTP response
+    :return: <str> the body, de-chunked
+    """
+    head, _, body = response.partition('\r\n\r\n')
+    if 'transfer-encoding: chunked' not in head.lower():
+        return body
+
+    chunks = list()
+    while body:
+        size, _, body = body.partition('\r\n')
+        try:
+            size = int(size.split(';')[0], 16)
+        except ValueError:
+            break
+        if not size:
+            break
+        chunks.append(body[:size])
+        body = body[size + 2:]
+    return ''.join(chunks)
+
 
 class Service(SocketService):
     def __init__(self, configuration=None, name=None):
@@ -53,8 +156,12 @@ class Service(SocketService):
         self.request = ''
         self.host = 'localhost'
         self.port = 3128
-        self.order = ORDER
-        self.definitions = CHARTS
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.detailed_pages = self.configuration.get('detailed_pages', True)
+        # page -> request, the pages available on this squid
+        self.page_requests = dict()
+        self.collected_dirs = set()
 
     def _get_data(self):
         """
@@ -65,11 +172,7 @@ class Service(SocketService):
 
         data = dict()
         try:
-            raw = ''
-            for tmp in response.split('\r\n'):
-                if tmp.startswith('sample_time'):
-                    raw = tmp
-                    break
+            raw = response_body(response)
 
             if raw.startswith('<'):
                 self.error('invalid data received')
@@ -87,6 +190,58 @@ class Service(SocketService):
         if not data:
             self.error('no data received')
             return None
+
+        for page, request in self.page_requests.items():
+            data.update(self.get_page_data(page, request) or dict())
+        return data
+
+    def get_page_data(self, page, request):
+        response = self._get_raw_data(request=request)
+        if not response:
+            return None
+        body = response_body(response)
+        if page == PAGE_INFO:
+            return self.parse_info(body)
+        if page == PAGE_STOREDIR:
+            return self.parse_storedir(body)
+        return self.parse_service_times(body)
+
+    @staticmethod
+    def parse_info(body):
+        data = dict()
+        for name, value in RE_HIT_RATIO.findall(body):
+            data[HIT_RATIOS[name]] = int(float(value) * 100)
+        return data
+
+    def parse_storedir(self, body):
+        data = dict()
+        # every store directory is a block starting with its 'Store Directory' line
+        dirs = list(RE_STOREDIR.finditer(body))
+        for i, match in enumerate(dirs):
+            end = dirs[i + 1].start() if i + 1 < len(dirs) else len(body)
+            used = RE_PERCENT_USED.search(body, match.end(), end)
+            if not used:
+                continue
+            path = match.group(1)
+            dim_id = 'storedir_{0}'.format(clean_name(path))
+            if dim_id not in self.collected_dirs:
+                self.collected_dirs.add(dim_id)
+                dimension = [dim_id, path, 'absolute', 1, 100]
+                self.add_dimension('storedir_utilization', dimension)
+            data[dim_id] = int(float(used.group(1)) * 100)
+        return data
+
+    @staticmethod
+    def parse_service_times(body):
+        data = dict()
+        for name, percentile, value in RE_SERVICE_TIME.findall(body):
+            name = name.strip()
+            # seconds, the dimensions divisor makes them milliseconds
+            value = int(float(value) * 1000000)
+            if percentile == '50' and name in SERVICE_TIMES:
+                data[SERVICE_TIMES[name]] = value
+            elif name == DNS_LOOKUPS and percentile in ('50', '95'):
+                data['dns_lookups_p' + percentile] = value
         return data
 
     def _check_raw_data(self, data):
@@ -117,7 +272,28 @@ class Service(SocketService):
         if not req.endswith(' HTTP/1.1\r\n\r\n'):
             req += ' HTTP/1.1\r\n\r\n'
         self.request = req.encode()
-        if self._get_data() is not None:
-            return True
-        else:
+        if self._get_data() is None:
             return False
+
+        if self.detailed_pages:
+            self.check_pages(req)
+        return True
+
+    def check_pages(self, request):
+        # the pages are next to 'counters': 'cache_object://localhost:3128/info', '/squid-internal-mgr/info'
+        if '/counters ' not in request:
+            self.debug('the request is not the one of the counters page, not reading the other pages')
+            return
+
+        for page in (PAGE_INFO, PAGE_STOREDIR, PAGE_SERVICE_TIMES):
+            page_request = request.replace('/counters ', '/{0} '.format(page)).encode()
+            # the storage directories are added to their chart while parsing the page
+            for chart in PAGES_ORDER[page]:
+                self.definitions[chart] = deepcopy(PAGES_CHARTS[chart])
+            if not self.get_page_data(page, page_request):
+                self.debug('no data in the {0} page, not charting it'.format(page))
+                for chart in PAGES_ORDER[page]:
+                    del self.definitions[chart]
+                continue
+            self.page_requests[page] = page_request
+            self.order.extend(PAGES_ORDER[page])
@@ -0,0 +1,293 @@
+# -*- coding: utf-8 -*-
+# Description: tcp_destinations netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import socket
+import struct
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import clean_name, find_binary
+
+update_every = 5
+
+SS = 'ss'
+
+OTHER = 'other'
+
+DEFAULT_MAX_DESTINATIONS = 50
+
+
+def destination_charts(name):
+    order = [
+        'dest_{0}_connections'.format(name),
+        'dest_{0}_retransmits'.format(name),
+        'dest_{0}_lossy'.format(name),
+    ]
+    charts = {
+        order[0]: {
+            'options': [None, 'Outbound Connections', 'connections', name, 'tcp_destinations.connections', 'line'],
+            'lines': [
+                ['dest_{0}_established'.format(name), 'established'],
+                ['dest_{0}_syn_sent'.format(name), 'connecting'],
+                ['dest_{0}_syn_retrying'.format(name), 'connect retrying'],
+                ['dest_{0}_closing'.format(name), 'closing'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Retransmitted Segments', 'segments/s', name, 'tcp_destinations.retransmits', 'line'],
+            'lines': [
+                ['dest_{0}_retrans_data'.format(name), 'data', 'incremental'],
+                ['dest_{0}_retrans_syn'.format(name), 'syn', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Connections With Outstanding Retransmits', 'connections', name,
+                        'tcp_destinations.lossy', 'line'],
+            'lines': [
+                ['dest_{0}_lossy'.format(name), 'lossy'],
+            ]
+        },
+    }
+    return order, charts
+
+
+CLOSING_STATES = (
+    'FIN-WAIT-1',
+    'FIN-WAIT-2',
+    'CLOSE-WAIT',
+    'LAST-ACK',
+    'CLOSING',
+)
+
+
+def parse_address(value):
+    """
+    Split an ss address ('10.0.0.1:80', '[::1]:80', '[fe80::1%eth0]:80', '::ffff:10.0.0.1:80')
+    into (family, packed address, port). Returns None for wildcards.
+    """
+    host, _, port = value.rpartition(':')
+    host = host.strip('[]').split('%')[0]
+    if not host or host == '*' or not port.isdigit():
+        return None
+
+    if host.startswith('::ffff:') and '.' in host:
+        host = host[7:]
+
+    for family in (socket.AF_INET, socket.AF_INET6):
+        try:
+            return family, socket.inet_pton(family, host), int(port)
+        except (socket.error, ValueError):
+            continue
+    return None
+
+
+def address_to_int(packed):
+    if len(packed) == 4:
+        return struct.unpack('!I', packed)[0]
+    high, low = struct.unpack('!QQ', packed)
+    return (high << 64) | low
+
+
+def parse_subnet(value):
+    """
+    Parse '10.0.0.0/8', 'fd00::/8' or a single address into (family, network, prefix).
+    """
+    host, _, prefix = value.partition('/')
+    for family, bits in ((socket.AF_INET, 32), (socket.AF_INET6, 128)):
+        try:
+            network = address_to_int(socket.inet_pton(family, host))
+        except (socket.error, ValueError):
+            continue
+        prefix = int(prefix) if prefix else bits
+        if not 0 <= prefix <= bits:
+            return None
+        mask = ((1 << prefix) - 1) << (bits - prefix)
+        return family, network & mask, mask
+    return None
+
+
+class Selector:
+    def __init__(self, name, subnets, ports):
+        self.name = name
+        self.subnets = subnets
+        self.ports = ports
+
+    def match(self, family, address, port):
+        if self.ports and port not in self.ports:
+            return False
+        if not self.subnets:
+            return True
+        address = address_to_int(address)
+        for subnet_family, network, mask in self.subnets:
+            if subnet_family == family and address & mask == network:
+                return True
+        return False
+
+
+def parse_sockets(raw):
+    """
+    Parse 'ss -tanHi' output. Every socket is a state line followed by an indented info line.
+    Yields (state, local, remote, retrans_outstanding, retrans_total).
+    """
+    current = None
+    for line in raw:
+        if not line.strip():
+            continue
+
+        if not line[0].isspace():
+            if current:
+                yield current
+            parts = line.split()
+            if len(parts) < 5:
+                current = None
+                continue
+            current = [parts[0], parts[3], parts[4], 0, 0]
+            continue
+
+        if current is None:
+            continue
+
+        for field in line.split():
+            if field.startswith('retrans:'):
+                outstanding, _, total = field[8:].partition('/')
+                if outstanding.isdigit() and total.isdigit():
+                    current[3], current[4] = int(outstanding), int(total)
+                break
+
+    if current:
+        yield current
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list()
+        self.definitions = dict()
+        self.ss = self.configuration.get('ss_path')
+        self.selectors = list()
+        self.collect_other = self.configuration.get('collect_other', True)
+        self.max_destinations = self.configuration.get('max_destinations', DEFAULT_MAX_DESTINATIONS)
+        self.collected_destinations = set()
+        self.retrans_totals = dict()
+        self.counters = dict()
+
+    def check(self):
+        self.ss = self.ss or find_binary(SS)
+        if not self.ss:
+            self.error('can\'t locate "{0}" binary'.format(SS))
+            return False
+
+        if not self.parse_selectors():
+            return False
+
+        if self._get_raw_data(command=[self.ss, '-tanHi']) is None:
+            return False
+
+        self.add_destination_charts(OTHER)
+        return True
+
+    def parse_selectors(self):
+        for conf in self.configuration.get('destinations') or list():
+            if not isinstance(conf, dict) or not conf.get('name'):
+                self.error('destination "{0}" has no name'.format(conf))
+                return False
+
+            subnets = list()
+            for value in conf.get('subnets') or list():
+                subnet = parse_subnet(str(value))
+                if not subnet:
+                    self.error('destination "{0}": invalid subnet "{1}"'.format(conf['name'], value))
+                    return False
+                subnets.append(subnet)
+
+            ports = set()
+            for value in conf.get('ports') or list():
+                try:
+                    ports.add(int(value))
+                except (TypeError, ValueError):
+                    self.error('destination "{0}": invalid port "{1}"'.format(conf['name'], value))
+                    return False
+
+            self.selectors.append(Selector(clean_name(conf['name']), subnets, ports))
+        return True
+
+    def destination(self, family, address, port):
+        if self.selectors:
+            for selector in self.selectors:
+                if selector.match(family, address, port):
+                    return selector.name
+            return OTHER if self.collect_other else None
+
+        # no selectors configured, group by remote port, 'other' is always collected and not counted
+        name = 'port_{0}'.format(port)
+        if name in self.collected_destinations or len(self.collected_destinations - {OTHER}) < self.max_destinations:
+            return name
+        return OTHER
+
+    def _get_data(self):
+        raw = self._get_raw_data(command=[self.ss, '-tanHi'])
+        if raw is None:
+            return None
+
+        sockets = list(parse_sockets(raw))
+
+        # connections accepted on a local listening port are inbound, their remote port means nothing
+        listening = set(local.rpartition(':')[2] for state, local, _, _, _ in sockets if state == 'LISTEN')
+
+        data = dict()
+        retrans_totals = dict()
+
+        for state, local, remote, outstanding, total in sockets:
+            if state in ('LISTEN', 'TIME-WAIT', 'SYN-RECV') or local.rpartition(':')[2] in listening:
+                continue
+
+            address = parse_address(remote)
+            if not address:
+                continue
+
+            name = self.destination(*address)
+            if not name:
+                continue
+
+            if name not in self.collected_destinations:
+                self.add_destination_charts(name)
+
+            prefix = 'dest_{0}_'.format(name)
+            if prefix + 'established' not in data:
+                for key in ('established', 'syn_sent', 'syn_retrying', 'closing', 'lossy'):
+                    data[prefix + key] = 0
+
+            syn = state == 'SYN-SENT'
+            if state == 'ESTAB':
+                data[prefix + 'established'] += 1
+            elif syn:
+                data[prefix + 'syn_sent'] += 1
+                data[prefix + 'syn_retrying'] += int(total > 0)
+            elif state in CLOSING_STATES:
+                data[prefix + 'closing'] += 1
+
+            data[prefix + 'lossy'] += int(outstanding > 0)
+
+            # ss reports per socket totals, turn them into per destination counters that survive socket close
+            key = (local, remote)
+            retrans_totals[key] = total
+            delta = total - self.retrans_totals.get(key, 0)
+            if delta > 0:
+                counter = prefix + ('retrans_syn' if syn else 'retrans_data')
+                self.counters[counter] = self.counters.get(counter, 0) + delta
+
+        self.retrans_totals = retrans_totals
+
+        for name in self.collected_destinations:
+            prefix = 'dest_{0}_'.format(name)
+            for key in ('established', 'syn_sent', 'syn_retrying', 'closing', 'lossy'):
+                data.setdefault(prefix + key, 0)
+            for key in ('retrans_data', 'retrans_syn'):
+                data[prefix + key] = self.counters.get(prefix + key, 0)
+
+        return data
+
+    def add_destination_charts(self, name):
+        self.collected_destinations.add(name)
+        order, charts = destination_charts(name)
+        self.add_charts(order, charts)
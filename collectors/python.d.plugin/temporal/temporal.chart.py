@@ -0,0 +1,226 @@
+# -*- coding: utf-8 -*-
+# Description: temporal netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from collections import defaultdict
+from copy import deepcopy
+from fnmatch import fnmatch
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse
+
+update_every = 10
+
+# the counters of the opentelemetry metrics framework have the '_total' suffix, the ones of tally do not
+METRIC_PERSISTENCE_REQUESTS = ('persistence_requests', 'persistence_requests_total')
+METRIC_PERSISTENCE_ERRORS = ('persistence_errors', 'persistence_errors_total')
+METRIC_LOCK_REQUESTS = ('lock_requests', 'lock_requests_total')
+METRIC_LOCK_LATENCY = 'lock_latency_bucket'
+METRIC_SCHEDULE_TO_START_LATENCY = 'task_schedule_to_start_latency_bucket'
+# Temporal 1.23 and newer
+METRIC_BACKLOG = 'approximate_backlog_count'
+
+TASK_TYPES = ('workflow', 'activity')
+
+# the persistence operations of the server itself, not of a namespace
+UNKNOWN_NAMESPACES = ('', '_unknown_', 'all')
+
+QUANTILES = (
+    (0.5, 'p50'),
+    (0.95, 'p95'),
+    (0.99, 'p99'),
+)
+
+ORDER = [
+    'persistence_requests',
+    'shard_lock_requests',
+    'shard_lock_latency',
+]
+
+CHARTS = {
+    'persistence_requests': {
+        'options': [None, 'Persistence Requests', 'requests/s', 'persistence', 'temporal.persistence_requests',
+                    'line'],
+        'lines': [
+            ['persistence_requests', 'requests', 'incremental'],
+            ['persistence_errors', 'errors', 'incremental'],
+        ]
+    },
+    'shard_lock_requests': {
+        'options': [None, 'Shard Lock Requests', 'requests/s', 'shards', 'temporal.shard_lock_requests', 'line'],
+        'lines': [
+            ['lock_requests', 'requests', 'incremental'],
+        ]
+    },
+    'shard_lock_latency': {
+        'options': [None, 'Shard Lock Latency', 'milliseconds', 'shards', 'temporal.shard_lock_latency', 'line'],
+        'lines': [['lock_latency_{0}'.format(q), q, 'absolute', 1, 1000] for _, q in QUANTILES]
+    },
+}
+
+
+def namespace_charts(namespace_id, namespace):
+    prefix = 'namespace_{0}'.format(namespace_id)
+    order = [
+        prefix + '_workflow_task_latency',
+        prefix + '_backlog',
+        prefix + '_persistence_requests',
+    ]
+    family = 'namespace ' + namespace
+    charts = {
+        order[0]: {
+            'options': [None, 'Workflow Task Schedule To Start Latency', 'milliseconds', family,
+                        'temporal.namespace_workflow_task_latency', 'line'],
+            'lines': [['{0}_workflow_task_latency_{1}'.format(prefix, q), q, 'absolute', 1, 1000]
+                      for _, q in QUANTILES]
+        },
+        order[1]: {
+            'options': [None, 'Task Queues Backlog', 'tasks', family, 'temporal.namespace_backlog', 'stacked'],
+            'lines': [['{0}_backlog_{1}'.format(prefix, t), t] for t in TASK_TYPES]
+        },
+        order[2]: {
+            'options': [None, 'Persistence Requests', 'requests/s', family,
+                        'temporal.namespace_persistence_requests', 'line'],
+            'lines': [
+                [prefix + '_persistence_requests', 'requests', 'incremental'],
+                [prefix + '_persistence_errors', 'errors', 'incremental'],
+            ]
+        },
+    }
+    return order, charts
+
+
+class NamespaceSelector:
+    """
+    Shell wildcard patterns, patterns starting with '!' are negative, the first matching pattern wins.
+    Namespaces not matching any pattern are selected only if all the patterns are negative.
+    """
+
+    def __init__(self, patterns):
+        self.patterns = list()
+        for pattern in patterns or list():
+            pattern = str(pattern)
+            self.patterns.append((not pattern.startswith('!'), pattern.lstrip('!')))
+        self.default = not any(p[0] for p in self.patterns)
+
+    def selected(self, namespace):
+        for positive, pattern in self.patterns:
+            if fnmatch(namespace, pattern):
+                return positive
+        return self.default
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8000/metrics')
+        self.selector = NamespaceSelector(self.configuration.get('namespaces'))
+        # namespace id -> <list> of its charts
+        self.collected_namespaces = dict()
+        # histogram -> <dict> le -> count, the buckets at the previous run
+        self.buckets = dict()
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        samples = parse(raw)
+        if not any(s[0] in METRIC_PERSISTENCE_REQUESTS for s in samples):
+            self.debug('no persistence requests in {0}, not a temporal server'.format(self.url))
+            return None
+
+        data = {
+            'persistence_requests': 0,
+            'persistence_errors': 0,
+            'lock_requests': 0,
+        }
+        namespaces = defaultdict(lambda: defaultdict(int))
+        latency = defaultdict(lambda: defaultdict(int))
+        lock_latency = defaultdict(int)
+
+        for name, labels, value in samples:
+            namespace = labels.get('namespace', '')
+            if name in METRIC_PERSISTENCE_REQUESTS:
+                data['persistence_requests'] += int(value)
+                namespaces[namespace]['persistence_requests'] += int(value)
+            elif name in METRIC_PERSISTENCE_ERRORS:
+                data['persistence_errors'] += int(value)
+                namespaces[namespace]['persistence_errors'] += int(value)
+            elif name in METRIC_LOCK_REQUESTS:
+                data['lock_requests'] += int(value)
+            elif name == METRIC_LOCK_LATENCY:
+                lock_latency[labels.get('le')] += value
+            elif name == METRIC_SCHEDULE_TO_START_LATENCY and labels.get('task_type', '').lower() == 'workflow':
+                latency[namespace][labels.get('le')] += value
+            elif name == METRIC_BACKLOG:
+                task_type = labels.get('task_type', '').lower()
+                if task_type in TASK_TYPES:
+                    namespaces[namespace]['backlog_' + task_type] += int(value)
+
+        data.update(self.quantiles_data('lock_latency', lock_latency))
+
+        seen = set()
+        for namespace in set(namespaces) | set(latency):
+            if namespace in UNKNOWN_NAMESPACES or not self.selector.selected(namespace):
+                continue
+            namespace_id = clean_name(namespace)
+            seen.add(namespace_id)
+            if namespace_id not in self.collected_namespaces:
+                self.add_namespace_charts(namespace_id, namespace)
+
+            prefix = 'namespace_{0}_'.format(namespace_id)
+            for key in ('persistence_requests', 'persistence_errors', 'backlog_workflow', 'backlog_activity'):
+                data[prefix + key] = namespaces[namespace][key]
+            data.update(self.quantiles_data(prefix + 'workflow_task_latency', latency[namespace]))
+
+        # deleted namespaces
+        for namespace_id in set(self.collected_namespaces) - seen:
+            self.remove_namespace_charts(namespace_id)
+
+        return data
+
+    def quantiles_data(self, histogram, buckets):
+        previous, self.buckets[histogram] = self.buckets.get(histogram), buckets
+        # the percentiles of the observations since the previous run, 0 without observations
+        delta = dict((le, count - (previous or dict()).get(le, 0)) for le, count in buckets.items())
+        data = dict()
+        for quantile, name in QUANTILES:
+            value = histogram_quantile(quantile, delta) if previous else None
+            data['{0}_{1}'.format(histogram, name)] = int((value or 0) * 1000000)
+        return data
+
+    def add_namespace_charts(self, namespace_id, namespace):
+        order, charts = namespace_charts(namespace_id, namespace)
+        self.collected_namespaces[namespace_id] = order
+        self.add_charts(order, charts)
+
+    def remove_namespace_charts(self, namespace_id):
+        self.buckets.pop('namespace_{0}_workflow_task_latency'.format(namespace_id), None)
+        self.remove_charts(self.collected_namespaces.pop(namespace_id))
+
+
+def histogram_quantile(quantile, buckets):
+    """
+    :param quantile: <float> 0-1
+    :param buckets: <dict> upper bound <str> -> cumulative count, '+Inf' included
+    :return: <float> the quantile interpolated within its bucket, None without observations
+    """
+    bounds = sorted((float(le), count) for le, count in buckets.items() if le)
+    if not bounds or bounds[-1][1] <= 0:
+        return None
+
+    rank = quantile * bounds[-1][1]
+    lower, lower_count = 0.0, 0
+    for upper, count in bounds:
+        if count >= rank:
+            if upper == float('inf'):
+                return lower
+            if count == lower_count:
+                return upper
+            return lower + (upper - lower) * (rank - lower_count) / (count - lower_count)
+        lower, lower_count = upper, count
+    return lower
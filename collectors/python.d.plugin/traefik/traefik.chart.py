@@ -3,10 +3,14 @@
 # Author: Alexandre Menezes (@ale_menezes)
 # SPDX-License-Identifier: GPL-3.0-or-later
 
+import time
 from collections import defaultdict
+from copy import deepcopy
 from json import loads
 
 from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse
 
 ORDER = [
     'response_statuses',
@@ -87,6 +91,72 @@ HEALTH_STATS = [
     'total_status_code_count'
 ]
 
+# Traefik v2 and v3 prometheus metrics, the health API is gone since v2
+
+METRICS_ORDER = [
+    'tls_certs_expiry',
+]
+
+METRICS_CHARTS = {
+    'tls_certs_expiry': {
+        'options': [None, 'TLS Certificates Time Until Expiration', 'days', 'tls', 'traefik.tls_certs_expiry',
+                    'line'],
+        'lines': []
+    },
+}
+
+# (kind, label, requests metric, request duration histogram)
+METRICS_ENTITIES = (
+    ('entrypoint', 'entrypoint', 'traefik_entrypoint_requests_total', 'traefik_entrypoint_request_duration_seconds'),
+    ('router', 'router', 'traefik_router_requests_total', 'traefik_router_request_duration_seconds'),
+    ('service', 'service', 'traefik_service_requests_total', 'traefik_service_request_duration_seconds'),
+)
+
+# v3 and v2 names
+METRICS_OPEN_CONNECTIONS = ('traefik_open_connections', 'traefik_entrypoint_open_connections')
+METRIC_CERT_NOT_AFTER = 'traefik_tls_certs_not_after'
+
+CODE_CLASSES = ('1xx', '2xx', '3xx', '4xx', '5xx', 'other')
+
+QUANTILES = (
+    (0.5, 'p50'),
+    (0.95, 'p95'),
+    (0.99, 'p99'),
+)
+
+
+def entity_charts(kind, entity_id, entity):
+    prefix = '{0}_{1}'.format(kind, entity_id)
+    order = [
+        prefix + '_requests',
+        prefix + '_request_duration',
+    ]
+    family = '{0} {1}'.format(kind, entity)
+    charts = {
+        order[0]: {
+            'options': [None, 'Requests By Status Code Class', 'requests/s', family,
+                        'traefik.{0}_requests'.format(kind), 'stacked'],
+            'lines': [['{0}_{1}'.format(prefix, c), c, 'incremental'] for c in CODE_CLASSES]
+        },
+        order[1]: {
+            'options': [None, 'Request Duration', 'milliseconds', family,
+                        'traefik.{0}_request_duration'.format(kind), 'line'],
+            'lines': [['{0}_duration_{1}'.format(prefix, q), q, 'absolute', 1, 1000] for _, q in QUANTILES]
+        },
+    }
+
+    if kind == 'entrypoint':
+        order.append(prefix + '_open_connections')
+        charts[order[-1]] = {
+            'options': [None, 'Open Connections', 'connections', family, 'traefik.entrypoint_open_connections',
+                        'line'],
+            'lines': [
+                [prefix + '_open_connections', 'open'],
+            ]
+        }
+
+    return order, charts
+
 
 class Service(UrlService):
     def __init__(self, configuration=None, name=None):
@@ -94,6 +164,21 @@ class Service(UrlService):
         self.url = self.configuration.get('url', 'http://localhost:8080/health')
         self.order = ORDER
         self.definitions = CHARTS
+        # Traefik v2 and v3 have no health API, their jobs use the prometheus metrics
+        self.prometheus = self.url.rstrip('/').endswith('/metrics')
+        if self.prometheus:
+            self.order = list(METRICS_ORDER)
+            self.definitions = deepcopy(METRICS_CHARTS)
+        self.do_kinds = {
+            'entrypoint': self.configuration.get('entrypoint_charts', True),
+            'router': self.configuration.get('router_charts', False),
+            'service': self.configuration.get('service_charts', True),
+        }
+        # (kind, id) -> <list> of its charts
+        self.collected_entities = dict()
+        # (kind, id) -> <dict> le -> count, the request duration buckets at the previous run
+        self.buckets = dict()
+        self.collected_certs = set()
         self.last_total_response_time = 0
         self.last_total_count = 0
         self.data = {
@@ -112,6 +197,9 @@ class Service(UrlService):
         }
 
     def _get_data(self):
+        if self.prometheus:
+            return self.get_metrics_data()
+
         data = self._get_raw_data()
 
         if not data:
@@ -181,6 +269,110 @@ class Service(UrlService):
                     self.charts['detailed_response_codes'].add_dimension([code, code, 'incremental'])
                 self.data[code] = value
 
+    def get_metrics_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        samples = parse(raw)
+        if not any(s[0].startswith('traefik_') for s in samples):
+            return None
+
+        data = dict()
+        seen = set()
+        for kind, label, requests_metric, duration_metric in METRICS_ENTITIES:
+            if not self.do_kinds[kind]:
+                continue
+            requests, buckets = defaultdict(lambda: defaultdict(int)), defaultdict(lambda: defaultdict(int))
+            for name, labels, value in samples:
+                entity = labels.get(label)
+                if not entity:
+                    continue
+                if name == requests_metric:
+                    requests[entity][code_class(labels.get('code', ''))] += value
+                elif name == duration_metric + '_bucket':
+                    buckets[entity][labels.get('le')] += value
+
+            for entity in requests:
+                key = (kind, clean_name(entity))
+                seen.add(key)
+                if key not in self.collected_entities:
+                    self.add_entity_charts(kind, key[1], entity)
+
+                prefix = '{0}_{1}_'.format(*key)
+                for cls in CODE_CLASSES:
+                    data[prefix + cls] = int(requests[entity][cls])
+                data.update(self.duration_data(key, prefix, buckets[entity]))
+
+        for name, labels, value in samples:
+            if name in METRICS_OPEN_CONNECTIONS and self.do_kinds['entrypoint'] and labels.get('entrypoint'):
+                dim_id = 'entrypoint_{0}_open_connections'.format(clean_name(labels['entrypoint']))
+                data[dim_id] = data.get(dim_id, 0) + int(value)
+            elif name == METRIC_CERT_NOT_AFTER and labels.get('cn'):
+                data.update(self.cert_data(labels['cn'], value))
+
+        # removed entrypoints, routers and services
+        for key in set(self.collected_entities) - seen:
+            self.remove_entity_charts(key)
+
+        return data
+
+    def duration_data(self, key, prefix, buckets):
+        previous, self.buckets[key] = self.buckets.get(key), buckets
+        # the percentiles of the requests since the previous run, 0 without requests
+        delta = dict((le, count - (previous or dict()).get(le, 0)) for le, count in buckets.items())
+        data = dict()
+        for quantile, name in QUANTILES:
+            value = histogram_quantile(quantile, delta) if previous else None
+            data['{0}duration_{1}'.format(prefix, name)] = int((value or 0) * 1000000)
+        return data
+
+    def cert_data(self, cn, not_after):
+        dim_id = 'tls_cert_{0}'.format(clean_name(cn))
+        if dim_id not in self.collected_certs:
+            self.collected_certs.add(dim_id)
+            dimension = [dim_id, cn, 'absolute', 1, 86400]
+            self.add_dimension('tls_certs_expiry', dimension)
+        return {dim_id: int(not_after - time.time())}
+
+    def add_entity_charts(self, kind, entity_id, entity):
+        order, charts = entity_charts(kind, entity_id, entity)
+        self.collected_entities[(kind, entity_id)] = order
+        self.add_charts(order, charts)
+
+    def remove_entity_charts(self, key):
+        self.buckets.pop(key, None)
+        self.remove_charts(self.collected_entities.pop(key))
+
+
+def code_class(code):
+    if len(code) == 3 and code[0] in '12345':
+        return code[0] + 'xx'
+    return 'other'
+
+
+def histogram_quantile(quantile, buckets):
+    """
+    :param quantile: <float> 0-1
+    :param buckets: <dict> upper bound <str> -> cumulative count, '+Inf' included
+    :return: <float> the quantile interpolated within its bucket, None without observations
+    """
+    bounds = sorted((float(le), count) for le, count in buckets.items() if le)
+    if not bounds or bounds[-1][1] <= 0:
+        return None
+
+    rank = quantile * bounds[-1][1]
+    lower, lower_count = 0.0, 0
+    for upper, count in bounds:
+        if count >= rank:
+            if upper == float('inf'):
+                return lower
+            if count == lower_count:
+                return upper
+            return lower + (upper - lower) * (rank - lower_count) / (count - lower_count)
+        lower, lower_count = upper, count
+    return lower
+
 
 def fetch_data_(raw_data, metrics):
     data = dict()
@@ -0,0 +1,228 @@
+# -*- coding: utf-8 -*-
+# Description: nvidia triton inference server netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+from bases.prometheus import parse, sum_by
+
+update_every = 5
+
+METRIC_SUCCESS = 'nv_inference_request_success'
+METRIC_FAILURE = 'nv_inference_request_failure'
+METRIC_INFERENCES = 'nv_inference_count'
+METRIC_EXECUTIONS = 'nv_inference_exec_count'
+METRIC_PENDING = 'nv_inference_pending_request_count'
+METRIC_GPU_UTILIZATION = 'nv_gpu_utilization'
+METRIC_GPU_MEMORY_USED = 'nv_gpu_memory_used_bytes'
+
+# cumulative durations of all the requests, in microseconds
+DURATIONS = (
+    ('nv_inference_queue_duration_us', 'queue'),
+    ('nv_inference_compute_input_duration_us', 'compute_input'),
+    ('nv_inference_compute_infer_duration_us', 'compute_infer'),
+    ('nv_inference_compute_output_duration_us', 'compute_output'),
+    ('nv_inference_request_duration_us', 'request'),
+)
+
+METRICS = (
+    METRIC_SUCCESS,
+    METRIC_FAILURE,
+    METRIC_INFERENCES,
+    METRIC_EXECUTIONS,
+    METRIC_PENDING,
+    METRIC_GPU_UTILIZATION,
+    METRIC_GPU_MEMORY_USED,
+) + tuple(m for m, _ in DURATIONS)
+
+ORDER = [
+    'requests',
+    'pending_requests',
+    'gpu_utilization',
+    'gpu_memory',
+]
+
+CHARTS = {
+    'requests': {
+        'options': [None, 'Inference Requests', 'requests/s', 'overview', 'triton.requests', 'stacked'],
+        'lines': [
+            ['requests_success', 'success', 'incremental'],
+            ['requests_failure', 'failure', 'incremental'],
+        ]
+    },
+    'pending_requests': {
+        'options': [None, 'Pending Requests', 'requests', 'overview', 'triton.pending_requests', 'line'],
+        'lines': [
+            ['requests_pending', 'pending'],
+        ]
+    },
+    'gpu_utilization': {
+        'options': [None, 'GPU Utilization', 'percentage', 'gpu', 'triton.gpu_utilization', 'line'],
+        'lines': []
+    },
+    'gpu_memory': {
+        'options': [None, 'GPU Memory Used', 'MiB', 'gpu', 'triton.gpu_memory', 'line'],
+        'lines': []
+    },
+}
+
+
+def model_charts(model_id, model):
+    order = [
+        'model_{0}_requests'.format(model_id),
+        'model_{0}_inferences'.format(model_id),
+        'model_{0}_batch_size'.format(model_id),
+        'model_{0}_latency'.format(model_id),
+        'model_{0}_pending'.format(model_id),
+    ]
+    family = 'model ' + model
+    charts = {
+        order[0]: {
+            'options': [None, 'Inference Requests', 'requests/s', family, 'triton.model_requests', 'stacked'],
+            'lines': [
+                ['model_{0}_success'.format(model_id), 'success', 'incremental'],
+                ['model_{0}_failure'.format(model_id), 'failure', 'incremental'],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Inferences And Batch Executions', 'inferences/s', family, 'triton.model_inferences',
+                        'line'],
+            'lines': [
+                ['model_{0}_inferences'.format(model_id), 'inferences', 'incremental'],
+                ['model_{0}_executions'.format(model_id), 'executions', 'incremental'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Average Batch Size', 'inferences', family, 'triton.model_batch_size', 'line'],
+            'lines': [
+                ['model_{0}_batch_size'.format(model_id), 'batch_size', 'absolute', 1, 100],
+            ]
+        },
+        order[3]: {
+            'options': [None, 'Requests Average Latency', 'milliseconds', family, 'triton.model_latency', 'line'],
+            'lines': [['model_{0}_{1}_latency'.format(model_id, d), d, 'absolute', 1, 1000] for _, d in DURATIONS]
+        },
+        order[4]: {
+            'options': [None, 'Pending Requests', 'requests', family, 'triton.model_pending', 'line'],
+            'lines': [
+                ['model_{0}_pending'.format(model_id), 'pending'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def delta_average(previous, key, total, count, mult=1):
+    """
+    Average of the events counted since the last run, 0 when there are none.
+    :param previous: <dict> key -> (total, count) of the last run, updated
+    """
+    prev_total, prev_count = previous.get(key, (total, count))
+    previous[key] = (total, count)
+    if count > prev_count:
+        return int((total - prev_total) * mult / (count - prev_count))
+    return 0
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8002/metrics')
+        self.collected_models = set()
+        self.collected_gpus = set()
+        self.previous = dict()
+
+    def check(self):
+        if not UrlService.check(self):
+            return False
+        if not self.collected_gpus:
+            # cpu only server, or started with --allow-gpu-metrics=false
+            self.order = [c for c in self.order if c not in ('gpu_utilization', 'gpu_memory')]
+        return True
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        samples = parse(raw, names=METRICS)
+        if not samples:
+            self.error('no triton inference metrics found at {0}'.format(self.url))
+            return None
+
+        data = dict()
+        data.update(self.models_data(samples))
+        data.update(self.gpus_data(samples))
+        return data
+
+    def models_data(self, samples):
+        success = sum_by(samples, METRIC_SUCCESS, 'model')
+        failure = sum_by(samples, METRIC_FAILURE, 'model')
+        inferences = sum_by(samples, METRIC_INFERENCES, 'model')
+        executions = sum_by(samples, METRIC_EXECUTIONS, 'model')
+        pending = sum_by(samples, METRIC_PENDING, 'model')
+        durations = dict((d, sum_by(samples, m, 'model')) for m, d in DURATIONS)
+
+        data = {
+            'requests_success': 0,
+            'requests_failure': 0,
+            'requests_pending': 0,
+        }
+        for model in success:
+            model_id = clean_name(model)
+            if model_id not in self.collected_models:
+                self.collected_models.add(model_id)
+                self.add_model_charts(model_id, model)
+
+            prefix = 'model_{0}_'.format(model_id)
+            requests = success[model] + failure.get(model, 0)
+            data[prefix + 'success'] = int(success[model])
+            data[prefix + 'failure'] = int(failure.get(model, 0))
+            data[prefix + 'inferences'] = int(inferences.get(model, 0))
+            data[prefix + 'executions'] = int(executions.get(model, 0))
+            data[prefix + 'pending'] = int(pending.get(model, 0))
+            data[prefix + 'batch_size'] = delta_average(
+                self.previous, prefix + 'batch_size', inferences.get(model, 0), executions.get(model, 0), 100)
+            for _, duration in DURATIONS:
+                # microseconds
+                data[prefix + duration + '_latency'] = delta_average(
+                    self.previous, prefix + duration, durations[duration].get(model, 0), requests)
+
+            data['requests_success'] += data[prefix + 'success']
+            data['requests_failure'] += data[prefix + 'failure']
+            data['requests_pending'] += data[prefix + 'pending']
+
+        return data
+
+    def gpus_data(self, samples):
+        utilization = sum_by(samples, METRIC_GPU_UTILIZATION, 'gpu_uuid')
+        used = sum_by(samples, METRIC_GPU_MEMORY_USED, 'gpu_uuid')
+
+        data = dict()
+        for gpu in utilization:
+            gpu_id = clean_name(gpu)
+            if gpu_id not in self.collected_gpus:
+                self.collected_gpus.add(gpu_id)
+                self.add_gpu_dimensions(gpu_id, gpu)
+            # 0.0 - 1.0
+            data['gpu_{0}_utilization'.format(gpu_id)] = int(utilization[gpu] * 10000)
+            data['gpu_{0}_memory_used'.format(gpu_id)] = int(used.get(gpu, 0))
+        return data
+
+    def add_model_charts(self, model_id, model):
+        order, charts = model_charts(model_id, model)
+        self.add_charts(order, charts)
+
+    def add_gpu_dimensions(self, gpu_id, gpu):
+        # GPU-8ed7d2a1-...
+        name = gpu[:12]
+        dimensions = (
+            ('gpu_utilization', ['gpu_{0}_utilization'.format(gpu_id), name, 'absolute', 1, 100]),
+            ('gpu_memory', ['gpu_{0}_memory_used'.format(gpu_id), name, 'absolute', 1, 1 << 20]),
+        )
+        for chart, dimension in dimensions:
+            self.add_dimension(chart, dimension)
@@ -0,0 +1,244 @@
+# -*- coding: utf-8 -*-
+# Description: vitess netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+from copy import deepcopy
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.collection import clean_name
+
+update_every = 10
+
+API_VARS = 'debug/vars'
+
+VTGATE = 'vtgate'
+VTTABLET = 'vttablet'
+
+VTGATE_ORDER = [
+    'vtgate_queries',
+    'vtgate_api_latency',
+    'vtgate_api_errors',
+    'vtgate_tablets',
+]
+
+VTGATE_CHARTS = {
+    'vtgate_queries': {
+        'options': [None, 'Queries By Plan Type', 'queries/s', 'queries', 'vitess.vtgate_queries', 'stacked'],
+        'lines': []
+    },
+    'vtgate_api_latency': {
+        'options': [None, 'Average API Latency By Operation', 'milliseconds', 'queries',
+                    'vitess.vtgate_api_latency', 'line'],
+        'lines': []
+    },
+    'vtgate_api_errors': {
+        'options': [None, 'API Errors By Code', 'errors/s', 'queries', 'vitess.vtgate_api_errors', 'stacked'],
+        'lines': []
+    },
+    'vtgate_tablets': {
+        'options': [None, 'Healthy Tablets By Keyspace/Shard', 'tablets', 'tablets', 'vitess.vtgate_tablets',
+                    'line'],
+        'lines': []
+    },
+}
+
+VTTABLET_ORDER = [
+    'vttablet_state',
+    'vttablet_queries',
+    'vttablet_query_latency',
+    'vttablet_transaction_pool',
+    'vttablet_transaction_pool_waits',
+    'vttablet_vreplication_lag',
+    'vttablet_vreplication_streams',
+]
+
+VTTABLET_CHARTS = {
+    'vttablet_state': {
+        'options': [None, 'Tablet State', 'status', 'tablet', 'vitess.vttablet_state', 'line'],
+        'lines': [
+            ['tablet_serving', 'serving'],
+            ['tablet_not_serving', 'not_serving'],
+        ]
+    },
+    'vttablet_queries': {
+        'options': [None, 'Queries By Plan Type', 'queries/s', 'queries', 'vitess.vttablet_queries', 'stacked'],
+        'lines': []
+    },
+    'vttablet_query_latency': {
+        'options': [None, 'Average Query Latency By Plan Type', 'milliseconds', 'queries',
+                    'vitess.vttablet_query_latency', 'line'],
+        'lines': []
+    },
+    'vttablet_transaction_pool': {
+        'options': [None, 'Transaction Pool', 'connections', 'transactions', 'vitess.vttablet_transaction_pool',
+                    'stacked'],
+        'lines': [
+            ['transaction_pool_available', 'available'],
+            ['transaction_pool_in_use', 'in_use'],
+        ]
+    },
+    'vttablet_transaction_pool_waits': {
+        'options': [None, 'Transaction Pool Waits', 'waits/s', 'transactions',
+                    'vitess.vttablet_transaction_pool_waits', 'line'],
+        'lines': [
+            ['transaction_pool_waits', 'waits', 'incremental'],
+        ]
+    },
+    'vttablet_vreplication_lag': {
+        'options': [None, 'VReplication Lag', 'seconds', 'vreplication', 'vitess.vttablet_vreplication_lag', 'line'],
+        'lines': [
+            ['vreplication_lag_max', 'max'],
+        ]
+    },
+    'vttablet_vreplication_streams': {
+        'options': [None, 'VReplication Streams', 'streams', 'vreplication', 'vitess.vttablet_vreplication_streams',
+                    'line'],
+        'lines': [
+            ['vreplication_streams', 'streams'],
+        ]
+    },
+}
+
+
+def histograms(timings):
+    """
+    :param timings: <dict> a Timings variable, {"TotalCount": .., "TotalTime": .., "Histograms": {name: histogram}}
+    :return: <dict> name -> (count, time in nanoseconds)
+    """
+    result = dict()
+    for name, histogram in ((timings or dict()).get('Histograms') or dict()).items():
+        result[name] = (histogram.get('Count', 0), histogram.get('Time', 0))
+    return result
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.url = self.configuration.get('url', 'http://127.0.0.1:15001').rstrip('/')
+        self.order = list()
+        self.definitions = dict()
+        # vtgate or vttablet, from the variables of the server
+        self.role = None
+        self.collected_dims = set()
+        # (chart, name) -> (count, time) at the previous run
+        self.timings = dict()
+
+    def check(self):
+        variables = self.get_vars()
+        if not variables:
+            self.error('no variables from {0}/{1}'.format(self.url, API_VARS))
+            return False
+
+        if 'VtgateApi' in variables:
+            self.role, self.order, self.definitions = VTGATE, list(VTGATE_ORDER), deepcopy(VTGATE_CHARTS)
+        elif 'TabletStateName' in variables:
+            self.role, self.order, self.definitions = VTTABLET, list(VTTABLET_ORDER), deepcopy(VTTABLET_CHARTS)
+        else:
+            self.error('{0} is neither a vtgate nor a vttablet'.format(self.url))
+            return False
+        self.info('{0} is a {1}'.format(self.url, self.role))
+
+        if self.role == VTTABLET:
+            # the tablet serves one shard of one keyspace
+            family = '{0}/{1} {2}'.format(
+                variables.get('TabletKeyspace', ''),
+                variables.get('TabletShard', ''),
+                str(variables.get('TabletType', '')).lower(),
+            )
+            self.definitions['vttablet_state']['options'][3] = family.strip()
+
+        return UrlService.check(self)
+
+    def get_vars(self):
+        raw = self._get_raw_data('{0}/{1}'.format(self.url, API_VARS))
+        if not raw:
+            return None
+        try:
+            return json.loads(raw)
+        except ValueError as error:
+            self.error('{0}: {1}'.format(API_VARS, error))
+            return None
+
+    def _get_data(self):
+        variables = self.get_vars()
+        if not variables:
+            return None
+        if self.role == VTGATE:
+            return self.vtgate_data(variables)
+        return self.vttablet_data(variables)
+
+    def vtgate_data(self, variables):
+        data = dict()
+        for plan, value in (variables.get('QueriesProcessed') or dict()).items():
+            data[self.dim('vtgate_queries', plan, 'incremental')] = value
+
+        # 'Operation.Keyspace.DbType', latency per operation
+        operations = dict()
+        for name, (count, time) in histograms(variables.get('VtgateApi')).items():
+            operation = name.split('.')[0]
+            totals = operations.get(operation, (0, 0))
+            operations[operation] = (totals[0] + count, totals[1] + time)
+        data.update(self.latency_data('vtgate_api_latency', operations))
+
+        # 'Operation.Keyspace.DbType.Code'
+        errors = dict()
+        for name, value in (variables.get('VtgateApiErrorCounts') or dict()).items():
+            code = name.split('.')[-1]
+            errors[code] = errors.get(code, 0) + value
+        for code, value in errors.items():
+            data[self.dim('vtgate_api_errors', code, 'incremental')] = value
+
+        # 'Keyspace.Shard.TabletType'
+        for name, value in (variables.get('HealthcheckConnections') or dict()).items():
+            data[self.dim('vtgate_tablets', name.replace('.', '/', 1).replace('.', ' ', 1))] = value
+
+        return data
+
+    def vttablet_data(self, variables):
+        serving = variables.get('TabletStateName') == 'SERVING'
+        data = {
+            'tablet_serving': int(serving),
+            'tablet_not_serving': int(not serving),
+        }
+
+        plans = histograms(variables.get('Queries'))
+        for plan, (count, _) in plans.items():
+            data[self.dim('vttablet_queries', plan, 'incremental')] = count
+        data.update(self.latency_data('vttablet_query_latency', plans))
+
+        if 'TransactionPoolCapacity' in variables:
+            in_use = variables.get('TransactionPoolInUse', variables.get('TransactionPoolActive', 0))
+            data['transaction_pool_in_use'] = in_use
+            data['transaction_pool_available'] = variables.get(
+                'TransactionPoolAvailable', variables['TransactionPoolCapacity'] - in_use)
+            data['transaction_pool_waits'] = variables.get('TransactionPoolWaitCount', 0)
+
+        # one lag per stream, 'VReplicationLagSeconds' is not set without streams
+        lags = (variables.get('VReplicationLagSeconds') or dict()).values()
+        data['vreplication_lag_max'] = max(lags) if lags else 0
+        data['vreplication_streams'] = variables.get('VReplicationStreamCount', len(lags))
+
+        return data
+
+    def latency_data(self, chart, timings):
+        # the average of the calls since the previous run
+        data = dict()
+        for name, (count, time) in timings.items():
+            dim_id = self.dim(chart, name, 'absolute', 1000000)
+            previous = self.timings.get((chart, name))
+            self.timings[(chart, name)] = (count, time)
+            if not previous or count <= previous[0]:
+                data[dim_id] = 0
+                continue
+            # nanoseconds, the dimension divisor makes them milliseconds
+            data[dim_id] = int((time - previous[1]) / (count - previous[0]))
+        return data
+
+    def dim(self, chart, name, algorithm='absolute', divisor=1):
+        dim_id = '{0}_{1}'.format(chart, clean_name(name))
+        if dim_id not in self.collected_dims:
+            self.collected_dims.add(dim_id)
+            dimension = [dim_id, name, algorithm, 1, divisor]
+            self.add_dimension(chart, dimension)
+        return dim_id
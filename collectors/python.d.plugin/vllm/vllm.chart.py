@@ -0,0 +1,177 @@
+# -*- coding: utf-8 -*-
+# Description: vllm netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+from bases.FrameworkServices.UrlService import UrlService
+from bases.prometheus import parse, sum_by
+
+update_every = 5
+
+METRIC_RUNNING = 'vllm:num_requests_running'
+METRIC_WAITING = 'vllm:num_requests_waiting'
+METRIC_SWAPPED = 'vllm:num_requests_swapped'
+METRIC_SUCCESS = 'vllm:request_success_total'
+METRIC_PROMPT_TOKENS = 'vllm:prompt_tokens_total'
+METRIC_GENERATION_TOKENS = 'vllm:generation_tokens_total'
+METRIC_PREEMPTIONS = 'vllm:num_preemptions_total'
+# renamed to kv_cache_usage_perc by the v1 engine, 0.0 - 1.0
+METRIC_GPU_CACHE = 'vllm:gpu_cache_usage_perc'
+METRIC_KV_CACHE = 'vllm:kv_cache_usage_perc'
+METRIC_CPU_CACHE = 'vllm:cpu_cache_usage_perc'
+# histogram of the tokens scheduled per engine step, prompt and generation
+METRIC_ITERATION_TOKENS = 'vllm:iteration_tokens_total'
+
+# histograms, in seconds
+LATENCIES = (
+    ('vllm:request_queue_time_seconds', 'queue'),
+    ('vllm:time_to_first_token_seconds', 'time_to_first_token'),
+    ('vllm:time_per_output_token_seconds', 'time_per_output_token'),
+    ('vllm:e2e_request_latency_seconds', 'e2e'),
+)
+
+FINISHED_REASONS = (
+    'stop',
+    'length',
+    'abort',
+)
+
+METRICS = (
+    METRIC_RUNNING,
+    METRIC_WAITING,
+    METRIC_SWAPPED,
+    METRIC_SUCCESS,
+    METRIC_PROMPT_TOKENS,
+    METRIC_GENERATION_TOKENS,
+    METRIC_PREEMPTIONS,
+    METRIC_GPU_CACHE,
+    METRIC_KV_CACHE,
+    METRIC_CPU_CACHE,
+) + tuple(m + suffix for m in (METRIC_ITERATION_TOKENS,) + tuple(m for m, _ in LATENCIES)
+        for suffix in ('_sum', '_count'))
+
+ORDER = [
+    'requests',
+    'finished_requests',
+    'tokens',
+    'batch_size',
+    'kv_cache',
+    'latency',
+    'preemptions',
+]
+
+CHARTS = {
+    'requests': {
+        'options': [None, 'Requests', 'requests', 'requests', 'vllm.requests', 'stacked'],
+        'lines': [
+            ['requests_running', 'running'],
+            ['requests_waiting', 'waiting'],
+            ['requests_swapped', 'swapped'],
+        ]
+    },
+    'finished_requests': {
+        'options': [None, 'Finished Requests', 'requests/s', 'requests', 'vllm.finished_requests', 'stacked'],
+        'lines': [['finished_' + r, r, 'incremental'] for r in FINISHED_REASONS]
+    },
+    'tokens': {
+        'options': [None, 'Token Throughput', 'tokens/s', 'tokens', 'vllm.tokens', 'line'],
+        'lines': [
+            ['tokens_prompt', 'prompt', 'incremental'],
+            ['tokens_generation', 'generation', 'incremental'],
+        ]
+    },
+    'batch_size': {
+        'options': [None, 'Average Batch Size', 'tokens', 'tokens', 'vllm.batch_size', 'line'],
+        'lines': [
+            ['batch_size', 'tokens', 'absolute', 1, 100],
+        ]
+    },
+    'kv_cache': {
+        'options': [None, 'KV Cache Usage', 'percentage', 'cache', 'vllm.kv_cache', 'line'],
+        'lines': [
+            ['kv_cache_gpu', 'gpu', 'absolute', 1, 100],
+            ['kv_cache_cpu', 'cpu', 'absolute', 1, 100],
+        ]
+    },
+    'latency': {
+        'options': [None, 'Requests Average Latency', 'milliseconds', 'latency', 'vllm.latency', 'line'],
+        'lines': [['latency_' + l, l, 'absolute', 1, 1000] for _, l in LATENCIES]
+    },
+    'preemptions': {
+        'options': [None, 'Preemptions', 'preemptions/s', 'requests', 'vllm.preemptions', 'line'],
+        'lines': [
+            ['preemptions', 'preemptions', 'incremental'],
+        ]
+    },
+}
+
+
+class Service(UrlService):
+    def __init__(self, configuration=None, name=None):
+        UrlService.__init__(self, configuration=configuration, name=name)
+        self.order = ORDER
+        self.definitions = CHARTS
+        self.url = self.configuration.get('url', 'http://127.0.0.1:8000/metrics')
+        self.previous = dict()
+
+    def _get_data(self):
+        raw = self._get_raw_data()
+        if not raw:
+            return None
+
+        samples = parse(raw, names=METRICS)
+        if not samples:
+            self.error('no vllm metrics found at {0}'.format(self.url))
+            return None
+
+        # every series is labeled with the model_name, a server serves a single model
+        data = dict()
+        for key, metric in (('requests_running', METRIC_RUNNING),
+                            ('requests_waiting', METRIC_WAITING),
+                            ('requests_swapped', METRIC_SWAPPED),
+                            ('tokens_prompt', METRIC_PROMPT_TOKENS),
+                            ('tokens_generation', METRIC_GENERATION_TOKENS),
+                            ('preemptions', METRIC_PREEMPTIONS)):
+            values = sum_by(samples, metric)
+            if values:
+                data[key] = int(values[None])
+
+        finished = sum_by(samples, METRIC_SUCCESS, 'finished_reason')
+        if finished:
+            for reason in FINISHED_REASONS:
+                data['finished_' + reason] = int(finished.get(reason, 0))
+
+        for key, metrics in (('kv_cache_gpu', (METRIC_KV_CACHE, METRIC_GPU_CACHE)),
+                             ('kv_cache_cpu', (METRIC_CPU_CACHE,))):
+            for metric in metrics:
+                values = sum_by(samples, metric)
+                if values:
+                    data[key] = int(values[None] * 10000)
+                    break
+
+        average = self.delta_average(samples, METRIC_ITERATION_TOKENS, 100)
+        if average is not None:
+            data['batch_size'] = average
+
+        for metric, latency in LATENCIES:
+            # microseconds
+            average = self.delta_average(samples, metric, 1e6)
+            if average is not None:
+                data['latency_' + latency] = average
+
+        return data
+
+    def delta_average(self, samples, histogram, mult):
+        """
+        Average of the histogram observations since the last run, 0 when there are none.
+        :return: None if the histogram is not exported
+        """
+        counts = sum_by(samples, histogram + '_count')
+        if not counts:
+            return None
+        total, count = sum_by(samples, histogram + '_sum').get(None, 0), counts[None]
+
+        prev_total, prev_count = self.previous.get(histogram, (total, count))
+        self.previous[histogram] = (total, count)
+        if count > prev_count:
+            return int((total - prev_total) * mult / (count - prev_count))
+        return 0
@@ -0,0 +1,248 @@
+# -*- coding: utf-8 -*-
+# Description: zfspool netdata python.d module
+# SPDX-License-Identifier: GPL-3.0-or-later
+
+import json
+from copy import deepcopy
+
+from bases.FrameworkServices.ExecutableService import ExecutableService
+from bases.collection import clean_name, find_binary
+
+update_every = 10
+
+ZPOOL = 'zpool'
+
+# -p: exact numbers, the fragmentation is a percentage, '-' when unknown
+LIST_ARGS = ['list', '-H', '-p', '-o', 'name,allocated,free,fragmentation,health']
+# OpenZFS 2.3 or newer
+STATUS_ARGS = ['status', '-j', '--json-int', '-p']
+
+STATES = (
+    'online',
+    'degraded',
+    'faulted',
+    'offline',
+    'unavail',
+    'removed',
+    'suspended',
+)
+
+ORDER = [
+    'pools',
+]
+
+CHARTS = {
+    'pools': {
+        'options': [None, 'Pools By Health', 'pools', 'overview', 'zfspool.pools', 'stacked'],
+        'lines': [['pools_' + s, s] for s in STATES]
+    },
+}
+
+
+def pool_charts(pool_id, pool, status):
+    order = [
+        'pool_{0}_space'.format(pool_id),
+        'pool_{0}_fragmentation'.format(pool_id),
+        'pool_{0}_health'.format(pool_id),
+    ]
+    family = 'pool ' + pool
+    charts = {
+        order[0]: {
+            'options': [None, 'Pool Space', 'GiB', family, 'zfspool.pool_space', 'stacked'],
+            'lines': [
+                ['pool_{0}_free'.format(pool_id), 'free', 'absolute', 1, 1 << 30],
+                ['pool_{0}_allocated'.format(pool_id), 'used', 'absolute', 1, 1 << 30],
+            ]
+        },
+        order[1]: {
+            'options': [None, 'Pool Fragmentation', 'percentage', family, 'zfspool.pool_fragmentation', 'line'],
+            'lines': [
+                ['pool_{0}_fragmentation'.format(pool_id), 'fragmentation'],
+            ]
+        },
+        order[2]: {
+            'options': [None, 'Pool Health', 'status', family, 'zfspool.pool_health', 'line'],
+            'lines': [['pool_{0}_{1}'.format(pool_id, s), s] for s in STATES]
+        },
+    }
+
+    if status:
+        order.extend([
+            'pool_{0}_vdevs'.format(pool_id),
+            'pool_{0}_scrub'.format(pool_id),
+        ])
+        charts[order[3]] = {
+            'options': [None, 'Devices By State', 'devices', family, 'zfspool.pool_vdevs', 'stacked'],
+            'lines': [['pool_{0}_vdevs_{1}'.format(pool_id, s), s] for s in STATES]
+        }
+        charts[order[4]] = {
+            'options': [None, 'Scrub Progress', 'percentage', family, 'zfspool.pool_scrub', 'line'],
+            'lines': [
+                ['pool_{0}_scrub_progress'.format(pool_id), 'progress', 'absolute', 1, 100],
+            ]
+        }
+
+    return order, charts
+
+
+def vdev_charts(vdev_id, pool, vdev):
+    order = [
+        'vdev_{0}_errors'.format(vdev_id),
+    ]
+    charts = {
+        order[0]: {
+            'options': [None, 'Device {0} Errors'.format(vdev), 'errors', 'pool ' + pool, 'zfspool.vdev_errors',
+                        'line'],
+            'lines': [
+                ['vdev_{0}_read_errors'.format(vdev_id), 'read'],
+                ['vdev_{0}_write_errors'.format(vdev_id), 'write'],
+                ['vdev_{0}_checksum_errors'.format(vdev_id), 'checksum'],
+            ]
+        },
+    }
+    return order, charts
+
+
+def walk_vdevs(vdev, path=None):
+    """
+    Yield (path, vdev) of the devices of a pool, the root vdev is the pool itself.
+    """
+    for name, child in (vdev.get('vdevs') or dict()).items():
+        child_path = '{0}/{1}'.format(path, name) if path else name
+        yield child_path, child
+        for item in walk_vdevs(child, child_path):
+            yield item
+
+
+class Service(ExecutableService):
+    def __init__(self, configuration=None, name=None):
+        ExecutableService.__init__(self, configuration=configuration, name=name)
+        self.order = list(ORDER)
+        self.definitions = deepcopy(CHARTS)
+        self.zpool = self.configuration.get('zpool_path')
+        self.do_vdevs = self.configuration.get('vdev_charts', True)
+        # the json output of 'zpool status' needs OpenZFS 2.3
+        self.status_json = False
+        # pool or vdev id -> <list> of its charts
+        self.collected = dict()
+
+    def check(self):
+        self.zpool = self.zpool or find_binary(ZPOOL)
+        if not self.zpool:
+            self.error('can\'t locate "{0}" binary'.format(ZPOOL))
+            return False
+
+        self.status_json = self.zpool_status() is not None
+        if not self.status_json:
+            self.info('"{0} status -j" is not supported, it needs OpenZFS 2.3 or newer, '
+                      'the devices are not charted'.format(self.zpool))
+
+        if not self._get_data():
+            self.error('no pools found by "{0} list"'.format(self.zpool))
+            return False
+        return True
+
+    def _get_data(self):
+        raw = self._get_raw_data(command=[self.zpool] + LIST_ARGS)
+        if not raw:
+            return None
+
+        data = dict(('pools_' + s, 0) for s in STATES)
+        seen = set()
+        for line in raw:
+            fields = line.rstrip('\n').split('\t')
+            if len(fields) != 5:
+                continue
+            pool, allocated, free, fragmentation, health = fields
+            health = health.lower()
+            pool_id = clean_name(pool)
+
+            seen.add('pool_' + pool_id)
+            if 'pool_' + pool_id not in self.collected:
+                self.add_collected_charts('pool_' + pool_id, *pool_charts(pool_id, pool, self.status_json))
+
+            prefix = 'pool_{0}_'.format(pool_id)
+            if health in STATES:
+                data['pools_' + health] += 1
+            for s in STATES:
+                data[prefix + s] = int(health == s)
+            for key, value in (('allocated', allocated), ('free', free), ('fragmentation', fragmentation)):
+                if value.isdigit():
+                    data[prefix + key] = int(value)
+
+        if self.status_json:
+            status = self.zpool_status()
+            if status:
+                data.update(self.status_data(status, seen))
+            else:
+                # keep the device charts of the pools until the next successful run
+                seen.update(c for c in self.collected if c.startswith('vdev_'))
+
+        for chart_id in set(self.collected) - seen:
+            self.remove_collected_charts(chart_id)
+
+        return data
+
+    def zpool_status(self):
+        raw = self._get_raw_data(command=[self.zpool] + STATUS_ARGS)
+        if not raw:
+            return None
+        try:
+            return json.loads(''.join(raw))
+        except ValueError as error:
+            self.debug('"{0} status -j": {1}'.format(self.zpool, error))
+            return None
+
+    def status_data(self, status, seen):
+        data = dict()
+        for pool, info in (status.get('pools') or dict()).items():
+            pool_id = clean_name(pool)
+            prefix = 'pool_{0}_'.format(pool_id)
+
+            vdevs = dict(('vdevs_' + s, 0) for s in STATES)
+            root = (info.get('vdevs') or dict()).get(pool) or dict()
+            for path, vdev in walk_vdevs(root):
+                state = str(vdev.get('state', '')).lower()
+                if state in STATES:
+                    vdevs['vdevs_' + state] += 1
+
+                if not self.do_vdevs:
+                    continue
+                vdev_id = '{0}_{1}'.format(pool_id, clean_name(path))
+                seen.add('vdev_' + vdev_id)
+                if 'vdev_' + vdev_id not in self.collected:
+                    self.add_collected_charts('vdev_' + vdev_id, *vdev_charts(vdev_id, pool, path))
+                for key in ('read_errors', 'write_errors', 'checksum_errors'):
+                    data['vdev_{0}_{1}'.format(vdev_id, key)] = int(vdev.get(key, 0))
+
+            for key, value in vdevs.items():
+                data[prefix + key] = value
+            data[prefix + 'scrub_progress'] = scrub_progress(info.get('scan_stats') or dict())
+
+        return data
+
+    def add_collected_charts(self, chart_id, order, charts):
+        self.collected[chart_id] = order
+        self.add_charts(order, charts)
+
+    def remove_collected_charts(self, chart_id):
+        # exported or destroyed pools, and replaced devices
+        self.remove_charts(self.collected.pop(chart_id))
+
+
+def scrub_progress(scan):
+    """
+    :return: <int> percentage * 100 of the running scrub, 100% once finished, 0 before the first one
+    """
+    if str(scan.get('function', '')).upper() != 'SCRUB':
+        return 0
+    state = str(scan.get('state', '')).upper()
+    if state == 'FINISHED':
+        return 10000
+    if state != 'SCANNING':
+        return 0
+    # the issued bytes are the ones actually verified, 'zpool status' shows them as done
+    to_examine, issued = int(scan.get('to_examine', 0)), int(scan.get('issued', scan.get('examined', 0)))
+    if not to_examine:
+        return 0
+    return min(int(issued * 10000 / to_examine), 10000)
@@ -217,7 +217,8 @@ netdataDashboard.menu = {
     'zfspool': {
         title: 'ZFS pools',
         icon: '<i class="fas fa-database"></i>',
-        info: 'State of ZFS pools.'
+        info: 'State of ZFS pools. The python.d <code>zfspool</code> module adds the space, fragmentation, '+
+        'devices errors and scrub progress of the pools, as reported by <code>zpool</code>.'
     },
 
     'btrfs': {
@@ -495,6 +496,12 @@ netdataDashboard.menu = {
         info: 'Provides statistics on the <b><a href="http://ceph.com/" target="_blank">ceph</a></b> cluster server, the open-source distributed storage system.'
     },
 
+    'minio': {
+        title: 'MinIO',
+        icon: '<i class="fas fa-database"></i>',
+        info: 'Drives, nodes, healing and S3 requests of a <b><a href="https://min.io/" target="_blank">MinIO</a></b> cluster, read from the v3 metrics of any of its nodes. An erasure set without read or write quorum cannot serve its objects. The bucket usage is updated by the data scanner, it lags behind the writes.'
+    },
+
     'ntpd': {
         title: 'ntpd',
         icon: '<i class="fas fa-clock"></i>',
@@ -537,6 +544,18 @@ netdataDashboard.menu = {
         info: 'Statistics for the various system power supplies. Data collected from <a href="https://www.kernel.org/doc/Documentation/power/power_supply_class.txt" target="_blank">Linux power supply class</a>.'
     },
 
+    'thermal': {
+        title: 'Thermal',
+        icon: '<i class="fas fa-thermometer-half"></i>',
+        info: 'Temperature of the thermal zones and state of the cooling devices. Data collected from the <a href="https://www.kernel.org/doc/html/latest/driver-api/thermal/sysfs-api.html" target="_blank">Linux thermal framework</a>.'
+    },
+
+    'powercap': {
+        title: 'Powercap',
+        icon: '<i class="fas fa-bolt"></i>',
+        info: 'Energy consumption of the CPU packages and their domains. Data collected from the <a href="https://www.kernel.org/doc/html/latest/power/powercap/powercap.html" target="_blank">Linux powercap framework</a> (Intel RAPL).'
+    },
+
     'xenstat': {
         title: 'Xen Node',
         icon: '<i class="fas fa-server"></i>',
@@ -699,6 +718,212 @@ netdataDashboard.menu = {
         icon: '<i class="fas fa-dragon"></i>',
         info: 'VPN network interfaces and peers traffic.'
     },
+
+    'adb': {
+        title: 'Android Devices',
+        icon: '<i class="fas fa-mobile-alt"></i>',
+        info: 'Battery, storage and uptime of Android devices attached to this host over the <b><a href="https://developer.android.com/studio/command-line/adb" target="_blank">Android Debug Bridge</a></b>.'
+    },
+    'rpi': {
+        title: 'Raspberry Pi',
+        icon: '<i class="fas fa-microchip"></i>',
+        info: 'SoC temperature, throttling state, voltages and clocks of <b><a href="https://www.raspberrypi.com/" target="_blank">Raspberry Pi</a></b> and other single board computers.'
+    },
+    'smartctl': {
+        title: 'S.M.A.R.T.',
+        icon: '<i class="fas fa-hdd"></i>',
+        info: 'Health self-assessment, temperature and error counters of the disks of this host, read with <b><a href="https://www.smartmontools.org/" target="_blank">smartctl</a></b>. Reallocated and pending sectors growing over time, NVMe media errors and a high NVMe percentage used are early signs of a failing or worn out device. Disks in standby are not woken up, their charts have gaps until they spin up again.'
+    },
+    'gpu': {
+        title: 'GPUs',
+        icon: '<i class="fas fa-microchip"></i>',
+        info: 'Utilization, memory, temperature, power draw, clocks and ECC errors of the NVIDIA GPUs of this host, read from <b><a href="https://github.com/NVIDIA/dcgm-exporter" target="_blank">dcgm-exporter</a></b> or <b>nvidia-smi</b>. Uncorrected ECC errors mean the GPU memory is failing, the affected applications are killed.'
+    },
+    'cri': {
+        title: 'CRI Containers',
+        icon: '<i class="fas fa-cube"></i>',
+        info: 'Pod sandboxes and containers of the <b><a href="https://kubernetes.io/docs/concepts/architecture/cri/" target="_blank">CRI</a></b> runtime of this node, containerd or CRI-O, read with <b>crictl</b>. The per container charts are named after the pod namespace, the pod and the container.'
+    },
+    'cilium': {
+        title: 'Cilium',
+        icon: '<i class="fas fa-network-wired"></i>',
+        info: 'Endpoints, BPF maps, datapath drops and Hubble flows of the <b><a href="https://cilium.io/" target="_blank">Cilium</a></b> agent of this node. A BPF map close to 100% pressure can not take new entries, a full policy map makes the agent fail the regeneration of its endpoint.'
+    },
+    'caddy': {
+        title: 'Caddy',
+        icon: '<i class="fas fa-server"></i>',
+        info: 'Requests and responses of the servers, reverse proxy upstreams and config reloads of the <b><a href="https://caddyserver.com/" target="_blank">Caddy</a></b> web server, read from its admin endpoint. The per server charts need the <code>metrics</code> global option in Caddy 2.8 and newer.'
+    },
+    'vitess': {
+        title: 'Vitess',
+        icon: '<i class="fas fa-database"></i>',
+        info: 'Queries, latencies and errors of the <b><a href="https://vitess.io/" target="_blank">Vitess</a></b> vtgate query routers, and state, transaction pool and VReplication lag of the vttablets, read from their <code>/debug/vars</code> endpoint.'
+    },
+    'clickhouse_keeper': {
+        title: 'ClickHouse Keeper',
+        icon: '<i class="fas fa-database"></i>',
+        info: 'Requests, latency, znodes, watches and role of a <b><a href="https://clickhouse.com/docs/en/guides/sre/keeper/clickhouse-keeper" target="_blank">ClickHouse Keeper</a></b> server, read with the <code>mntr</code> four letter word command. Every ensemble needs one leader, the outstanding requests pile up when the server can not keep up with its clients.'
+    },
+    'pgpooler': {
+        title: 'PostgreSQL Poolers',
+        icon: '<i class="fas fa-database"></i>',
+        info: 'Pools and databases of the <b><a href="https://github.com/postgresml/pgcat" target="_blank">PgCat</a></b> and <b><a href="https://github.com/yandex/odyssey" target="_blank">Odyssey</a></b> PostgreSQL connection poolers, read from their admin console. Waiting clients on a saturated pool wait for a server connection, their queries are delayed.'
+    },
+    'temporal': {
+        title: 'Temporal',
+        icon: '<i class="fas fa-tasks"></i>',
+        info: 'Persistence requests, shard locks and per namespace workflow task latency and task queues backlog of the <b><a href="https://temporal.io/" target="_blank">Temporal</a></b> server, read from its prometheus metrics. A growing backlog with a rising schedule to start latency means the workers can not keep up with the tasks.'
+    },
+    'grpccheck': {
+        title: 'gRPC Health Checks',
+        icon: '<i class="fas fa-heartbeat"></i>',
+        info: 'Serving status and latency of the <b><a href="https://github.com/grpc/grpc/blob/master/doc/health-checking.md" target="_blank">gRPC health checks</a></b> of a server or one of its services. <code>failed</code> means the health check got no answer: the server is down, does not answer in time or has no health service.'
+    },
+    'jsonquery': {
+        title: 'JSON Endpoints',
+        icon: '<i class="fas fa-code"></i>',
+        info: 'Values of JSON endpoints, selected with JSONPath expressions on the charts defined in the <code>python.d/jsonquery.conf</code> configuration file.'
+    },
+    'sqlquery': {
+        title: 'SQL Queries',
+        icon: '<i class="fas fa-database"></i>',
+        info: 'Results of the SQL queries to PostgreSQL, MySQL, SQLite and Microsoft SQL Server databases defined in the <code>python.d/sqlquery.conf</code> configuration file.'
+    },
+    'hazelcast': {
+        title: 'Hazelcast',
+        icon: '<i class="fas fa-th"></i>',
+        info: 'Cluster membership and partition migrations of <b><a href="https://hazelcast.com/" target="_blank">Hazelcast</a></b> in-memory data grid members.'
+    },
+    'infinispan': {
+        title: 'Infinispan',
+        icon: '<i class="fas fa-th"></i>',
+        info: 'Cluster health and per cache statistics of <b><a href="https://infinispan.org/" target="_blank">Infinispan</a></b> in-memory data grid servers.'
+    },
+    'haproxy_dataplane': {
+        title: 'HAProxy Data Plane',
+        icon: '<i class="fas fa-network-wired"></i>',
+        info: 'Runtime state of <b><a href="https://www.haproxy.org/" target="_blank">HAProxy</a></b> collected through the Data Plane API: reloads, worker processes draining after a hitless reload, stick tables, runtime maps and ACLs.'
+    },
+    'listeners': {
+        title: 'Listening Sockets',
+        icon: '<i class="fas fa-door-open"></i>',
+        info: 'TCP and UDP sockets listening on this host, per owning process. New listeners are reported on the <b>changes</b> chart and logged with their address and process.'
+    },
+    'activemq_artemis': {
+        title: 'ActiveMQ Artemis',
+        icon: '<i class="fas fa-exchange-alt"></i>',
+        info: 'Connections, messages and address memory of an <b><a href="https://activemq.apache.org/components/artemis/" target="_blank">Apache ActiveMQ Artemis</a></b> broker, and messages, consumers and message rates per address and queue, read through the Jolokia endpoint of the management console. When the address memory reaches the global max size, the addresses page, block or drop messages, depending on their address full policy.'
+    },
+    'benthos': {
+        title: 'Benthos',
+        icon: '<i class="fas fa-stream"></i>',
+        info: 'Message rates, errors and connection status of <b><a href="https://www.benthos.dev/" target="_blank">Benthos</a></b> and Redpanda Connect pipelines. Every chart family is a stream; outputs retrying failed writes count every failed attempt as an output error.'
+    },
+    'celery': {
+        title: 'Celery',
+        icon: '<i class="fas fa-tasks"></i>',
+        info: 'Queue lengths, workers and task rates and latencies of <b><a href="https://docs.celeryq.dev/" target="_blank">Celery</a></b> task queues, read from the Redis broker and the Celery events. Tasks are attributed to their queue only when the clients send <code>task-sent</code> events.'
+    },
+    'debezium': {
+        title: 'Debezium',
+        icon: '<i class="fas fa-stream"></i>',
+        info: 'Snapshot progress, streaming lag behind the source database and event rates of <b><a href="https://debezium.io/" target="_blank">Debezium</a></b> change data capture connectors, read through Jolokia.'
+    },
+    'emqx': {
+        title: 'EMQX',
+        icon: '<i class="fas fa-exchange-alt"></i>',
+        info: 'Connections, subscriptions and message rates of an <b><a href="https://www.emqx.io/" target="_blank">EMQX</a></b> MQTT cluster, summed over its nodes. Routing drops are messages published to topics without subscribers, delivery drops are messages that could not be queued for a subscriber.'
+    },
+    'gridengine': {
+        title: 'Grid Engine',
+        icon: '<i class="fas fa-server"></i>',
+        info: 'Slots of every cluster queue and jobs by state of a Grid Engine cluster, read with <code>qstat</code>. Every task of an array job is counted as a job.'
+    },
+    'htcondor': {
+        title: 'HTCondor',
+        icon: '<i class="fas fa-server"></i>',
+        info: 'Slots, jobs and negotiation cycles of an <b><a href="https://htcondor.org/" target="_blank">HTCondor</a></b> pool, read with <code>condor_status</code> and <code>condor_q</code>.'
+    },
+    'jupyterhub': {
+        title: 'JupyterHub',
+        icon: '<i class="fas fa-book"></i>',
+        info: 'Users, notebook servers and their spawns, and the requests and proxy route operations of a <b><a href="https://jupyter.org/hub" target="_blank">JupyterHub</a></b>. The requests to the notebook servers go through the proxy directly and are not counted by the hub.'
+    },
+    'kafka': {
+        title: 'Kafka',
+        icon: '<i class="fas fa-stream"></i>',
+        info: 'Partitions health, request rates and latency, and traffic per topic of an <b><a href="https://kafka.apache.org/" target="_blank">Apache Kafka</a></b> broker, read through Jolokia. The under-replicated partitions are the partitions led by the broker.'
+    },
+    'kafka_connect': {
+        title: 'Kafka Connect',
+        icon: '<i class="fas fa-plug"></i>',
+        info: 'Connectors and tasks of a <b><a href="https://kafka.apache.org/documentation/#connect" target="_blank">Kafka Connect</a></b> cluster, by state. A task is reassigned when it moves to another worker during a rebalance.'
+    },
+    'mlflow': {
+        title: 'MLflow',
+        icon: '<i class="fas fa-flask"></i>',
+        info: 'Health, active experiments and runs of an <b><a href="https://mlflow.org/" target="_blank">MLflow</a></b> tracking server. The request charts are available when the server runs with <code>--expose-prometheus</code>.'
+    },
+    'mosquitto': {
+        title: 'Mosquitto',
+        icon: '<i class="fas fa-exchange-alt"></i>',
+        info: 'Clients, messages and traffic of an <b><a href="https://mosquitto.org/" target="_blank">Eclipse Mosquitto</a></b> MQTT broker, read from its <code>$SYS</code> topics. The broker updates them every <code>sys_interval</code> seconds, so changes show up with that delay. Dropped messages are messages the broker could not queue for a client, usually because its queue was full.'
+    },
+    'nats': {
+        title: 'NATS',
+        icon: '<i class="fas fa-exchange-alt"></i>',
+        info: 'Statistics of every server of a <b><a href="https://nats.io/" target="_blank">NATS</a></b> cluster or supercluster, gathered through a single connection to the system account.'
+    },
+    'nsq': {
+        title: 'NSQ',
+        icon: '<i class="fas fa-exchange-alt"></i>',
+        info: 'Messages published and queued per topic, and pending, in flight, requeued and timed out messages per channel of an <b><a href="https://nsq.io/" target="_blank">NSQ</a></b> daemon. Queued messages exceeding the memory queue size are kept on disk.'
+    },
+    'ollama': {
+        title: 'Ollama',
+        icon: '<i class="fas fa-brain"></i>',
+        info: 'Loaded models and their memory of an <b><a href="https://ollama.com/" target="_blank">Ollama</a></b> server. Models not fully offloaded to the GPUs use system memory and run much slower. The probe charts show the results of a short generate request, run periodically when <code>probe_model</code> is configured.'
+    },
+    'ray': {
+        title: 'Ray',
+        icon: '<i class="fas fa-project-diagram"></i>',
+        info: 'Nodes, tasks and actors by state, object store memory and GPU usage of a <b><a href="https://www.ray.io/" target="_blank">Ray</a></b> cluster, read from the dashboard of the head node. Finished and failed tasks are the ones still retained by the state API, not a total.'
+    },
+    'redis_queues': {
+        title: 'Redis Queues',
+        icon: '<i class="fas fa-tasks"></i>',
+        info: 'Length, age of the oldest waiting job and job rates of the <b><a href="https://sidekiq.org/" target="_blank">Sidekiq</a></b>, <b><a href="https://github.com/resque/resque" target="_blank">Resque</a></b> or <b><a href="https://docs.bullmq.io/" target="_blank">BullMQ</a></b> queues kept in Redis. Every chart family is a queue.'
+    },
+    'redis_sentinel': {
+        title: 'Redis Sentinel',
+        icon: '<i class="fas fa-database"></i>',
+        info: 'Masters monitored by a <b><a href="https://redis.io/docs/management/sentinel/" target="_blank">Redis Sentinel</a></b>, with the replicas and sentinels known for each of them, quorum status, failovers and the master link state reported for the replicas.'
+    },
+    'redpanda': {
+        title: 'Redpanda',
+        icon: '<i class="fas fa-stream"></i>',
+        info: 'Cluster health, partition leadership, Kafka API latency, raft recovery and data disk space of <b><a href="https://redpanda.com/" target="_blank">Redpanda</a></b> brokers, read from their admin API.'
+    },
+    'slurm': {
+        title: 'Slurm',
+        icon: '<i class="fas fa-server"></i>',
+        info: 'Nodes, CPUs and jobs of a <b><a href="https://slurm.schedmd.com/" target="_blank">Slurm</a></b> cluster, read with <code>sinfo</code> and <code>squeue</code>. The wait time is the time since submission of the jobs still pending.'
+    },
+    'triton': {
+        title: 'Triton',
+        icon: '<i class="fas fa-brain"></i>',
+        info: 'Inference requests, batch sizes and latency breakdown per model, and GPU usage of the <b><a href="https://developer.nvidia.com/triton-inference-server" target="_blank">NVIDIA Triton Inference Server</a></b>. The average batch size is the number of inferences per model execution.'
+    },
+    'tcp_destinations': {
+        title: 'TCP Destinations',
+        icon: '<i class="fas fa-route"></i>',
+        info: 'Outbound TCP connections, retransmissions and stalled connects per destination, as reported by <code>ss</code>. Every chart family is a destination subnet/port selector, or a remote port when no selectors are configured.'
+    },
+    'vllm': {
+        title: 'vLLM',
+        icon: '<i class="fas fa-brain"></i>',
+        info: 'Requests, token throughput, batch size, KV cache usage and request latencies of a <b><a href="https://docs.vllm.ai/" target="_blank">vLLM</a></b> model server. Requests are preempted, and recomputed later, when the KV cache runs out of space.'
+    },
 };
 
 
@@ -935,6 +1160,11 @@ netdataDashboard.submenu = {
         info: 'Statistics of the system variables as shown by the readlist billboard <code>ntpq -c rl</code>. System variables are assigned an association ID of zero and can also be shown in the readvar billboard <code>ntpq -c "rv 0"</code>. These variables are used in the <a href="http://doc.ntp.org/current-stable/discipline.html" target="_blank">Clock Discipline Algorithm</a>, to calculate the lowest and most stable offset.'
     },
 
+    'ntpd.server': {
+        title: 'server',
+        info: 'Statistics of the packets served to NTP clients, as shown by <code>ntpq -c sysstats</code> and <code>ntpq -c monstats</code>. Enabled with <code>show_server: yes</code> in the module configuration.'
+    },
+
     'ntpd.peers': {
         title: 'peers',
         info: 'Statistics of the peer variables for each peer configured in <code>/etc/ntp.conf</code> as shown by the readvar billboard <code>ntpq -c "rv &lt;association&gt;"</code>, while each peer is assigned a nonzero association ID as shown by <code>ntpq -c "apeers"</code>. The module periodically scans for new/changed peers (default: every 60s). <b>ntpd</b> selects the best possible peer from the available peers to synchronize the clock. A minimum of at least 3 peers is required to properly identify the best possible peer.'
@@ -1668,6 +1898,10 @@ netdataDashboard.context = {
         info: 'The percentage of time spent in C-states.'
     },
 
+    'cpufreq.cpu_pstate_residency_time': {
+        info: 'The percentage of time spent in each CPU frequency (P-state), from the <code>cpufreq</code> statistics.'
+    },
+
     // ------------------------------------------------------------------------
     // MEMORY
 
@@ -1783,6 +2017,18 @@ netdataDashboard.context = {
         'As migration is a copying operation, it contributes the largest part of the overhead created by NUMA balancing.</p>'
     },
 
+    'mem.numa_node_memory': {
+        info: 'Memory usage of the NUMA node. An imbalance between nodes causes allocations to spill over to remote nodes, increasing memory access latency.'
+    },
+
+    'mem.numa_node_locality': {
+        info: 'The percentage of the pages allocated from this node to processes running on the node itself (<b>local</b>) or on another node (<b>remote</b>). A high remote percentage means processes access memory across the interconnect.'
+    },
+
+    'mem.numa_node_hugepages': {
+        info: 'Hugepages pool of the NUMA node. <b>surplus</b> pages are allocated on demand above the persistent pool size, when <code>nr_overcommit_hugepages</code> allows it.'
+    },
+
     'mem.available': {
         info: function (os) {
             if (os === "freebsd")
@@ -4919,6 +5165,26 @@ netdataDashboard.context = {
         info: 'The number of write requests merged.'
     },
 
+    'services.cpu_some_pressure': {
+        info: 'CPU <a href="https://www.kernel.org/doc/html/latest/accounting/psi.html" target="_blank">Pressure Stall Information</a> (10 seconds average). <b>Some</b> indicates the share of time in which at least some tasks of the service are stalled on CPU.'
+    },
+
+    'services.memory_some_pressure': {
+        info: 'Memory <a href="https://www.kernel.org/doc/html/latest/accounting/psi.html" target="_blank">Pressure Stall Information</a> (10 seconds average). <b>Some</b> indicates the share of time in which at least some tasks of the service are stalled on memory.'
+    },
+
+    'services.memory_full_pressure': {
+        info: 'Memory <a href="https://www.kernel.org/doc/html/latest/accounting/psi.html" target="_blank">Pressure Stall Information</a> (10 seconds average). <b>Full</b> indicates the share of time in which all non-idle tasks of the service are stalled on memory simultaneously.'
+    },
+
+    'services.io_some_pressure': {
+        info: 'I/O <a href="https://www.kernel.org/doc/html/latest/accounting/psi.html" target="_blank">Pressure Stall Information</a> (10 seconds average). <b>Some</b> indicates the share of time in which at least some tasks of the service are stalled on I/O.'
+    },
+
+    'services.io_full_pressure': {
+        info: 'I/O <a href="https://www.kernel.org/doc/html/latest/accounting/psi.html" target="_blank">Pressure Stall Information</a> (10 seconds average). <b>Full</b> indicates the share of time in which all non-idle tasks of the service are stalled on I/O simultaneously.'
+    },
+
     'services.swap_read': {
         info: ebpfSwapRead + '<div id="ebpf_services_swap_read"></div>'
     },
@@ -5156,6 +5422,10 @@ netdataDashboard.context = {
         info: 'Shows info on how long the tube has been paused for, and how long is left remaining on the pause.'
     },
 
+    'beanstalk.workers': {
+        info: 'Workers watching this tube, split into the ones waiting in a <code>reserve</code> command (idle) and the ones processing a job (busy). A worker watching several tubes is counted on each of them.'
+    },
+
     // ------------------------------------------------------------------------
     // ceph
 
@@ -5832,6 +6102,18 @@ netdataDashboard.context = {
         height: 0.2
     },
 
+    'ntpd.server_rejected': {
+        info: 'Packets from clients that were not answered normally. <b>Restricted</b> - denied by <code>restrict</code> rules. <b>Rate limited</b> - clients exceeding the <code>discard</code> limits. <b>Declined</b> - packets for which no response was sent. <b>Bad format</b>, <b>bad auth</b> - malformed or failing authentication. <b>Old version</b> - NTP versions older than the server one.'
+    },
+
+    'ntpd.server_kod': {
+        info: 'Kiss-o\'-Death packets sent to clients exceeding the rate limits of a <code>restrict ... limited kod</code> rule, asking them to reduce their poll rate.'
+    },
+
+    'ntpd.server_clients': {
+        info: 'Number of entries of the most recently used (MRU) list, the clients that recently sent packets to the server. Its size is bounded by the <code>mru</code> options of <code>ntp.conf</code>, so on busy public servers it is a lower bound of the actual number of clients.'
+    },
+
     'ntpd.peer_offset': {
         info: 'The offset of the peer clock relative to the system clock in milliseconds. Smaller values here weight peers more heavily for selection after the initial synchronization of the local clock. For a system providing time service to other systems, these should be as low as possible.'
     },
@@ -6022,6 +6304,37 @@ netdataDashboard.context = {
         'Maximal/minimal means values of voltages when battery considered "full"/"empty" at normal conditions.</p>'
     },
 
+    'powersupply.status': {
+        info: 'The battery charging status. <b>not_charging</b> means the charger is connected, but the battery is not being charged (e.g. because of a charge threshold).'
+    },
+
+    'powersupply.health': {
+        info: 'The battery health, as reported by the driver.'
+    },
+
+    'powersupply.cycle_count': {
+        info: 'The number of charge/discharge cycles the battery has gone through. Battery capacity degrades as this number grows.'
+    },
+
+    'powersupply.online': {
+        info: 'Whether the power supply (AC adapter, USB power source) is plugged in.'
+    },
+
+    // ------------------------------------------------------------------------
+    // Thermal and powercap
+
+    'thermal.zone_temperature': {
+        info: 'The temperature reported by the thermal zone sensor. The kernel acts on the zone trip points: <b>passive</b> (throttles the CPU), <b>hot</b> and <b>critical</b> (shuts the system down).'
+    },
+
+    'thermal.cooling_device_state': {
+        info: 'The current and maximum state of the cooling device. For processors the state is the throttling level, for fans the speed level. Zero means no cooling is applied.'
+    },
+
+    'powercap.rapl_power': {
+        info: 'The power consumed by the RAPL zone and its subzones. The <b>package</b> zone includes the <b>core</b> and <b>uncore</b> subzones, <b>dram</b> is reported separately.'
+    },
+
     // ------------------------------------------------------------------------
     // VMware vSphere
 